@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/emon5122/dockwarden/internal/clock"
+	"github.com/emon5122/dockwarden/internal/health"
+	"github.com/emon5122/dockwarden/internal/notify"
+	"github.com/emon5122/dockwarden/internal/scheduler"
+	"github.com/emon5122/dockwarden/internal/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+// digestSchedule returns the cron expression of a health digest setting.
+// Daily digests go out at 08:00, weekly ones on Monday at 08:00, in the
+// container's time zone.
+func digestSchedule(value string) string {
+	switch value {
+	case "daily":
+		return "0 0 8 * * *"
+	case "weekly":
+		return "0 0 8 * * 1"
+	default:
+		return value
+	}
+}
+
+// startHealthDigest schedules the health digest and returns its scheduler,
+// or nil when the digest is disabled
+func startHealthDigest(watcher *health.Watcher, upd *updater.Updater) *scheduler.Scheduler {
+	if cfg.HealthDigest == "" || watcher == nil {
+		return nil
+	}
+	notifier := notify.FromConfig(cfg)
+
+	sched := scheduler.NewWithOptions(scheduler.Options{
+		Name:     "health-digest",
+		Schedule: digestSchedule(cfg.HealthDigest),
+	}, clock.Real)
+	sched.Start(func() {
+		// Standby instances don't watch, their digest would be empty
+		if !upd.IsLeader() {
+			log.Debug("Standby instance, skipping health digest")
+			return
+		}
+
+		digest := watcher.TakeDigest()
+		for _, status := range upd.ContainerStatuses() {
+			if status.UpdateAvailable {
+				digest.PendingUpdates = append(digest.PendingUpdates, status.Name)
+			}
+		}
+
+		log.WithFields(log.Fields{
+			"monitored":       digest.Monitored,
+			"unhealthy":       digest.Unhealthy,
+			"restarts":        digest.Restarts,
+			"gave_up":         len(digest.GaveUp),
+			"pending_updates": len(digest.PendingUpdates),
+		}).Info("Sending health digest")
+		notifier.NotifyHealthDigest(digest)
+	})
+	return sched
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/meta"
+	"github.com/emon5122/dockwarden/internal/notify"
+	log "github.com/sirupsen/logrus"
+)
+
+// versionFile in the data directory records the version that last started,
+// so a self-update can be detected on the next start
+const versionFile = "version"
+
+// recordVersion stores version in dataDir and returns the version recorded
+// by the previous start, or "" when there is none
+func recordVersion(dataDir, version string) (string, error) {
+	path := filepath.Join(dataDir, versionFile)
+
+	previous, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(version+"\n"), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return strings.TrimSpace(string(previous)), nil
+}
+
+// lifecycleNotifier returns the notifier for start/stop notifications, or nil
+// when they are disabled
+func lifecycleNotifier() *notify.Notifier {
+	if !cfg.NotifyLifecycle {
+		return nil
+	}
+	return notify.FromConfig(cfg)
+}
+
+// announceStart logs and notifies the start of the daemon, including a
+// version change since the previous start
+func announceStart(notifier *notify.Notifier) {
+	previous, err := recordVersion(cfg.DataDir, meta.Version)
+	if err != nil {
+		log.WithError(err).Debug("Failed to record version, self-update detection disabled")
+	} else if previous != "" && previous != meta.Version {
+		log.WithFields(log.Fields{
+			"old_version": previous,
+			"new_version": meta.Version,
+		}).Info("DockWarden was updated since the last start")
+		if notifier != nil {
+			notifier.NotifyVersionChanged(previous, meta.Version)
+		}
+	}
+
+	if notifier != nil {
+		notifier.NotifyStarted(meta.Version, cfg.Summary())
+	}
+}
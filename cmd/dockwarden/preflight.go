@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultDockerHost = "unix:///var/run/docker.sock"
+
+// startupCheck is the outcome of a single startup check
+type startupCheck struct {
+	name   string
+	ok     bool
+	detail string
+	// required checks make DockWarden refuse to start when they fail
+	required bool
+}
+
+// runStartupChecks verifies up front that DockWarden has the access its
+// configuration needs, logs a capability summary and exits with a clear
+// explanation when a required permission is missing, instead of failing
+// later with scattered errors.
+func runStartupChecks() {
+	checks := []startupCheck{
+		checkDockerAccess(),
+		checkUser(),
+		checkDataDir(),
+	}
+	if cfg.APIEnabled && cfg.APISocket != "" {
+		checks = append(checks, checkAPISocket())
+	}
+	if cfg.ImageFeedDir != "" {
+		checks = append(checks, checkImageFeed())
+	}
+
+	summary := log.Fields{}
+	failed := false
+	for _, check := range checks {
+		summary[check.name] = check.detail
+		if check.ok {
+			continue
+		}
+		logger := log.WithField("check", check.name)
+		if check.required {
+			logger.Error(check.detail)
+			failed = true
+		} else {
+			logger.Warn(check.detail)
+		}
+	}
+
+	if failed {
+		log.Fatal("Startup checks failed, DockWarden lacks the access its configuration needs (see docs/security.md)")
+	}
+	log.WithFields(summary).Info("Startup checks passed")
+}
+
+// checkDockerAccess pings the daemon and, when that fails, explains whether
+// the socket is missing or not accessible to the current user
+func checkDockerAccess() startupCheck {
+	check := startupCheck{name: "docker", required: true}
+
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = defaultDockerHost
+	}
+
+	if err := client.Ping(); err == nil {
+		check.ok = true
+		check.detail = "reachable at " + host
+		if cfg.MonitorOnly {
+			check.detail += " (read-only access is enough with monitor-only)"
+		}
+		return check
+	}
+
+	path, ok := strings.CutPrefix(host, "unix://")
+	if !ok {
+		check.detail = fmt.Sprintf("Docker daemon at %s is not reachable; check DOCKER_HOST and that the socket proxy is running", host)
+		return check
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		check.detail = fmt.Sprintf("Docker socket %s not found; mount it with -v /var/run/docker.sock:/var/run/docker.sock", path)
+		return check
+	}
+
+	conn, err := net.DialTimeout("unix", path, 5*time.Second)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			check.detail = fmt.Sprintf("permission denied on %s for %s; run as root or add the socket's group (docker run --group-add $(stat -c %%g %s))", path, currentUser(), path)
+			return check
+		}
+		check.detail = fmt.Sprintf("Docker socket %s is not usable: %v", path, err)
+		return check
+	}
+	conn.Close()
+
+	check.detail = fmt.Sprintf("Docker socket %s accepts connections but the daemon doesn't answer", path)
+	return check
+}
+
+// checkUser reports who DockWarden runs as. Root is not needed; access to
+// the Docker socket is.
+func checkUser() startupCheck {
+	check := startupCheck{name: "user", ok: true, detail: currentUser()}
+	if os.Geteuid() == 0 {
+		check.detail += " (root is not required, only access to the Docker socket)"
+	}
+	return check
+}
+
+// checkDataDir verifies the data directory is writable. Without it the
+// update history is kept in memory and version changes aren't detected, so
+// it is only a warning.
+func checkDataDir() startupCheck {
+	check := startupCheck{name: "data_dir"}
+	if err := writable(cfg.DataDir); err != nil {
+		check.detail = fmt.Sprintf("%s is not writable, update history won't survive restarts: %v", cfg.DataDir, err)
+		return check
+	}
+	check.ok = true
+	check.detail = cfg.DataDir + " writable"
+	return check
+}
+
+// checkAPISocket verifies the API socket can be created
+func checkAPISocket() startupCheck {
+	check := startupCheck{name: "api_socket", required: true}
+	dir := filepath.Dir(cfg.APISocket)
+	if err := writable(dir); err != nil {
+		check.detail = fmt.Sprintf("cannot create API socket %s: %v", cfg.APISocket, err)
+		return check
+	}
+	check.ok = true
+	check.detail = dir + " writable"
+	return check
+}
+
+// checkImageFeed verifies the image feed directory can be read. Archives
+// are renamed once loaded, so a read-only feed is only a warning.
+func checkImageFeed() startupCheck {
+	check := startupCheck{name: "image_feed", required: true}
+	if _, err := os.ReadDir(cfg.ImageFeedDir); err != nil {
+		check.detail = fmt.Sprintf("cannot read image feed %s: %v", cfg.ImageFeedDir, err)
+		return check
+	}
+	check.ok = true
+	check.detail = cfg.ImageFeedDir + " readable"
+	if err := writable(cfg.ImageFeedDir); err != nil {
+		check.detail += ", read-only (loaded archives are remembered until restart)"
+	}
+	return check
+}
+
+// writable reports whether files can be created in dir, creating it if needed
+func writable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".dockwarden-check-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// currentUser describes the effective user and group
+func currentUser() string {
+	uid, gid := os.Geteuid(), os.Getegid()
+	if uid < 0 {
+		return "current user"
+	}
+	return fmt.Sprintf("uid %d, gid %d", uid, gid)
+}
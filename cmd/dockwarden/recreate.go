@@ -0,0 +1,31 @@
+package main
+
+import (
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/clock"
+	"github.com/emon5122/dockwarden/internal/scheduler"
+	"github.com/emon5122/dockwarden/internal/updater"
+)
+
+// forceRecreateTick is how often forced recreate schedules are evaluated;
+// they fire with minute precision
+const forceRecreateTick = time.Minute
+
+// startForceRecreate evaluates the forced recreate schedules of the global
+// setting and the dockwarden.force-recreate labels every minute. Labels can
+// appear on any container, so it always runs.
+func startForceRecreate(upd *updater.Updater) *scheduler.Scheduler {
+	sched := scheduler.NewWithOptions(scheduler.Options{
+		Name:     "force-recreate",
+		Interval: forceRecreateTick,
+	}, clock.Real)
+
+	last := time.Now()
+	sched.Start(func() {
+		now := time.Now()
+		upd.ForceRecreate(last, now)
+		last = now
+	})
+	return sched
+}
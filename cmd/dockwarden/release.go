@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/clock"
+	"github.com/emon5122/dockwarden/internal/scheduler"
+	"github.com/emon5122/dockwarden/internal/updater"
+)
+
+// versionCheckInterval is how often GitHub is asked for a newer release
+const versionCheckInterval = 24 * time.Hour
+
+// startVersionCheck checks for a newer DockWarden release on start and
+// daily, and returns its scheduler, or nil when the check is disabled
+func startVersionCheck(upd *updater.Updater) *scheduler.Scheduler {
+	if !cfg.VersionCheck {
+		return nil
+	}
+
+	sched := scheduler.NewWithOptions(scheduler.Options{
+		Name:       "version-check",
+		Interval:   versionCheckInterval,
+		RunOnStart: true,
+	}, clock.Real)
+	sched.Start(func() {
+		upd.CheckRelease(context.Background())
+	})
+	return sched
+}
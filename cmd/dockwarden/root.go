@@ -1,20 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/emon5122/dockwarden/internal/clock"
+	"github.com/emon5122/dockwarden/internal/compose"
 	"github.com/emon5122/dockwarden/internal/config"
 	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/feed"
+	"github.com/emon5122/dockwarden/internal/gitops"
+	"github.com/emon5122/dockwarden/internal/grafana"
 	"github.com/emon5122/dockwarden/internal/health"
+	"github.com/emon5122/dockwarden/internal/history"
+	"github.com/emon5122/dockwarden/internal/i18n"
+	"github.com/emon5122/dockwarden/internal/lock"
+	"github.com/emon5122/dockwarden/internal/logging"
 	"github.com/emon5122/dockwarden/internal/meta"
+	"github.com/emon5122/dockwarden/internal/notify"
+	"github.com/emon5122/dockwarden/internal/redact"
+	"github.com/emon5122/dockwarden/internal/registry"
 	"github.com/emon5122/dockwarden/internal/scheduler"
+	"github.com/emon5122/dockwarden/internal/syslog"
 	"github.com/emon5122/dockwarden/internal/updater"
 	"github.com/emon5122/dockwarden/pkg/api"
 
+	"github.com/docker/docker/api/types/versions"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -69,10 +85,11 @@ func preRun(cmd *cobra.Command, args []string) {
 	// Load configuration
 	cfg, err = config.Load(cmd)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		log.WithError(err).Fatal("Failed to load configuration")
 	}
 
 	// Setup logging
+	redact.Register(cfg.Secrets()...)
 	setupLogging(cfg)
 
 	// Health check mode - just exit with success if Docker is reachable
@@ -83,17 +100,185 @@ func preRun(cmd *cobra.Command, args []string) {
 		os.Exit(0)
 	}
 
+	if _, err := i18n.New(cfg.Locale); err != nil {
+		log.WithError(err).Fatal("Invalid locale")
+	}
+
+	if err := notify.ValidateConfig(cfg); err != nil {
+		log.WithError(err).Fatal("Invalid notification settings")
+	}
+
+	if _, err := scheduler.ParseWindow(cfg.HealthQuietHours); err != nil {
+		log.WithError(err).Fatal("Invalid health quiet hours")
+	}
+	if err := api.ValidateTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.WithError(err).Fatal("Invalid trusted proxies")
+	}
+	if err := api.ValidateTokens(cfg.APITokens); err != nil {
+		log.WithError(err).Fatal("Invalid API tokens")
+	}
+	if cfg.HealthDigest != "" && cfg.NotificationURL == "" {
+		log.Fatal("The health digest is sent as a notification, set --notification-url")
+	}
+
+	if cfg.ImageFeedDir != "" {
+		if !cfg.Offline {
+			log.Fatal("The image feed only works in offline mode, set --offline")
+		}
+		if cfg.ImageFeedInterval <= 0 {
+			log.Fatal("Invalid image feed interval, it must be positive")
+		}
+	}
+
+	if _, err := compose.Load(cfg.ComposeFiles); err != nil {
+		log.WithError(err).Fatal("Invalid compose files")
+	}
+	for _, rule := range cfg.EphemeralRules {
+		switch strings.TrimSpace(rule) {
+		case docker.EphemeralGeneratedName, docker.EphemeralOneoff, "none":
+		default:
+			log.WithField("rule", rule).Fatal("Invalid ephemeral rule, use generated-name, oneoff or none")
+		}
+	}
+	if cfg.RecreateStrategy != updater.RecreateInspect && cfg.RecreateStrategy != updater.RecreateCompose {
+		log.WithField("strategy", cfg.RecreateStrategy).Fatal("Invalid recreate strategy, use inspect or compose")
+	}
+
+	switch cfg.HealthAction {
+	case health.ActionRestart, health.ActionRecreate, health.ActionNotify:
+	default:
+		log.WithField("action", cfg.HealthAction).Fatal("Invalid health action, use restart, recreate or notify")
+	}
+
+	if cfg.ForceRecreateSchedule != "" {
+		if _, err := updater.ParseRecreateSchedule(cfg.ForceRecreateSchedule); err != nil {
+			log.WithError(err).Fatal("Invalid forced recreate schedule")
+		}
+	}
+
+	if cfg.RollbackTimeout < 0 {
+		log.Fatal("Invalid rollback timeout, it must not be negative")
+	}
+
+	if cfg.VerifySignatures {
+		if cfg.Offline {
+			log.Fatal("Signature verification needs the registry, it doesn't work in offline mode")
+		}
+		if cfg.SignatureTrustStore == "" {
+			log.Fatal("Signature verification needs trusted certificates, set --signature-trust-store")
+		}
+		if _, err := registry.LoadTrustStore(cfg.SignatureTrustStore); err != nil {
+			log.WithError(err).Fatal("Invalid signature trust store")
+		}
+	}
+
+	if err := history.CheckURL(cfg.HistoryURL); err != nil {
+		log.WithError(err).Fatal("Invalid history URL")
+	}
+
+	if cfg.GitOpsURL != "" {
+		if _, _, err := gitops.ParseAuthor(cfg.GitOpsAuthor); err != nil {
+			log.WithError(err).Fatal("Invalid GitOps author")
+		}
+		if cfg.GitOpsBranch == "" {
+			log.Fatal("GitOps needs a branch, set --gitops-branch")
+		}
+	}
+
+	if cfg.HealthTCPFailures < 1 {
+		log.Fatal("Invalid health TCP failures, it must be at least 1")
+	}
+
+	if cfg.LeaderElection && cfg.LockURL == "" {
+		log.Fatal("Leader election needs a lock backend, set --lock-url")
+	}
+	if cfg.LockURL != "" {
+		if _, err := lock.New(cfg.LockURL, lock.Name("cycle", cfg.Scope), cfg.LockTTL); err != nil {
+			log.WithError(err).Fatal("Invalid lock URL")
+		}
+	}
+
+	profile, err := docker.LookupPlatformProfile(cfg.PlatformProfile)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid platform profile")
+	}
+
 	// Initialize Docker client
 	client, err = docker.NewClient(docker.ClientOptions{
 		IncludeStopped:    cfg.IncludeStopped,
 		IncludeRestarting: cfg.IncludeRestarting,
 		RemoveVolumes:     cfg.RemoveVolumes,
+		ProtectedLabels:   cfg.ProtectedLabels,
+		APIVersion:        cfg.DockerAPIVersion,
+		Profile:           profile,
+
+		PauseRestartPolicy: cfg.PauseRestartPolicy,
 	})
 	if err != nil {
-		log.Fatalf("Failed to create Docker client: %v", err)
+		log.WithError(err).Fatal("Failed to create Docker client")
 	}
 
-	log.Infof("DockWarden %s starting...", meta.Version)
+	log.WithField("version", meta.Version).Info("DockWarden starting...")
+
+	runStartupChecks()
+	logDockerFeatures()
+	logPlatformDiagnostics()
+}
+
+// logDockerFeatures logs the Docker API version in use and what it supports,
+// and turns off features the daemon is too old for
+func logDockerFeatures() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	features, err := client.Features(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to determine Docker API features")
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"api_version":          features.APIVersion,
+		"daemon_api_version":   features.DaemonAPIVersion,
+		"pinned":               features.Pinned,
+		"health_checks":        features.HealthChecks,
+		"stop_signal":          features.StopSignal,
+		"multi_network_create": features.MultiNetworkCreate,
+	})
+	logger.Info("Docker API")
+
+	if features.Pinned && versions.LessThan(features.DaemonAPIVersion, features.APIVersion) {
+		logger.Warn("Pinned Docker API version is newer than the daemon supports; requests are likely to fail")
+	}
+	if cfg.HealthWatch && !features.HealthChecks {
+		logger.Warn("Docker daemon is too old to report container health, disabling health watching")
+		cfg.HealthWatch = false
+	}
+}
+
+// logPlatformDiagnostics logs the host and binary platforms and warns when
+// DockWarden runs under emulation, which usually means the wrong image
+// variant was pulled (e.g. an amd64 image on an arm64 host)
+func logPlatformDiagnostics() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	binary := docker.BinaryPlatform()
+	host, err := docker.HostPlatform(ctx, client)
+	if err != nil {
+		log.WithError(err).Debug("Failed to determine host platform")
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"host_platform":   host.String(),
+		"binary_platform": binary.String(),
+	})
+	if host.Arch != binary.Arch {
+		logger.Warn("DockWarden binary architecture differs from the Docker host; it is probably running under emulation, pull the image for the host platform")
+		return
+	}
+	logger.Debug("Platform diagnostics")
 }
 
 func run(cmd *cobra.Command, args []string) {
@@ -101,13 +286,32 @@ func run(cmd *cobra.Command, args []string) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Join the leader election before anything acts on containers
+	elector := newElector()
+	if elector != nil {
+		elector.Start()
+	}
+
 	// Create updater module
 	upd := updater.New(client, cfg)
+	upd.SetElector(elector)
+	if stop := forwardToSyslog(upd); stop != nil {
+		defer stop()
+	}
+	if stop := annotateGrafana(upd); stop != nil {
+		defer stop()
+	}
+
+	// Bring containers back to the images pinned in the lockfile before
+	// the health watcher, the API or the first cycle act on them
+	upd.ReconcileLockfile()
 
 	// Create health watcher module
 	var watcher *health.Watcher
 	if cfg.HealthWatch {
-		watcher = health.NewWatcher(client, cfg)
+		watcher = health.NewWatcher(client, cfg, clock.Real)
+		watcher.SetElector(elector)
+		watcher.SetCycleLock(upd.TryLockCycle)
 		go watcher.Start()
 	}
 
@@ -117,63 +321,147 @@ func run(cmd *cobra.Command, args []string) {
 	}
 
 	// Create scheduler
-	sched := scheduler.New(cfg)
+	sched := scheduler.New(cfg, clock.Real)
 
 	// Run once mode
 	if cfg.RunOnce {
 		log.Info("Running once and exiting...")
-		if err := upd.Run(); err != nil {
-			log.Errorf("Update failed: %v", err)
+		err := upd.Run()
+		if err != nil {
+			log.WithError(err).Error("Update failed")
+		}
+		flushNotifications()
+		if cfg.ExitOnFailure {
+			os.Exit(runOnceExitCode(upd.LastCycle(), err))
 		}
 		return
 	}
 
+	notifier := lifecycleNotifier()
+	announceStart(notifier)
+
+	trigger := newCycleTrigger(upd)
+	handleControlSignals(trigger, upd, watcher)
+	watchTriggers(trigger)
+
 	// Start scheduler
 	sched.Start(func() {
 		if err := upd.Run(); err != nil {
-			log.Errorf("Update cycle failed: %v", err)
+			log.WithError(err).Error("Update cycle failed")
 		}
 	})
 
+	var imageFeed *feed.Watcher
+	if cfg.ImageFeedDir != "" {
+		imageFeed = feed.New(cfg.ImageFeedDir, cfg.ImageFeedInterval, upd, clock.Real)
+		imageFeed.Start()
+	}
+
+	digest := startHealthDigest(watcher, upd)
+	forceRecreate := startForceRecreate(upd)
+	versionCheck := startVersionCheck(upd)
+
 	// Wait for shutdown signal
 	sig := <-sigChan
-	log.Infof("Received signal %v, shutting down...", sig)
+	log.WithField("signal", sig.String()).Info("Received signal, shutting down...")
 
 	// Graceful shutdown
 	sched.Stop()
+	if imageFeed != nil {
+		imageFeed.Stop()
+	}
+	if digest != nil {
+		digest.Stop()
+	}
+	forceRecreate.Stop()
+	if versionCheck != nil {
+		versionCheck.Stop()
+	}
 	if watcher != nil {
 		watcher.Stop()
 	}
 
+	if elector != nil {
+		elector.Stop()
+	}
+
+	if notifier != nil {
+		notifier.NotifyStopped(meta.Version, "received "+sig.String())
+	}
+	flushNotifications()
+
 	log.Info("DockWarden stopped")
 }
 
-func setupLogging(cfg *config.Config) {
-	// Set log level
-	level, err := log.ParseLevel(cfg.LogLevel)
+// newElector returns the leader elector when leader election is enabled and
+// not running once
+func newElector() *lock.Elector {
+	if !cfg.LeaderElection || cfg.RunOnce {
+		return nil
+	}
+	l, err := lock.New(cfg.LockURL, lock.Name("leader", cfg.Scope), cfg.LockTTL)
 	if err != nil {
-		level = log.InfoLevel
+		log.WithError(err).Fatal("Invalid lock URL")
 	}
-	log.SetLevel(level)
+	return lock.NewElector(l)
+}
 
-	// Set log format
-	switch cfg.LogFormat {
-	case "json":
-		log.SetFormatter(&log.JSONFormatter{
-			TimestampFormat: time.RFC3339,
-		})
-	default:
-		log.SetFormatter(&log.TextFormatter{
-			FullTimestamp:   true,
-			TimestampFormat: "2006-01-02 15:04:05",
-		})
+// forwardToSyslog forwards the updater's events to syslog when configured and
+// returns the function stopping it
+func forwardToSyslog(upd *updater.Updater) func() {
+	if cfg.SyslogURL == "" {
+		return nil
+	}
+	w, err := syslog.New(cfg.SyslogURL, cfg.SyslogFacility)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid syslog configuration")
+	}
+	log.WithField("url", redact.URL(cfg.SyslogURL)).Info("Forwarding events to syslog")
+	return syslog.Forward(upd.Events(), w)
+}
+
+// annotateGrafana pushes update annotations to Grafana when configured and
+// returns the function stopping it
+func annotateGrafana(upd *updater.Updater) func() {
+	if cfg.GrafanaURL == "" {
+		return nil
+	}
+	c, err := grafana.New(grafana.Options{
+		URL:          cfg.GrafanaURL,
+		Token:        cfg.GrafanaToken,
+		Tags:         cfg.GrafanaTags,
+		DashboardUID: cfg.GrafanaDashboardUID,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Invalid Grafana configuration")
+	}
+	log.WithField("url", redact.URL(cfg.GrafanaURL)).Info("Pushing update annotations to Grafana")
+	return grafana.Forward(upd.Events(), c)
+}
+
+// flushNotifications gives queued notifications a chance to go out before
+// the process exits
+func flushNotifications() {
+	if !notify.Flush(30 * time.Second) {
+		log.Warn("Timed out delivering queued notifications")
 	}
 }
 
+func setupLogging(cfg *config.Config) {
+	logging.Setup(cfg.LogLevel, cfg.LogFormat)
+}
+
 func startAPIServer(upd *updater.Updater, watcher *health.Watcher) {
 	server := api.NewServer(cfg, client, upd, watcher)
+	if cfg.GRPCPort != 0 {
+		go func() {
+			if err := server.StartGRPC(); err != nil {
+				log.WithError(err).Error("gRPC server error")
+			}
+		}()
+	}
 	if err := server.Start(); err != nil {
-		log.Errorf("API server error: %v", err)
+		log.WithError(err).Error("API server error")
 	}
 }
 
@@ -184,3 +472,23 @@ func performHealthCheck() error {
 	}
 	return client.Ping()
 }
+
+// Exit codes of run-once mode with --exit-on-failure
+const (
+	exitNothingToDo = 0
+	exitFailure     = 1
+	exitUpdated     = 2
+)
+
+// runOnceExitCode tells cron and CI wrappers what a single cycle did.
+// Failures take precedence over applied updates.
+func runOnceExitCode(summary updater.CycleSummary, err error) int {
+	switch {
+	case err != nil || summary.Failed > 0:
+		return exitFailure
+	case summary.Updated > 0:
+		return exitUpdated
+	default:
+		return exitNothingToDo
+	}
+}
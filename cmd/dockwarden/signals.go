@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+
+	"github.com/emon5122/dockwarden/internal/health"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+// handleControlSignals runs an update cycle on triggerSignal and logs the
+// internal state on dumpSignal, so minimal deployments without the API can
+// still be driven with docker kill -s. It does nothing on platforms without
+// these signals.
+func handleControlSignals(trigger *cycleTrigger, upd *updater.Updater, watcher *health.Watcher) {
+	if triggerSignal == nil {
+		return
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, triggerSignal, dumpSignal)
+
+	go func() {
+		for sig := range sigChan {
+			logger := log.WithField("signal", sig.String())
+			if sig == dumpSignal {
+				dumpState(logger, upd, watcher)
+				continue
+			}
+			trigger.fire(logger)
+		}
+	}()
+}
+
+// dumpState logs the updater and health watcher statistics
+func dumpState(logger *log.Entry, upd *updater.Updater, watcher *health.Watcher) {
+	fields := log.Fields{"goroutines": runtime.NumGoroutine()}
+	for k, v := range upd.GetStats() {
+		fields["updater_"+k] = v
+	}
+	if watcher != nil {
+		for k, v := range watcher.GetStats() {
+			fields["health_"+k] = v
+		}
+		for _, status := range watcher.RestartStatuses() {
+			if status.Attempts > 0 || status.Recreates > 0 || status.GaveUp || status.CrashLooping {
+				logger.WithFields(log.Fields{
+					logging.FieldContainer: status.Name,
+					"attempts":             status.Attempts,
+					"recreates":            status.Recreates,
+					"gave_up":              status.GaveUp,
+					"crash_looping":        status.CrashLooping,
+				}).Info("Health tracking")
+			}
+		}
+	}
+	for k, v := range cfg.Summary() {
+		fields["config_"+k] = v
+	}
+	logger.WithFields(fields).Info("State dump")
+}
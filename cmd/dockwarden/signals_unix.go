@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// SIGUSR1 triggers an update cycle, SIGUSR2 dumps the internal state
+var (
+	triggerSignal os.Signal = syscall.SIGUSR1
+	dumpSignal    os.Signal = syscall.SIGUSR2
+)
@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// Windows has no user-defined signals
+var (
+	triggerSignal os.Signal
+	dumpSignal    os.Signal
+)
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/emon5122/dockwarden/internal/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+// cycleTrigger starts update cycles on demand, from signals, the trigger
+// FIFO or stdin. Triggers arriving while a triggered cycle is still running
+// are ignored.
+type cycleTrigger struct {
+	upd     *updater.Updater
+	running atomic.Bool
+}
+
+func newCycleTrigger(upd *updater.Updater) *cycleTrigger {
+	return &cycleTrigger{upd: upd}
+}
+
+// fire starts an update cycle in the background
+func (t *cycleTrigger) fire(logger *log.Entry) {
+	if !t.running.CompareAndSwap(false, true) {
+		logger.Warn("Triggered update cycle still running, ignoring")
+		return
+	}
+	logger.Info("Update cycle triggered")
+	go func() {
+		defer t.running.Store(false)
+		if err := t.upd.Run(); err != nil {
+			logger.WithError(err).Error("Triggered update cycle failed")
+		}
+	}()
+}
+
+// watchTriggers starts a cycle for every line written to the trigger FIFO
+// or stdin, when enabled, so wrapper scripts can drive runs without the API
+func watchTriggers(trigger *cycleTrigger) {
+	if cfg.TriggerFile != "" {
+		f, err := openTriggerFile(cfg.TriggerFile)
+		if err != nil {
+			log.WithError(err).WithField("path", cfg.TriggerFile).Fatal("Failed to open trigger file")
+		}
+		log.WithField("path", cfg.TriggerFile).Info("Listening for triggers on named pipe")
+		go readTriggers(f, "fifo", trigger)
+	}
+
+	if cfg.TriggerStdin {
+		log.Info("Listening for triggers on stdin")
+		go readTriggers(os.Stdin, "stdin", trigger)
+	}
+}
+
+// readTriggers fires trigger once per line read from r until r is closed
+func readTriggers(r io.Reader, source string, trigger *cycleTrigger) {
+	logger := log.WithField("trigger", source)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		trigger.fire(logger)
+	}
+	if err := scanner.Err(); err != nil {
+		logger.WithError(err).Error("Failed to read triggers")
+		return
+	}
+	logger.Warn("Trigger input closed, no more triggers will be read")
+}
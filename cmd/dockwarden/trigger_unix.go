@@ -0,0 +1,27 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// openTriggerFile opens the named pipe at path, creating it if needed. It is
+// opened read-write so the pipe stays open when a writer disconnects.
+func openTriggerFile(path string) (*os.File, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := syscall.Mkfifo(path, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to create named pipe: %w", err)
+		}
+	} else if err != nil {
+		return nil, err
+	} else if info.Mode()&os.ModeNamedPipe == 0 {
+		return nil, fmt.Errorf("%s exists and is not a named pipe", path)
+	}
+
+	return os.OpenFile(path, os.O_RDWR, 0)
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// openTriggerFile is not supported, Windows has no FIFOs
+func openTriggerFile(path string) (*os.File, error) {
+	return nil, errors.New("named pipe triggers are not supported on Windows")
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is how many entries are kept when no limit is configured
+const DefaultMaxEntries = 200
+
+// Entry records an action triggered through the API or the dashboard
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Token     string    `json:"token"`
+	RemoteIP  string    `json:"remote_ip"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Identity describes who triggered the action, for logs and notifications
+func (e Entry) Identity() string {
+	return "token " + e.Token + " (" + e.RemoteIP + ")"
+}
+
+// Log keeps the most recent entries in memory
+type Log struct {
+	mu        sync.Mutex
+	entries   []Entry
+	max       int
+	retention time.Duration
+	pruned    int64
+}
+
+// New creates an audit log holding up to max entries, none older than
+// retention (0 keeps entries regardless of age)
+func New(max int, retention time.Duration) *Log {
+	if max <= 0 {
+		max = DefaultMaxEntries
+	}
+	return &Log{max: max, retention: retention}
+}
+
+// Max returns how many entries the log holds at most
+func (l *Log) Max() int {
+	return l.max
+}
+
+// Record appends an entry, dropping the oldest once the log is full
+func (l *Log) Record(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+	l.prune(time.Now())
+}
+
+// prune drops the entries beyond the maximum or older than the retention
+func (l *Log) prune(now time.Time) {
+	first := max(len(l.entries)-l.max, 0)
+	if l.retention > 0 {
+		cutoff := now.Add(-l.retention)
+		for first < len(l.entries) && l.entries[first].Time.Before(cutoff) {
+			first++
+		}
+	}
+	if first > 0 {
+		l.entries = append(l.entries[:0], l.entries[first:]...)
+		l.pruned += int64(first)
+	}
+}
+
+// Stats returns the number of entries held and how many were pruned
+func (l *Log) Stats() (entries int, pruned int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(time.Now())
+	return len(l.entries), l.pruned
+}
+
+// Recent returns up to n entries accepted by match (all when nil), newest first
+func (l *Log) Recent(n int, match func(Entry) bool) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(time.Now())
+
+	result := make([]Entry, 0, n)
+	for i := len(l.entries) - 1; i >= 0 && len(result) < n; i-- {
+		if match == nil || match(l.entries[i]) {
+			result = append(result, l.entries[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,251 @@
+// Package compose reads the service definitions of Docker Compose files, so
+// compose-managed containers can be compared with the file they were
+// created from.
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Labels compose sets on the containers it creates
+const (
+	LabelProject = "com.docker.compose.project"
+	LabelService = "com.docker.compose.service"
+)
+
+// Service is a service definition from a compose file. Values that use
+// variable interpolation can't be resolved without the environment compose
+// ran in and are left out.
+type Service struct {
+	// File is the compose file defining the service
+	File    string
+	Project string
+	Name    string
+	Image   string
+	Restart string
+
+	Environment map[string]string
+	Labels      map[string]string
+	// Ports are the published ports as "8080->80/tcp"
+	Ports []string
+	// Volumes are the container paths of volume and bind mounts
+	Volumes []string
+}
+
+// Definitions are the services of a set of compose files
+type Definitions struct {
+	services map[string]Service
+}
+
+// Load parses the given compose files. A file's project is its top-level
+// name, or the name of the directory it is in, like compose derives it.
+func Load(paths []string) (*Definitions, error) {
+	defs := &Definitions{services: make(map[string]Service)}
+	for _, path := range paths {
+		if err := defs.load(path); err != nil {
+			return nil, err
+		}
+	}
+	return defs, nil
+}
+
+func (d *Definitions) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read compose file: %w", err)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse compose file %s: %w", path, err)
+	}
+
+	project := f.Name
+	if project == "" || strings.Contains(project, "$") {
+		project = filepath.Base(filepath.Dir(path))
+	}
+	project = projectName(project)
+
+	for name, s := range f.Services {
+		svc := Service{
+			File:        path,
+			Project:     project,
+			Name:        name,
+			Image:       literal(s.Image),
+			Restart:     literal(s.Restart),
+			Environment: s.Environment,
+			Labels:      s.Labels,
+		}
+		for _, p := range s.Ports {
+			if p.published != "" {
+				svc.Ports = append(svc.Ports, p.published)
+			}
+		}
+		for _, v := range s.Volumes {
+			if v.target != "" {
+				svc.Volumes = append(svc.Volumes, v.target)
+			}
+		}
+		d.services[project+"/"+name] = svc
+	}
+	return nil
+}
+
+// Lookup returns the definition of a service of a project
+func (d *Definitions) Lookup(project, service string) (Service, bool) {
+	svc, ok := d.services[projectName(project)+"/"+service]
+	return svc, ok
+}
+
+// projectName normalizes a project name the way compose does: lowercase,
+// keeping only letters, digits, dashes and underscores
+func projectName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, name)
+}
+
+// literal returns s unless it uses variable interpolation
+func literal(s string) string {
+	if strings.Contains(s, "$") {
+		return ""
+	}
+	return s
+}
+
+// file is the part of a compose file that is compared
+type file struct {
+	Name     string             `yaml:"name"`
+	Services map[string]service `yaml:"services"`
+}
+
+type service struct {
+	Image       string    `yaml:"image"`
+	Restart     string    `yaml:"restart"`
+	Environment mapOrList `yaml:"environment"`
+	Labels      mapOrList `yaml:"labels"`
+	Ports       []port    `yaml:"ports"`
+	Volumes     []volume  `yaml:"volumes"`
+}
+
+// mapOrList is a compose mapping that may also be written as a list of
+// KEY=VALUE entries. Keys without a value (taken from the environment
+// compose runs in) and interpolated values are left out.
+type mapOrList map[string]string
+
+func (m *mapOrList) UnmarshalYAML(node *yaml.Node) error {
+	result := make(mapOrList)
+	switch node.Kind {
+	case yaml.MappingNode:
+		var raw map[string]interface{}
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		for k, v := range raw {
+			s := fmt.Sprint(v)
+			if v == nil || strings.Contains(s, "$") {
+				continue
+			}
+			result[k] = s
+		}
+	case yaml.SequenceNode:
+		var raw []string
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		for _, entry := range raw {
+			k, v, ok := strings.Cut(entry, "=")
+			if !ok || strings.Contains(v, "$") {
+				continue
+			}
+			result[k] = v
+		}
+	}
+	*m = result
+	return nil
+}
+
+// port is a port entry in short ("127.0.0.1:8080:80/udp") or long syntax.
+// Only entries publishing a single fixed host port are kept.
+type port struct {
+	published string
+}
+
+func (p *port) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Target    string `yaml:"target"`
+			Published string `yaml:"published"`
+			Protocol  string `yaml:"protocol"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		p.published = publishedPort(long.Published, long.Target, long.Protocol)
+		return nil
+	}
+
+	spec, protocol, _ := strings.Cut(node.Value, "/")
+	i := strings.LastIndex(spec, ":")
+	if i < 0 {
+		return nil
+	}
+	host, target := spec[:i], spec[i+1:]
+	if j := strings.LastIndex(host, ":"); j >= 0 {
+		host = host[j+1:]
+	}
+	p.published = publishedPort(host, target, protocol)
+	return nil
+}
+
+// publishedPort renders a published port like docker.Port, or returns ""
+// for ranges, random host ports and interpolated values
+func publishedPort(host, target, protocol string) string {
+	if host == "" || target == "" || strings.ContainsAny(host+target+protocol, "-$") {
+		return ""
+	}
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	return fmt.Sprintf("%s->%s/%s", host, target, protocol)
+}
+
+// volume is a volume entry in short ("data:/var/lib/data:ro") or long syntax
+type volume struct {
+	target string
+}
+
+func (v *volume) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Type   string `yaml:"type"`
+			Target string `yaml:"target"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		if long.Type != "tmpfs" && long.Type != "npipe" {
+			v.target = literal(long.Target)
+		}
+		return nil
+	}
+
+	parts := strings.Split(node.Value, ":")
+	target := parts[0]
+	if len(parts) > 1 {
+		target = parts[1]
+	}
+	v.target = literal(target)
+	return nil
+}
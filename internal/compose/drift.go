@@ -0,0 +1,112 @@
+package compose
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/emon5122/dockwarden/internal/docker"
+)
+
+// Drift compares a running container with its service definition and
+// describes every difference, e.g. a port published by hand or an
+// environment variable changed with docker update. Environment values are
+// never included, they often hold secrets. Ports are only compared while
+// the container runs.
+func Drift(svc Service, ctr docker.Container, details docker.ContainerDetails) []string {
+	var drift []string
+
+	if svc.Image != "" && normalizeImage(svc.Image) != normalizeImage(ctr.Image) {
+		drift = append(drift, fmt.Sprintf("image is %s, compose file has %s", ctr.Image, svc.Image))
+	}
+
+	restart := svc.Restart
+	if restart == "" {
+		restart = "no"
+	}
+	if details.RestartPolicy != "" && details.RestartPolicy != restart {
+		drift = append(drift, fmt.Sprintf("restart policy is %s, compose file has %s", details.RestartPolicy, restart))
+	}
+
+	env := make(map[string]string, len(details.Env))
+	for _, entry := range details.Env {
+		k, v, _ := strings.Cut(entry, "=")
+		env[k] = v
+	}
+	for _, key := range sortedKeys(svc.Environment) {
+		value, ok := env[key]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("environment variable %s is not set", key))
+		case value != svc.Environment[key]:
+			drift = append(drift, fmt.Sprintf("environment variable %s differs", key))
+		}
+	}
+
+	for _, key := range sortedKeys(svc.Labels) {
+		value, ok := ctr.Labels[key]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("label %s is not set", key))
+		case value != svc.Labels[key]:
+			drift = append(drift, fmt.Sprintf("label %s is %q, compose file has %q", key, value, svc.Labels[key]))
+		}
+	}
+
+	if ctr.IsRunning() {
+		var published []string
+		for _, p := range ctr.Ports {
+			if p.PublicPort == 0 {
+				continue
+			}
+			if s := fmt.Sprintf("%d->%d/%s", p.PublicPort, p.PrivatePort, p.Type); !slices.Contains(published, s) {
+				published = append(published, s)
+			}
+		}
+		for _, p := range svc.Ports {
+			if !slices.Contains(published, p) {
+				drift = append(drift, fmt.Sprintf("port %s is not published", p))
+			}
+		}
+		for _, p := range published {
+			if !slices.Contains(svc.Ports, p) {
+				drift = append(drift, fmt.Sprintf("port %s is not in the compose file", p))
+			}
+		}
+	}
+
+	for _, target := range svc.Volumes {
+		if !slices.ContainsFunc(ctr.Mounts, func(m docker.Mount) bool { return m.Destination == target }) {
+			drift = append(drift, fmt.Sprintf("nothing is mounted at %s", target))
+		}
+	}
+	for _, m := range ctr.Mounts {
+		// Volumes declared by the image are mounted without being listed
+		if m.Type == "bind" && !slices.Contains(svc.Volumes, m.Destination) {
+			drift = append(drift, fmt.Sprintf("bind mount at %s is not in the compose file", m.Destination))
+		}
+	}
+
+	return drift
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeImage returns the fully-qualified reference of an image with the
+// implied latest tag, so "nginx" and "docker.io/library/nginx:latest" match
+func normalizeImage(imageName string) string {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return imageName
+	}
+	return reference.TagNameOnly(named).String()
+}
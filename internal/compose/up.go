@@ -0,0 +1,61 @@
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// More labels compose sets, locating the files a project was created from
+const (
+	LabelWorkingDir  = "com.docker.compose.project.working_dir"
+	LabelConfigFiles = "com.docker.compose.project.config_files"
+)
+
+// DefaultCommand runs the compose plugin of the docker CLI
+const DefaultCommand = "docker compose"
+
+// UpOptions select the service brought up
+type UpOptions struct {
+	Files   []string
+	Project string
+	// ProjectDir resolves relative paths in the files; compose records it
+	// in the working_dir label, so relative bind mounts keep their host path
+	ProjectDir string
+	Service    string
+}
+
+// Up runs "docker compose up -d" for a single service with command, e.g.
+// "docker compose" or "docker-compose". Images are never pulled or built:
+// the caller pulled them already, and compose recreates the container when
+// its image changed. The combined output is included in errors.
+func Up(ctx context.Context, command string, opts UpOptions) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		args = strings.Fields(DefaultCommand)
+	}
+	for _, f := range opts.Files {
+		args = append(args, "--file", f)
+	}
+	if opts.Project != "" {
+		args = append(args, "--project-name", opts.Project)
+	}
+	if opts.ProjectDir != "" {
+		args = append(args, "--project-directory", opts.ProjectDir)
+	}
+	args = append(args, "up", "--detach", "--pull", "never", "--no-build", opts.Service)
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("compose up of service %s failed: %w: %s", opts.Service, err, out)
+		}
+		return fmt.Errorf("compose up of service %s failed: %w", opts.Service, err)
+	}
+	return nil
+}
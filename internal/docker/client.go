@@ -5,7 +5,11 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,6 +20,7 @@ import (
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/registry"
 	dockerclient "github.com/docker/docker/client"
+	"github.com/emon5122/dockwarden/internal/logging"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -24,14 +29,29 @@ type Client interface {
 	Ping() error
 	ListContainers(ctx context.Context, opts ListOptions) ([]Container, error)
 	GetContainer(ctx context.Context, id string) (Container, error)
+	InspectContainer(ctx context.Context, id string) (Container, ContainerDetails, error)
 	StopContainer(ctx context.Context, id string, timeout time.Duration) error
 	StartContainer(ctx context.Context, id string) error
-	RestartContainer(ctx context.Context, id string, timeout time.Duration) error
+	RestartContainer(ctx context.Context, id string, timeout time.Duration, signal string) error
 	RemoveContainer(ctx context.Context, id string) error
-	RecreateContainer(ctx context.Context, id string, timeout time.Duration) (string, error)
-	PullImage(ctx context.Context, imageName string) error
+	RecreateContainer(ctx context.Context, id string, opts RecreateOptions) (string, error)
+	RunJob(ctx context.Context, id string, job Job) (JobResult, error)
+	Exec(ctx context.Context, id string, cmd []string) (ExecResult, error)
+	PullImage(ctx context.Context, imageName string) (int64, error)
+	LoadImage(ctx context.Context, archive io.Reader) ([]string, error)
 	GetImageDigest(ctx context.Context, imageName string) (string, error)
+	InspectImage(ctx context.Context, imageName string) (ImageInfo, error)
+	IsLocalImage(ctx context.Context, imageName string) (bool, error)
 	RemoveImage(ctx context.Context, imageID string) error
+	ListImages(ctx context.Context) ([]Image, error)
+	ListNetworks(ctx context.Context) ([]NetworkInfo, error)
+	ListVolumes(ctx context.Context) ([]Volume, error)
+	TagImage(ctx context.Context, imageID, ref string) error
+	ListImageTags(ctx context.Context, repository string) ([]string, error)
+	Info(ctx context.Context) (SystemInfo, error)
+	Features(ctx context.Context) (Features, error)
+	DaemonHost() string
+	DiskUsage(ctx context.Context) (DiskUsage, error)
 }
 
 // ClientOptions configures the Docker client
@@ -39,23 +59,57 @@ type ClientOptions struct {
 	IncludeStopped    bool
 	IncludeRestarting bool
 	RemoveVolumes     bool
+	// ProtectedLabels are label patterns always preserved verbatim on recreate
+	ProtectedLabels []string
+	// APIVersion pins the Docker API version instead of negotiating it
+	APIVersion string
+	// Profile adds platform-specific preservation rules on recreate
+	Profile PlatformProfile
+	// PauseRestartPolicy disables the restart policy of a container before
+	// it is stopped for a recreate
+	PauseRestartPolicy bool
+}
+
+// RecreateOptions controls how a container is recreated
+type RecreateOptions struct {
+	// StopTimeout is the grace period given to the old container to stop
+	StopTimeout time.Duration
+	// Image, when set, replaces the container's image reference (tag upgrade)
+	Image string
+	// Labels are edits to the container's labels (see EditLabels)
+	Labels map[string]string
+	// NetworkContainer, when set, is the ID of the container a container
+	// sharing the network stack of another (network_mode: container:X)
+	// joins instead, e.g. the replacement of a recreated one
+	NetworkContainer string
+	// NoStart leaves the new container created but stopped, e.g. for a
+	// container that wasn't running before
+	NoStart bool
 }
 
 // ListOptions for filtering containers
 type ListOptions struct {
-	All           bool
-	LabelFilter   string
+	All         bool
+	LabelFilter string
+	// IncludeHealth inspects each container to fill in HealthStatus,
+	// StartedAt and RestartCount, which the list API doesn't report
 	IncludeHealth bool
 }
 
 type dockerClient struct {
-	api  dockerclient.CommonAPIClient
-	opts ClientOptions
+	api    dockerclient.CommonAPIClient
+	opts   ClientOptions
+	pinned bool
 }
 
 // NewClient creates a new Docker client
 func NewClient(opts ClientOptions) (Client, error) {
-	cli, err := dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation())
+	clientOpts := []dockerclient.Opt{dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation()}
+	if opts.APIVersion != "" {
+		clientOpts = append(clientOpts, dockerclient.WithVersion(opts.APIVersion))
+	}
+
+	cli, err := dockerclient.NewClientWithOpts(clientOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Docker client: %w", err)
 	}
@@ -63,6 +117,8 @@ func NewClient(opts ClientOptions) (Client, error) {
 	return &dockerClient{
 		api:  cli,
 		opts: opts,
+		// FromEnv pins the version given in DOCKER_API_VERSION
+		pinned: opts.APIVersion != "" || os.Getenv(dockerclient.EnvOverrideAPIVersion) != "",
 	}, nil
 }
 
@@ -94,6 +150,12 @@ func (c *dockerClient) ListContainers(ctx context.Context, opts ListOptions) ([]
 	result := make([]Container, 0, len(containers))
 	for _, ctr := range containers {
 		container := containerFromAPI(ctr)
+		if opts.IncludeHealth {
+			// A container removed since listing keeps its list entry
+			if info, err := c.api.ContainerInspect(ctx, ctr.ID); err == nil {
+				container = containerFromInspect(info)
+			}
+		}
 		result = append(result, container)
 	}
 
@@ -121,7 +183,10 @@ func (c *dockerClient) StopContainer(ctx context.Context, id string, timeout tim
 		return fmt.Errorf("failed to stop container %s: %w", id, err)
 	}
 
-	log.Debugf("Stopped container %s", id[:12])
+	logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldContainerID: shortID(id),
+		logging.FieldAction:      "stop",
+	}).Debug("Stopped container")
 	return nil
 }
 
@@ -131,14 +196,24 @@ func (c *dockerClient) StartContainer(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to start container %s: %w", id, err)
 	}
 
-	log.Debugf("Started container %s", id[:12])
+	logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldContainerID: shortID(id),
+		logging.FieldAction:      "start",
+	}).Debug("Started container")
 	return nil
 }
 
-// RestartContainer restarts a container
-func (c *dockerClient) RestartContainer(ctx context.Context, id string, timeout time.Duration) error {
+// RestartContainer restarts a container. An empty signal uses the
+// container's configured stop signal.
+func (c *dockerClient) RestartContainer(ctx context.Context, id string, timeout time.Duration, signal string) error {
+	if signal != "" && !c.supports(apiStopSignal) {
+		logging.FromContext(ctx).WithField("signal", signal).Debug("Daemon API doesn't support stop signals on restart, using the container's own")
+		signal = ""
+	}
+
 	timeoutSec := int(timeout.Seconds())
 	stopOpts := container.StopOptions{
+		Signal:  signal,
 		Timeout: &timeoutSec,
 	}
 
@@ -146,7 +221,10 @@ func (c *dockerClient) RestartContainer(ctx context.Context, id string, timeout
 		return fmt.Errorf("failed to restart container %s: %w", id, err)
 	}
 
-	log.Debugf("Restarted container %s", id[:12])
+	logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldContainerID: shortID(id),
+		logging.FieldAction:      "restart",
+	}).Debug("Restarted container")
 	return nil
 }
 
@@ -161,12 +239,15 @@ func (c *dockerClient) RemoveContainer(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to remove container %s: %w", id, err)
 	}
 
-	log.Debugf("Removed container %s", id[:12])
+	logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldContainerID: shortID(id),
+		logging.FieldAction:      "remove",
+	}).Debug("Removed container")
 	return nil
 }
 
 // RecreateContainer stops, removes, and recreates a container with the latest image
-func (c *dockerClient) RecreateContainer(ctx context.Context, id string, timeout time.Duration) (string, error) {
+func (c *dockerClient) RecreateContainer(ctx context.Context, id string, opts RecreateOptions) (string, error) {
 	// Get container config before removing
 	inspect, err := c.api.ContainerInspect(ctx, id)
 	if err != nil {
@@ -175,8 +256,12 @@ func (c *dockerClient) RecreateContainer(ctx context.Context, id string, timeout
 
 	containerName := strings.TrimPrefix(inspect.Name, "/")
 	oldImageID := inspect.Image
+	logger := logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldContainer: containerName,
+		logging.FieldAction:    "recreate",
+	})
 
-	log.Debugf("Recreating container %s with latest image", containerName)
+	logger.Debug("Recreating container with latest image")
 
 	// Build NetworkingConfig from current network settings
 	// This preserves networks, aliases, IP addresses, etc.
@@ -207,19 +292,85 @@ func (c *dockerClient) RecreateContainer(ctx context.Context, id string, timeout
 					MacAddress:          "", // Let Docker assign new MAC for clean DNS registration
 				}
 				networkingConfig.EndpointsConfig[netName] = endpointConfig
-				log.Debugf("Preserving network %s for container %s", netName, containerName)
+				logger.WithField("network", netName).Debug("Preserving network")
 			}
 		}
 	}
 
+	// Apply environment overrides from dockwarden.update.env.* labels
+	if overrides := containerFromInspect(inspect).EnvOverrides(); len(overrides) > 0 {
+		inspect.Config.Env = applyEnvOverrides(inspect.Config.Env, overrides)
+		keys := make([]string, 0, len(overrides))
+		for k := range overrides {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		logger.WithField("env", strings.Join(keys, ",")).Info("Applying environment overrides from labels")
+	}
+
+	// Switch to the requested image reference (dockwarden.update.target-tag).
+	// The target-tag label is consumed so the next cycle doesn't retag again.
+	if opts.Image != "" && opts.Image != inspect.Config.Image {
+		logger.WithFields(log.Fields{
+			"old_image": inspect.Config.Image,
+			"new_image": opts.Image,
+		}).Info("Retagging container image")
+		inspect.Config.Image = opts.Image
+		if inspect.Config.Labels != nil {
+			delete(inspect.Config.Labels, TargetTagLabel)
+		}
+	}
+
+	// Rebuild the label set: keep the container's own and protected labels,
+	// drop labels inherited from the old image and mark the container managed
+	var oldImageLabels map[string]string
+	if imageInspect, _, err := c.api.ImageInspectWithRaw(ctx, oldImageID); err == nil && imageInspect.Config != nil {
+		oldImageLabels = imageInspect.Config.Labels
+	} else if err != nil {
+		logger.WithError(err).Debug("Failed to inspect old image, keeping all labels")
+	}
+	identity := containerFromInspect(inspect).Identity()
+	inspect.Config.Labels = recreateLabels(inspect.Config.Labels, oldImageLabels, c.protectedLabels(), identity)
+	if c.opts.Profile.Preserve != nil {
+		c.opts.Profile.Preserve(inspect.Config, inspect.HostConfig)
+	}
+	if len(opts.Labels) > 0 {
+		inspect.Config.Labels = EditLabels(inspect.Config.Labels, opts.Labels)
+		logger.WithField("labels", strings.Join(slices.Sorted(maps.Keys(opts.Labels)), ",")).Info("Applying label edits")
+	}
+
+	// Apply the healthcheck override from dockwarden.healthcheck.* labels
+	if hc, ok, err := containerFromInspect(inspect).HealthcheckOverride(inspect.Config.Healthcheck); err != nil {
+		logger.WithError(err).Warn("Ignoring healthcheck labels")
+	} else if ok {
+		inspect.Config.Healthcheck = hc
+		logger.WithField("healthcheck", hc.Test).Info("Applying healthcheck override from labels")
+	}
+
+	// Join the network stack of the replacement of the container this one
+	// shared it with
+	if opts.NetworkContainer != "" && inspect.HostConfig.NetworkMode.IsContainer() {
+		inspect.HostConfig.NetworkMode = container.NetworkMode("container:" + opts.NetworkContainer)
+		logger.WithField("network_container", shortID(opts.NetworkContainer)).Info("Joining network stack of recreated container")
+	}
+
+	// The new container is created with the policy captured here, so a
+	// paused policy comes back with it
+	policy := inspect.HostConfig.RestartPolicy
+	paused := false
+
 	// Stop container if running
 	if inspect.State.Running {
-		timeoutSec := int(timeout.Seconds())
+		paused = c.pauseRestartPolicy(ctx, logger, id, policy)
+		timeoutSec := int(opts.StopTimeout.Seconds())
 		stopOpts := container.StopOptions{Timeout: &timeoutSec}
 		if err := c.api.ContainerStop(ctx, id, stopOpts); err != nil {
+			if paused {
+				c.restoreRestartPolicy(ctx, logger, id, policy)
+			}
 			return "", fmt.Errorf("failed to stop container %s: %w", id, err)
 		}
-		log.Debugf("Stopped container %s", containerName)
+		logger.Debug("Stopped container")
 	}
 
 	// Remove the container
@@ -227,48 +378,81 @@ func (c *dockerClient) RecreateContainer(ctx context.Context, id string, timeout
 		RemoveVolumes: false, // Preserve volumes
 		Force:         true,
 	}); err != nil {
+		if paused {
+			c.restoreRestartPolicy(ctx, logger, id, policy)
+		}
 		return "", fmt.Errorf("failed to remove container %s: %w", id, err)
 	}
-	log.Debugf("Removed old container %s", containerName)
+	logger.Debug("Removed old container")
+
+	// Daemons before API 1.44 reject more than one network on create; the
+	// others are connected after creating the container
+	createNetworking := networkingConfig
+	if len(networkingConfig.EndpointsConfig) > 1 && !c.supports(apiMultiNetworkCreate) {
+		names := slices.Sorted(maps.Keys(networkingConfig.EndpointsConfig))
+		createNetworking = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				names[0]: networkingConfig.EndpointsConfig[names[0]],
+			},
+		}
+	}
 
 	// Create new container with same config, host config, AND network config
-	createResp, err := c.api.ContainerCreate(ctx, inspect.Config, inspect.HostConfig, networkingConfig, nil, containerName)
+	createResp, err := c.api.ContainerCreate(ctx, inspect.Config, inspect.HostConfig, createNetworking, nil, containerName)
 	if err != nil {
 		return "", fmt.Errorf("failed to create container %s: %w", containerName, err)
 	}
 	newID := createResp.ID
-	log.Debugf("Created new container %s with ID %s", containerName, newID[:12])
+	logger.WithField("new_container_id", shortID(newID)).Debug("Created new container")
+	c.verifyRestartPolicy(ctx, logger, newID, policy)
 
-	// Connect to additional networks (ContainerCreate only connects to one network)
-	// We need to explicitly connect to other networks
-	networkCount := 0
+	// Connect to the networks ContainerCreate didn't handle
 	for netName, endpointConfig := range networkingConfig.EndpointsConfig {
-		networkCount++
-		if networkCount == 1 {
-			// First network is handled by ContainerCreate
+		if _, ok := createNetworking.EndpointsConfig[netName]; ok {
 			continue
 		}
 		// Connect to additional networks
 		if err := c.api.NetworkConnect(ctx, endpointConfig.NetworkID, newID, endpointConfig); err != nil {
-			log.Warnf("Failed to connect container %s to network %s: %v", containerName, netName, err)
+			logger.WithField("network", netName).WithError(err).Warn("Failed to connect container to network")
 		} else {
-			log.Debugf("Connected container %s to network %s", containerName, netName)
+			logger.WithField("network", netName).Debug("Connected container to network")
 		}
 	}
 
+	if opts.NoStart {
+		logger.Info("Created new container, left stopped")
+		return newID, nil
+	}
+
 	// Start the new container
 	if err := c.api.ContainerStart(ctx, newID, container.StartOptions{}); err != nil {
 		return "", fmt.Errorf("failed to start container %s: %w", containerName, err)
 	}
-	log.Infof("Started new container %s", containerName)
+	logger.Info("Started new container")
 
 	// Return old image ID for cleanup
 	_ = oldImageID // Available for caller to clean up if needed
 	return newID, nil
 }
 
-// PullImage pulls the latest version of an image
-func (c *dockerClient) PullImage(ctx context.Context, imageName string) error {
+// protectedLabels returns the configured protected label patterns plus
+// those of the platform profile
+func (c *dockerClient) protectedLabels() []string {
+	patterns := c.opts.ProtectedLabels
+	if patterns == nil {
+		patterns = DefaultProtectedLabels
+	}
+	return c.opts.Profile.protectedLabels(patterns)
+}
+
+// PullImage pulls the latest version of an image and returns the number of
+// bytes downloaded, as reported by the pull progress of each layer
+func (c *dockerClient) PullImage(ctx context.Context, imageName string) (int64, error) {
+	logger := logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldImage:  imageName,
+		logging.FieldAction: "pull",
+	})
+
 	// Get registry authentication
 	authStr := getRegistryAuth(imageName)
 
@@ -276,17 +460,24 @@ func (c *dockerClient) PullImage(ctx context.Context, imageName string) error {
 		RegistryAuth: authStr,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+		return 0, fmt.Errorf("failed to pull image %s: %w", imageName, err)
 	}
 	defer reader.Close()
 
-	// Consume the reader to complete the pull
+	// Consume the reader to complete the pull, remembering how far each
+	// layer got so the downloaded size can be reported
+	layers := make(map[string]int64)
 	decoder := json.NewDecoder(reader)
 	for {
 		var message struct {
-			Status   string `json:"status"`
-			Progress string `json:"progress"`
-			Error    string `json:"error"`
+			ID             string `json:"id"`
+			Status         string `json:"status"`
+			Progress       string `json:"progress"`
+			ProgressDetail struct {
+				Current int64 `json:"current"`
+				Total   int64 `json:"total"`
+			} `json:"progressDetail"`
+			Error string `json:"error"`
 		}
 
 		if err := decoder.Decode(&message); err != nil {
@@ -294,33 +485,130 @@ func (c *dockerClient) PullImage(ctx context.Context, imageName string) error {
 		}
 
 		if message.Error != "" {
-			return fmt.Errorf("pull error: %s", message.Error)
+			return downloaded(layers), fmt.Errorf("pull error: %s", message.Error)
 		}
 
-		log.Debugf("Pull %s: %s %s", imageName, message.Status, message.Progress)
+		if message.Status == "Downloading" && message.ProgressDetail.Current > layers[message.ID] {
+			layers[message.ID] = message.ProgressDetail.Current
+		}
+
+		logger.WithFields(log.Fields{
+			"status":   message.Status,
+			"progress": message.Progress,
+		}).Debug("Pull progress")
 	}
 
 	// Only log at debug level - the caller will log if there's an actual update
-	log.Debugf("Pulled image %s", imageName)
-	return nil
+	bytes := downloaded(layers)
+	logger.WithField("bytes", bytes).Debug("Pulled image")
+	return bytes, nil
+}
+
+// LoadImage loads a docker-save archive and returns the references of the
+// loaded images, or their IDs for images saved without a tag
+func (c *dockerClient) LoadImage(ctx context.Context, archive io.Reader) ([]string, error) {
+	resp, err := c.api.ImageLoad(ctx, archive, dockerclient.ImageLoadWithQuiet(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load image archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var loaded []string
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+		}
+
+		if err := decoder.Decode(&message); err != nil {
+			break
+		}
+
+		if message.Error != "" {
+			return loaded, fmt.Errorf("load error: %s", message.Error)
+		}
+
+		line := strings.TrimSpace(message.Stream)
+		if ref, ok := strings.CutPrefix(line, "Loaded image: "); ok {
+			loaded = append(loaded, ref)
+		} else if id, ok := strings.CutPrefix(line, "Loaded image ID: "); ok {
+			loaded = append(loaded, id)
+		}
+	}
+
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"images":            loaded,
+		logging.FieldAction: "load",
+	}).Debug("Loaded image archive")
+	return loaded, nil
+}
+
+// downloaded sums the downloaded bytes of all layers
+func downloaded(layers map[string]int64) int64 {
+	var total int64
+	for _, n := range layers {
+		total += n
+	}
+	return total
 }
 
-// GetImageDigest returns the digest for an image
+// GetImageDigest returns the digest for an image. For images pulled from a
+// registry this is the manifest (or manifest-list) digest, so the value is
+// stable across platforms and comparable with what the registry advertises.
 func (c *dockerClient) GetImageDigest(ctx context.Context, imageName string) (string, error) {
 	inspect, _, err := c.api.ImageInspectWithRaw(ctx, imageName)
 	if err != nil {
 		return "", fmt.Errorf("failed to inspect image %s: %w", imageName, err)
 	}
 
-	if len(inspect.RepoDigests) > 0 {
-		// Extract digest from repo digest (format: repo@sha256:...)
-		parts := strings.Split(inspect.RepoDigests[0], "@")
-		if len(parts) == 2 {
-			return parts[1], nil
-		}
+	return imageDigest(imageName, inspect), nil
+}
+
+// ImageInfo is the metadata of a local image
+type ImageInfo struct {
+	ID      string
+	Digest  string
+	Created time.Time
+	Labels  map[string]string
+	// Platform is the platform the image was built for
+	Platform Platform
+}
+
+// InspectImage returns the digest, creation time, labels and platform of an
+// image
+func (c *dockerClient) InspectImage(ctx context.Context, imageName string) (ImageInfo, error) {
+	inspect, _, err := c.api.ImageInspectWithRaw(ctx, imageName)
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("failed to inspect image %s: %w", imageName, err)
 	}
 
-	return inspect.ID, nil
+	info := ImageInfo{
+		ID:     inspect.ID,
+		Digest: imageDigest(imageName, inspect),
+		Platform: Platform{
+			OS:      inspect.Os,
+			Arch:    inspect.Architecture,
+			Variant: inspect.Variant,
+		},
+	}
+	if created, err := time.Parse(time.RFC3339Nano, inspect.Created); err == nil {
+		info.Created = created
+	}
+	if inspect.Config != nil {
+		info.Labels = inspect.Config.Labels
+	}
+	return info, nil
+}
+
+// IsLocalImage reports whether an image exists only locally (built or loaded
+// rather than pulled), i.e. it has no registry digest to check against
+func (c *dockerClient) IsLocalImage(ctx context.Context, imageName string) (bool, error) {
+	inspect, _, err := c.api.ImageInspectWithRaw(ctx, imageName)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+	return len(inspect.RepoDigests) == 0, nil
 }
 
 // RemoveImage removes an image
@@ -333,10 +621,48 @@ func (c *dockerClient) RemoveImage(ctx context.Context, imageID string) error {
 		return fmt.Errorf("failed to remove image %s: %w", imageID, err)
 	}
 
-	log.Debugf("Removed image %s", imageID[:12])
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"image_id":          shortID(imageID),
+		logging.FieldAction: "remove_image",
+	}).Debug("Removed image")
 	return nil
 }
 
+// TagImage adds the tag ref to an image
+func (c *dockerClient) TagImage(ctx context.Context, imageID, ref string) error {
+	if err := c.api.ImageTag(ctx, imageID, ref); err != nil {
+		return fmt.Errorf("failed to tag image %s as %s: %w", shortID(imageID), ref, err)
+	}
+
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"image_id":          shortID(imageID),
+		"tag":               ref,
+		logging.FieldAction: "tag_image",
+	}).Debug("Tagged image")
+	return nil
+}
+
+// ListImageTags returns the local tags (as repository:tag) of a repository
+func (c *dockerClient) ListImageTags(ctx context.Context, repository string) ([]string, error) {
+	images, err := c.api.ImageList(ctx, image.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("reference", repository)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images of %s: %w", repository, err)
+	}
+
+	var tags []string
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			if strings.HasPrefix(tag, repository+":") {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
 // getRegistryAuth returns the base64 encoded auth for a registry
 func getRegistryAuth(imageName string) string {
 	// Determine the registry from image name
@@ -347,12 +673,12 @@ func getRegistryAuth(imageName string) string {
 
 	for _, configPath := range configPaths {
 		if auth := getAuthFromConfig(configPath, registry); auth != "" {
-			log.Debugf("Found auth for registry %s from %s", registry, configPath)
+			log.WithFields(log.Fields{"registry": registry, "config": configPath}).Debug("Found registry auth")
 			return auth
 		}
 	}
 
-	log.Debugf("No auth found for registry %s", registry)
+	log.WithField("registry", registry).Debug("No registry auth found")
 	return ""
 }
 
@@ -422,7 +748,7 @@ func getAuthFromConfig(configPath string, registryHost string) string {
 	}
 
 	if err := json.Unmarshal(data, &dockerConfig); err != nil {
-		log.Debugf("Failed to parse docker config %s: %v", configPath, err)
+		log.WithField("config", configPath).WithError(err).Debug("Failed to parse docker config")
 		return ""
 	}
 
@@ -432,13 +758,13 @@ func getAuthFromConfig(configPath string, registryHost string) string {
 		// Docker API expects base64(json{"username":"x","password":"y","serveraddress":"z"})
 		decoded, err := base64.StdEncoding.DecodeString(authBase64)
 		if err != nil {
-			log.Debugf("Failed to decode auth: %v", err)
+			log.WithError(err).Debug("Failed to decode auth")
 			return ""
 		}
 
 		parts := strings.SplitN(string(decoded), ":", 2)
 		if len(parts) != 2 {
-			log.Debugf("Invalid auth format")
+			log.Debug("Invalid auth format")
 			return ""
 		}
 
@@ -450,7 +776,7 @@ func getAuthFromConfig(configPath string, registryHost string) string {
 
 		jsonAuth, err := json.Marshal(authConfig)
 		if err != nil {
-			log.Debugf("Failed to marshal auth config: %v", err)
+			log.WithError(err).Debug("Failed to marshal auth config")
 			return ""
 		}
 
@@ -485,6 +811,15 @@ func getAuthFromConfig(configPath string, registryHost string) string {
 	return ""
 }
 
+// shortID truncates a container or image ID for log output
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
 // containerFromAPI converts API container to our Container type
 func containerFromAPI(c types.Container) Container {
 	name := ""
@@ -501,7 +836,33 @@ func containerFromAPI(c types.Container) Container {
 		Status:  c.Status,
 		Labels:  c.Labels,
 		Created: time.Unix(c.Created, 0),
+		Ports:   portsFromAPI(c.Ports),
+		Mounts:  mountsFromAPI(c.Mounts),
+
+		NetworkMode: c.HostConfig.NetworkMode,
+		Networks:    summaryNetworks(c),
+		IPAddresses: summaryIPAddresses(c),
+	}
+}
+
+// summaryNetworks returns the networks of a listed container
+func summaryNetworks(c types.Container) []string {
+	if c.NetworkSettings == nil {
+		return nil
 	}
+	return networkNames(c.NetworkSettings.Networks)
+}
+
+// summaryIPAddresses returns the addresses of a listed container on its
+// networks, sorted by network name
+func summaryIPAddresses(c types.Container) []string {
+	var addrs []string
+	for _, name := range summaryNetworks(c) {
+		if ep := c.NetworkSettings.Networks[name]; ep != nil && ep.IPAddress != "" {
+			addrs = append(addrs, ep.IPAddress)
+		}
+	}
+	return addrs
 }
 
 // containerFromInspect converts inspect result to our Container type
@@ -512,6 +873,11 @@ func containerFromInspect(info types.ContainerJSON) Container {
 		healthStatus = info.State.Health.Status
 	}
 
+	startedAt := time.Time{}
+	if started, err := time.Parse(time.RFC3339Nano, info.State.StartedAt); err == nil && started.Year() > 1 {
+		startedAt = started
+	}
+
 	return Container{
 		ID:           info.ID,
 		Name:         strings.TrimPrefix(info.Name, "/"),
@@ -522,5 +888,49 @@ func containerFromInspect(info types.ContainerJSON) Container {
 		Labels:       info.Config.Labels,
 		Created:      created,
 		HealthStatus: healthStatus,
+		StartedAt:    startedAt,
+		RestartCount: info.RestartCount,
+		Ports:        portsFromInspect(info),
+		Mounts:       mountsFromAPI(info.Mounts),
+		NetworkMode:  networkMode(info),
+		Networks:     inspectNetworks(info),
+		IPAddresses:  ipAddresses(info),
+	}
+}
+
+// inspectNetworks returns the networks of an inspected container
+func inspectNetworks(info types.ContainerJSON) []string {
+	if info.NetworkSettings == nil {
+		return nil
+	}
+	return networkNames(info.NetworkSettings.Networks)
+}
+
+// ipAddresses returns the addresses of an inspected container on its
+// networks, sorted by network name
+func ipAddresses(info types.ContainerJSON) []string {
+	if info.NetworkSettings == nil {
+		return nil
+	}
+	networks := make([]string, 0, len(info.NetworkSettings.Networks))
+	for name := range info.NetworkSettings.Networks {
+		networks = append(networks, name)
+	}
+	sort.Strings(networks)
+
+	var addrs []string
+	for _, name := range networks {
+		if ep := info.NetworkSettings.Networks[name]; ep != nil && ep.IPAddress != "" {
+			addrs = append(addrs, ep.IPAddress)
+		}
+	}
+	return addrs
+}
+
+// networkMode returns the network mode of an inspected container
+func networkMode(info types.ContainerJSON) string {
+	if info.HostConfig == nil {
+		return ""
 	}
+	return string(info.HostConfig.NetworkMode)
 }
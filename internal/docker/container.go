@@ -1,6 +1,47 @@
 package docker
 
-import "time"
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnvOverrideLabelPrefix marks labels whose suffix names an environment
+// variable to set on the container when it is recreated
+const EnvOverrideLabelPrefix = "dockwarden.update.env."
+
+// TargetTagLabel requests that the container be moved to another tag of the
+// same image repository on its next update
+const TargetTagLabel = "dockwarden.update.target-tag"
+
+// Tag filter labels constrain which upstream tags a container may move to
+const (
+	TagsIncludeLabel = "dockwarden.tags.include"
+	TagsExcludeLabel = "dockwarden.tags.exclude"
+)
+
+// GroupLabel puts a container into an update group
+const GroupLabel = "dockwarden.group"
+
+// HealthExecLabel holds a command run inside an unhealthy container before
+// it is restarted, for services a soft reload usually fixes
+const HealthExecLabel = "dockwarden.health.exec"
+
+// Ownership labels say who to contact about a container on shared hosts
+const (
+	OwnerLabel = "dockwarden.owner"
+	NotesLabel = "dockwarden.notes"
+)
+
+// Labels of willfarrell/autoheal, honored so stacks moving over from it keep
+// working without relabeling
+const (
+	AutohealLabel            = "autoheal"
+	AutohealStopTimeoutLabel = "autoheal.stop.timeout"
+)
 
 // Container represents a Docker container
 type Container struct {
@@ -13,6 +54,25 @@ type Container struct {
 	Labels       map[string]string
 	Created      time.Time
 	HealthStatus string
+
+	// StartedAt and RestartCount are only known from inspect (see
+	// ListOptions.IncludeHealth); StartedAt is zero if it never started
+	StartedAt    time.Time
+	RestartCount int
+
+	// Ports and Mounts summarize published ports and volumes
+	Ports  []Port
+	Mounts []Mount
+
+	// NetworkMode is the network mode of the container, container:<id> for
+	// one sharing the network stack of another (network_mode: service:X)
+	NetworkMode string
+	// Networks are the names of the networks the container is attached to,
+	// sorted
+	Networks []string
+	// IPAddresses are the container's addresses on its networks, sorted by
+	// network name
+	IPAddresses []string
 }
 
 // IsRunning returns true if the container is running
@@ -30,6 +90,38 @@ func (c Container) IsUnhealthy() bool {
 	return c.HealthStatus == "unhealthy"
 }
 
+// TCPProbeLabel opts a container without a HEALTHCHECK into, or out of,
+// the synthetic TCP liveness probe
+const TCPProbeLabel = "dockwarden.health.tcp-probe"
+
+// TCPProbeEnabled reports whether the watcher should probe the container's
+// first published TCP port, from its label or the global default
+func (c Container) TCPProbeEnabled(def bool) bool {
+	switch c.GetLabel(TCPProbeLabel) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return def
+}
+
+// ProbePort returns the first published TCP port, or false if there is none
+func (c Container) ProbePort() (Port, bool) {
+	for _, p := range c.Ports {
+		if p.Type == "tcp" && p.PublicPort != 0 {
+			return p, true
+		}
+	}
+	return Port{}, false
+}
+
+// HealthExec returns the command to run inside the container when it turns
+// unhealthy, or an empty string
+func (c Container) HealthExec() string {
+	return strings.TrimSpace(c.GetLabel(HealthExecLabel))
+}
+
 // GetLabel returns a label value or empty string
 func (c Container) GetLabel(key string) string {
 	if c.Labels == nil {
@@ -64,27 +156,64 @@ func (c Container) UpdateEnabled() bool {
 	return label == "true"
 }
 
-// WatchEnabled returns true if health watching is enabled for this container
+// WatchEnabled returns true if health watching is enabled for this container.
+// The autoheal label is an alias of dockwarden.watch.enable.
 func (c Container) WatchEnabled() bool {
 	label := c.GetLabel("dockwarden.watch.enable")
+	if label == "" {
+		label = c.GetLabel(AutohealLabel)
+	}
 	if label == "" {
 		return true // Default to enabled
 	}
 	return label == "true"
 }
 
-// GetStopSignal returns the configured stop signal or default
-func (c Container) GetStopSignal() string {
-	signal := c.GetLabel("dockwarden.stop-signal")
-	if signal == "" {
-		return "SIGTERM"
+// WatchSelected reports whether the container opted in to management with
+// labelName, or to health watching with autoheal=true, for label-enable mode
+func (c Container) WatchSelected(labelName string) bool {
+	return c.IsEnabled(labelName, false) || c.GetLabel(AutohealLabel) == "true"
+}
+
+// CleanupEnabled returns whether the old image should be removed after an
+// update. The dockwarden.cleanup label overrides the global setting.
+func (c Container) CleanupEnabled(defaultCleanup bool) bool {
+	switch c.GetLabel("dockwarden.cleanup") {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return defaultCleanup
 	}
-	return signal
+}
+
+// RollbackEnabled returns whether the container is rolled back to its
+// previous image when it exits or turns unhealthy right after an update. The
+// dockwarden.rollback.enable label overrides the global setting.
+func (c Container) RollbackEnabled(defaultRollback bool) bool {
+	switch c.GetLabel("dockwarden.rollback.enable") {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return defaultRollback
+	}
+}
+
+// GetStopSignal returns the signal set with the dockwarden.stop-signal label.
+// It is empty when unset, leaving the container's own stop signal in place.
+func (c Container) GetStopSignal() string {
+	return c.GetLabel("dockwarden.stop-signal")
 }
 
 // GetStopTimeout returns the configured stop timeout or default
 func (c Container) GetStopTimeout(defaultTimeout time.Duration) time.Duration {
 	timeoutStr := c.GetLabel("dockwarden.stop-timeout")
+	if timeoutStr == "" {
+		timeoutStr = c.GetLabel(AutohealStopTimeoutLabel)
+	}
 	if timeoutStr == "" {
 		return defaultTimeout
 	}
@@ -96,7 +225,217 @@ func (c Container) GetStopTimeout(defaultTimeout time.Duration) time.Duration {
 	return timeout
 }
 
+// GetGroup returns the dockwarden.group label value. Containers sharing a
+// group are updated together.
+func (c Container) GetGroup() string {
+	return strings.TrimSpace(c.GetLabel(GroupLabel))
+}
+
+// DependsOn returns the container names listed in dockwarden.depends-on
+func (c Container) DependsOn() []string {
+	return c.nameList("dockwarden.depends-on")
+}
+
+// RestartWith returns the container names listed in dockwarden.restart-with
+func (c Container) RestartWith() []string {
+	return c.nameList("dockwarden.restart-with")
+}
+
+// nameList returns the comma-separated container names of a label
+func (c Container) nameList(key string) []string {
+	var names []string
+	for _, name := range strings.Split(c.GetLabel(key), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, strings.TrimPrefix(name, "/"))
+		}
+	}
+	return names
+}
+
+// RestartsWith reports whether the container must be restarted after other
+// is: it lists other's name or identity in dockwarden.restart-with or shares
+// its network stack, and would keep dead connections otherwise
+func (c Container) RestartsWith(other Container) bool {
+	if c.ID == other.ID {
+		return false
+	}
+	if names := c.RestartWith(); slices.Contains(names, other.Name) || slices.Contains(names, other.Identity()) {
+		return true
+	}
+	target := c.NetworkContainer()
+	if target == "" {
+		return false
+	}
+	return target == other.Name || strings.HasPrefix(other.ID, target)
+}
+
+// NetworkContainer returns the name or ID of the container whose network
+// stack the container shares (network_mode: container:X), or ""
+func (c Container) NetworkContainer() string {
+	target, _ := strings.CutPrefix(c.NetworkMode, "container:")
+	if target == c.NetworkMode {
+		return ""
+	}
+	return target
+}
+
 // GetScope returns the scope label value
 func (c Container) GetScope() string {
 	return c.GetLabel("dockwarden.scope")
 }
+
+// Identity returns the stable identity of the container: its
+// dockwarden.id label, or the scope and name of a container that hasn't
+// been recreated yet. Unlike the ID it stays the same across updates.
+func (c Container) Identity() string {
+	if id := strings.TrimSpace(c.GetLabel(IdentityLabel)); id != "" {
+		return id
+	}
+	if scope := c.GetScope(); scope != "" {
+		return scope + "/" + c.Name
+	}
+	return c.Name
+}
+
+// EnvOverrides returns the environment variables to set on recreate, taken
+// from dockwarden.update.env.<KEY>=<value> labels
+func (c Container) EnvOverrides() map[string]string {
+	overrides := make(map[string]string)
+	for key, value := range c.Labels {
+		if !strings.HasPrefix(key, EnvOverrideLabelPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, EnvOverrideLabelPrefix)
+		if name == "" || strings.Contains(name, "=") {
+			continue
+		}
+		overrides[name] = value
+	}
+	return overrides
+}
+
+// applyEnvOverrides sets each override in env, replacing an existing entry
+// for the same variable or appending a new one. Keys are applied in sorted
+// order so the resulting Env is deterministic.
+func applyEnvOverrides(env []string, overrides map[string]string) []string {
+	if len(overrides) == 0 {
+		return env
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]string, len(env))
+	copy(result, env)
+
+	for _, key := range keys {
+		entry := key + "=" + overrides[key]
+		replaced := false
+		for i, existing := range result {
+			if existing == key || strings.HasPrefix(existing, key+"=") {
+				result[i] = entry
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// TargetImage returns the image reference the container should be moved to
+// according to its dockwarden.update.target-tag label, or "" when no retag
+// is requested or the container already runs that tag
+func (c Container) TargetImage() string {
+	tag := strings.TrimSpace(c.GetLabel(TargetTagLabel))
+	if tag == "" {
+		return ""
+	}
+	target := ReplaceTag(c.Image, tag)
+	if target == c.Image {
+		return ""
+	}
+	return target
+}
+
+// ReplaceTag returns imageName with its tag (and any digest) replaced by tag
+// Examples:
+//   - nginx:1.27 + 1.28 -> nginx:1.28
+//   - localhost:5000/app + v2 -> localhost:5000/app:v2
+//   - nginx@sha256:... + 1.28 -> nginx:1.28
+func ReplaceTag(imageName, tag string) string {
+	return stripTag(imageName) + ":" + tag
+}
+
+// stripTag removes the tag and digest from an image reference, keeping a
+// registry port intact (localhost:5000/app:v1 -> localhost:5000/app)
+func stripTag(imageName string) string {
+	repo := imageName
+	if idx := strings.Index(repo, "@"); idx != -1 {
+		repo = repo[:idx]
+	}
+	if idx := strings.LastIndex(repo, ":"); idx != -1 && !strings.Contains(repo[idx+1:], "/") {
+		repo = repo[:idx]
+	}
+	return repo
+}
+
+// TagFilter restricts the upstream tags considered for a container
+type TagFilter struct {
+	Include *regexp.Regexp
+	Exclude *regexp.Regexp
+}
+
+// TagFilter compiles the dockwarden.tags.include and dockwarden.tags.exclude
+// regular expressions of the container
+func (c Container) TagFilter() (TagFilter, error) {
+	var filter TagFilter
+	var err error
+	if filter.Include, err = compileTagLabel(c, TagsIncludeLabel); err != nil {
+		return TagFilter{}, err
+	}
+	if filter.Exclude, err = compileTagLabel(c, TagsExcludeLabel); err != nil {
+		return TagFilter{}, err
+	}
+	return filter, nil
+}
+
+func compileTagLabel(c Container, label string) (*regexp.Regexp, error) {
+	expr := strings.TrimSpace(c.GetLabel(label))
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s regex: %w", label, err)
+	}
+	return re, nil
+}
+
+// Allows reports whether tag passes the filter: it must match the include
+// expression, when set, and must not match the exclude expression
+func (f TagFilter) Allows(tag string) bool {
+	if f.Include != nil && !f.Include.MatchString(tag) {
+		return false
+	}
+	if f.Exclude != nil && f.Exclude.MatchString(tag) {
+		return false
+	}
+	return true
+}
+
+// Filter returns the tags allowed by the filter, keeping their order
+func (f TagFilter) Filter(tags []string) []string {
+	allowed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if f.Allows(tag) {
+			allowed = append(allowed, tag)
+		}
+	}
+	return allowed
+}
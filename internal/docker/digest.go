@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+)
+
+// imageDigest picks the digest used to detect that an image reference now
+// points at different content.
+//
+// For multi-arch images the daemon records the manifest-list (index) digest
+// the tag resolved to in RepoDigests, while the image ID is the digest of the
+// platform-specific config. Registries only ever advertise the manifest-list
+// digest for such tags, so comparing against the image ID would report a
+// perpetual update on arm hosts. The order of preference is:
+//
+//  1. the RepoDigest belonging to the image's own repository (an image can be
+//     tagged in several repositories, each with its own digest)
+//  2. the target descriptor digest reported by the containerd image store
+//  3. the first RepoDigest, for images only known under another name
+//  4. the image ID, for images that were never pulled (built or loaded locally)
+func imageDigest(imageName string, inspect image.InspectResponse) string {
+	repo := RepositoryName(imageName)
+	for _, repoDigest := range inspect.RepoDigests {
+		name, digest, ok := strings.Cut(repoDigest, "@")
+		if ok && RepositoryName(name) == repo {
+			return digest
+		}
+	}
+
+	if inspect.Descriptor != nil && inspect.Descriptor.Digest != "" {
+		return inspect.Descriptor.Digest.String()
+	}
+
+	if len(inspect.RepoDigests) > 0 {
+		if _, digest, ok := strings.Cut(inspect.RepoDigests[0], "@"); ok {
+			return digest
+		}
+	}
+
+	return inspect.ID
+}
+
+// RepositoryName returns the fully-qualified repository of an image reference
+// without tag or digest, e.g. "nginx:1.27" -> "docker.io/library/nginx".
+// References that cannot be parsed are returned with only the tag stripped.
+func RepositoryName(imageName string) string {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return stripTag(imageName)
+	}
+	return named.Name()
+}
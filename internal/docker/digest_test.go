@@ -0,0 +1,134 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+// The fixtures in testdata/inspect are image inspect responses recorded on
+// arm hosts, testdata/manifests the manifest lists the registry served for
+// their tags. A registry advertises the digest of the manifest list bytes,
+// so that is what imageDigest has to return for pulled multi-arch images.
+
+func TestImageDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   string
+		inspect string
+		// index is the manifest list whose digest is expected
+		index string
+		// want is the expected digest when there is no manifest list
+		want string
+	}{
+		{
+			name:    "manifest list on arm64",
+			image:   "nginx:1.27",
+			inspect: "nginx-arm64.json",
+			index:   "nginx-1.27.json",
+		},
+		{
+			name:    "docker manifest list on arm v7",
+			image:   "alpine:3.20",
+			inspect: "alpine-armv7.json",
+			index:   "alpine-3.20.json",
+		},
+		{
+			name:    "repo digest of the image's own repository",
+			image:   "docker.io/library/nginx:1.27",
+			inspect: "nginx-mirrored.json",
+			index:   "nginx-1.27.json",
+		},
+		{
+			name:    "repo digest of a mirror",
+			image:   "mirror.example.com/library/nginx:1.27",
+			inspect: "nginx-mirrored.json",
+			want:    "sha256:0437f1ed5c5f39eb84a55f85f71846c6bec270baca0db7e711f3d5be981b2e01",
+		},
+		{
+			name:    "containerd store descriptor",
+			image:   "ghcr.io/example/app:2.4",
+			inspect: "app-containerd.json",
+			index:   "app-2.4.json",
+		},
+		{
+			name:    "repo digest under another name",
+			image:   "app:local",
+			inspect: "app-retagged.json",
+			index:   "app-2.4.json",
+		},
+		{
+			name:    "locally built image",
+			image:   "example/tool:dev",
+			inspect: "local-build.json",
+			want:    "sha256:cb37be42ddd902b12351f701fe117683c3bd8de5670b95ad20db80737ddac51f",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspect := loadInspect(t, tt.inspect)
+			want := tt.want
+			if tt.index != "" {
+				want = manifestDigest(t, tt.index)
+			}
+
+			got := imageDigest(tt.image, inspect)
+			if got != want {
+				t.Errorf("imageDigest(%q) = %s, want %s", tt.image, got, want)
+			}
+			if tt.index != "" && got == inspect.ID && inspect.Descriptor == nil {
+				t.Errorf("imageDigest(%q) returned the platform image ID", tt.image)
+			}
+		})
+	}
+}
+
+func TestRepositoryName(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx", "docker.io/library/nginx"},
+		{"nginx:1.27", "docker.io/library/nginx"},
+		{"library/nginx@sha256:461fece1eb3086e4092949a8e3488b7fbb0ebd02f5ac3a300c0b9ad3a2eb6df1", "docker.io/library/nginx"},
+		{"ghcr.io/example/app:2.4", "ghcr.io/example/app"},
+		{"registry.example.com:5000/team/app:1.0", "registry.example.com:5000/team/app"},
+		{"Invalid/Name:1.0", "Invalid/Name"},
+	}
+
+	for _, tt := range tests {
+		if got := RepositoryName(tt.image); got != tt.want {
+			t.Errorf("RepositoryName(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+func loadInspect(t *testing.T, name string) image.InspectResponse {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "inspect", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inspect image.InspectResponse
+	if err := json.Unmarshal(data, &inspect); err != nil {
+		t.Fatalf("failed to parse %s: %v", name, err)
+	}
+	return inspect
+}
+
+// manifestDigest returns the digest a registry advertises for a recorded
+// manifest list
+func manifestDigest(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "manifests", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ComposeOneoffLabel marks containers started with "docker compose run"
+const ComposeOneoffLabel = "com.docker.compose.oneoff"
+
+// Ephemeral container heuristics
+const (
+	// EphemeralGeneratedName matches names the daemon generated for a
+	// container started without --name, e.g. "elegant_turing"
+	EphemeralGeneratedName = "generated-name"
+	// EphemeralOneoff matches containers started with "docker compose run"
+	EphemeralOneoff = "oneoff"
+)
+
+// generatedName matches the names of the daemon's name generator: an
+// adjective, an underscore and a surname, with a digit appended on retries
+var generatedName = regexp.MustCompile(`^([a-z]+)_[a-z]+[0-9]?$`)
+
+// generatedNameAdjectives are the first words the name generator uses
+var generatedNameAdjectives = map[string]struct{}{
+	"admiring": {}, "adoring": {}, "affectionate": {}, "agitated": {},
+	"amazing": {}, "angry": {}, "awesome": {}, "beautiful": {},
+	"blissful": {}, "bold": {}, "boring": {}, "brave": {}, "busy": {},
+	"charming": {}, "clever": {}, "compassionate": {}, "competent": {},
+	"condescending": {}, "confident": {}, "cool": {}, "cranky": {},
+	"crazy": {}, "dazzling": {}, "determined": {}, "distracted": {},
+	"dreamy": {}, "eager": {}, "ecstatic": {}, "elastic": {}, "elated": {},
+	"elegant": {}, "eloquent": {}, "epic": {}, "exciting": {},
+	"fervent": {}, "festive": {}, "flamboyant": {}, "focused": {},
+	"friendly": {}, "frosty": {}, "funny": {}, "gallant": {}, "gifted": {},
+	"goofy": {}, "gracious": {}, "great": {}, "happy": {}, "hardcore": {},
+	"heuristic": {}, "hopeful": {}, "hungry": {}, "infallible": {},
+	"inspiring": {}, "intelligent": {}, "interesting": {}, "jolly": {},
+	"jovial": {}, "keen": {}, "kind": {}, "laughing": {}, "loving": {},
+	"lucid": {}, "magical": {}, "modest": {}, "musing": {},
+	"mystifying": {}, "naughty": {}, "nervous": {}, "nice": {},
+	"nifty": {}, "nostalgic": {}, "objective": {}, "optimistic": {},
+	"peaceful": {}, "pedantic": {}, "pensive": {}, "practical": {},
+	"priceless": {}, "quirky": {}, "quizzical": {}, "recursing": {},
+	"relaxed": {}, "reverent": {}, "romantic": {}, "sad": {}, "serene": {},
+	"sharp": {}, "silly": {}, "sleepy": {}, "stoic": {}, "strange": {},
+	"stupefied": {}, "suspicious": {}, "sweet": {}, "tender": {},
+	"thirsty": {}, "trusting": {}, "unruffled": {}, "upbeat": {},
+	"vibrant": {}, "vigilant": {}, "vigorous": {}, "wizardly": {},
+	"wonderful": {}, "xenodochial": {}, "youthful": {}, "zealous": {},
+	"zen": {},
+}
+
+// EphemeralReason returns why ctr looks like a short-lived container
+// created by CI or other tooling, according to the enabled rules, or ""
+// when it doesn't. Containers younger than minAge are ephemeral as well;
+// zero disables the age check.
+func (c Container) EphemeralReason(rules []string, minAge time.Duration, now time.Time) string {
+	for _, rule := range rules {
+		switch strings.TrimSpace(rule) {
+		case EphemeralGeneratedName:
+			if m := generatedName.FindStringSubmatch(c.Name); m != nil {
+				if _, ok := generatedNameAdjectives[m[1]]; ok {
+					return "name was generated by Docker"
+				}
+			}
+		case EphemeralOneoff:
+			if strings.EqualFold(c.GetLabel(ComposeOneoffLabel), "true") {
+				return "started with docker compose run"
+			}
+		}
+	}
+	if minAge > 0 && !c.Created.IsZero() && now.Sub(c.Created) < minAge {
+		return "created less than " + minAge.String() + " ago"
+	}
+	return ""
+}
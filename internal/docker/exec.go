@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxExecOutput caps the output kept from an exec, per stream
+const maxExecOutput = 1 << 20
+
+// ExecResult is the outcome of a command executed in a container
+type ExecResult struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+// Exec runs cmd inside the running container id and waits for it to finish.
+// Output beyond 1 MiB per stream is discarded.
+func (c *dockerClient) Exec(ctx context.Context, id string, cmd []string) (ExecResult, error) {
+	created, err := c.api.ContainerExecCreate(ctx, id, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to create exec in container %s: %w", id, err)
+	}
+
+	attach, err := c.api.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to attach to exec in container %s: %w", id, err)
+	}
+	defer attach.Close()
+
+	stdout := &limitedBuffer{max: maxExecOutput}
+	stderr := &limitedBuffer{max: maxExecOutput}
+	done := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdout, stderr, attach.Reader)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && err != io.EOF {
+			return ExecResult{}, fmt.Errorf("failed to read exec output: %w", err)
+		}
+	case <-ctx.Done():
+		return ExecResult{}, fmt.Errorf("exec in container %s: %w", id, ctx.Err())
+	}
+
+	inspect, err := c.api.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to inspect exec in container %s: %w", id, err)
+	}
+
+	logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldContainerID: shortID(id),
+		logging.FieldAction:      "exec",
+		"exit_code":              inspect.ExitCode,
+	}).Debug("Executed command in container")
+
+	return ExecResult{
+		ExitCode: inspect.ExitCode,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}, nil
+}
+
+// limitedBuffer keeps the first max bytes written and discards the rest
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// Healthcheck labels replace or add the HEALTHCHECK of a container when it
+// is recreated, so images with a broken healthcheck can be fixed without
+// rebuilding them
+const (
+	HealthcheckCmdLabel         = "dockwarden.healthcheck.cmd"
+	HealthcheckIntervalLabel    = "dockwarden.healthcheck.interval"
+	HealthcheckTimeoutLabel     = "dockwarden.healthcheck.timeout"
+	HealthcheckStartPeriodLabel = "dockwarden.healthcheck.start-period"
+	HealthcheckRetriesLabel     = "dockwarden.healthcheck.retries"
+)
+
+// HealthcheckNone as the command disables the healthcheck, like
+// HEALTHCHECK NONE in a Dockerfile
+const HealthcheckNone = "NONE"
+
+// HealthcheckOverride applies the container's healthcheck labels to current,
+// the healthcheck of its configuration, and reports whether any label is
+// set. The command is run with the container's shell (CMD-SHELL); settings
+// without a label keep their current value. Without a command label the
+// image's command is used, as with `docker run --health-interval`.
+func (c Container) HealthcheckOverride(current *container.HealthConfig) (*container.HealthConfig, bool, error) {
+	cmd := strings.TrimSpace(c.GetLabel(HealthcheckCmdLabel))
+	interval := strings.TrimSpace(c.GetLabel(HealthcheckIntervalLabel))
+	timeout := strings.TrimSpace(c.GetLabel(HealthcheckTimeoutLabel))
+	startPeriod := strings.TrimSpace(c.GetLabel(HealthcheckStartPeriodLabel))
+	retries := strings.TrimSpace(c.GetLabel(HealthcheckRetriesLabel))
+	if cmd == "" && interval == "" && timeout == "" && startPeriod == "" && retries == "" {
+		return current, false, nil
+	}
+
+	hc := &container.HealthConfig{}
+	if current != nil {
+		*hc = *current
+	}
+
+	switch {
+	case strings.EqualFold(cmd, HealthcheckNone):
+		return &container.HealthConfig{Test: []string{HealthcheckNone}}, true, nil
+	case cmd != "":
+		hc.Test = []string{"CMD-SHELL", cmd}
+	}
+
+	for _, d := range []struct {
+		label string
+		value string
+		field *time.Duration
+	}{
+		{HealthcheckIntervalLabel, interval, &hc.Interval},
+		{HealthcheckTimeoutLabel, timeout, &hc.Timeout},
+		{HealthcheckStartPeriodLabel, startPeriod, &hc.StartPeriod},
+	} {
+		if d.value == "" {
+			continue
+		}
+		// Docker rejects durations below one millisecond, other than 0
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v < time.Millisecond {
+			return current, true, fmt.Errorf("invalid %s label %q, expected a duration of at least 1ms like 30s", d.label, d.value)
+		}
+		*d.field = v
+	}
+
+	if retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			return current, true, fmt.Errorf("invalid %s label %q, expected a non-negative number", HealthcheckRetriesLabel, retries)
+		}
+		hc.Retries = n
+	}
+	return hc, true, nil
+}
@@ -0,0 +1,47 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+// Image is a local image
+type Image struct {
+	ID      string    `json:"id"`
+	Tags    []string  `json:"tags"`
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+	// Dangling images have no tag left, usually superseded by a pull
+	Dangling bool `json:"dangling"`
+}
+
+// ListImages returns the local images, newest first
+func (c *dockerClient) ListImages(ctx context.Context) ([]Image, error) {
+	summaries, err := c.api.ImageList(ctx, image.ListOptions{All: false})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	images := make([]Image, 0, len(summaries))
+	for _, s := range summaries {
+		var tags []string
+		for _, tag := range s.RepoTags {
+			if tag != "<none>:<none>" {
+				tags = append(tags, tag)
+			}
+		}
+		images = append(images, Image{
+			ID:       s.ID,
+			Tags:     tags,
+			Size:     s.Size,
+			Created:  time.Unix(s.Created, 0),
+			Dangling: len(tags) == 0,
+		})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Created.After(images[j].Created) })
+	return images, nil
+}
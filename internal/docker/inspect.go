@@ -0,0 +1,185 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// ContainerDetails are inspect details not carried by Container
+type ContainerDetails struct {
+	Networks      []Network
+	RestartPolicy string
+	// Env often holds secrets and is never exposed through the API
+	Env []string `json:"-"`
+}
+
+// Port is a container port and, when published, its host binding
+type Port struct {
+	PrivatePort uint16
+	PublicPort  uint16 `json:",omitempty"`
+	IP          string `json:",omitempty"`
+	Type        string
+}
+
+// String renders the port like docker ps, e.g. "8080->80/tcp". The host
+// address is only included when the port is bound to a specific one.
+func (p Port) String() string {
+	if p.PublicPort == 0 {
+		return fmt.Sprintf("%d/%s", p.PrivatePort, p.Type)
+	}
+	if p.IP == "" || p.IP == "0.0.0.0" || p.IP == "::" {
+		return fmt.Sprintf("%d->%d/%s", p.PublicPort, p.PrivatePort, p.Type)
+	}
+	return fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type)
+}
+
+// Mount summarizes a volume, bind or tmpfs mount
+type Mount struct {
+	Type        string
+	Source      string
+	Destination string
+	ReadOnly    bool
+}
+
+// String renders the mount like a -v flag, e.g. "data:/var/lib/data:ro"
+func (m Mount) String() string {
+	s := m.Destination
+	if m.Source != "" {
+		s = m.Source + ":" + s
+	}
+	if m.ReadOnly {
+		s += ":ro"
+	}
+	return s
+}
+
+// Network is a network the container is attached to
+type Network struct {
+	Name      string
+	IPAddress string   `json:",omitempty"`
+	Aliases   []string `json:",omitempty"`
+}
+
+// InspectContainer returns a container together with its inspect details
+func (c *dockerClient) InspectContainer(ctx context.Context, id string) (Container, ContainerDetails, error) {
+	info, err := c.api.ContainerInspect(ctx, id)
+	if err != nil {
+		return Container{}, ContainerDetails{}, fmt.Errorf("failed to inspect container %s: %w", id, err)
+	}
+
+	return containerFromInspect(info), detailsFromInspect(info), nil
+}
+
+func detailsFromInspect(info types.ContainerJSON) ContainerDetails {
+	var details ContainerDetails
+
+	if info.HostConfig != nil {
+		details.RestartPolicy = restartPolicy(info.HostConfig.RestartPolicy)
+	}
+	if info.Config != nil {
+		details.Env = info.Config.Env
+	}
+
+	if info.NetworkSettings != nil {
+		for name, ep := range info.NetworkSettings.Networks {
+			network := Network{Name: name}
+			if ep != nil {
+				network.IPAddress = ep.IPAddress
+				network.Aliases = ep.Aliases
+			}
+			details.Networks = append(details.Networks, network)
+		}
+		sort.Slice(details.Networks, func(i, j int) bool { return details.Networks[i].Name < details.Networks[j].Name })
+	}
+
+	return details
+}
+
+// portsFromAPI converts the ports reported by the container list
+func portsFromAPI(ports []container.Port) []Port {
+	result := make([]Port, 0, len(ports))
+	for _, p := range ports {
+		result = append(result, Port{
+			PrivatePort: p.PrivatePort,
+			PublicPort:  p.PublicPort,
+			IP:          p.IP,
+			Type:        p.Type,
+		})
+	}
+	sortPorts(result)
+	return result
+}
+
+// portsFromInspect converts the port map of an inspected container
+func portsFromInspect(info types.ContainerJSON) []Port {
+	if info.NetworkSettings == nil {
+		return nil
+	}
+
+	var result []Port
+	for port, bindings := range info.NetworkSettings.Ports {
+		if len(bindings) == 0 {
+			result = append(result, Port{PrivatePort: uint16(port.Int()), Type: port.Proto()})
+			continue
+		}
+		for _, b := range bindings {
+			public, _ := strconv.ParseUint(b.HostPort, 10, 16)
+			result = append(result, Port{
+				PrivatePort: uint16(port.Int()),
+				PublicPort:  uint16(public),
+				IP:          b.HostIP,
+				Type:        port.Proto(),
+			})
+		}
+	}
+	sortPorts(result)
+	return result
+}
+
+func sortPorts(ports []Port) {
+	sort.Slice(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+		if a.PrivatePort != b.PrivatePort {
+			return a.PrivatePort < b.PrivatePort
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.IP < b.IP
+	})
+}
+
+// mountsFromAPI converts the mount points of a container. Named volumes
+// are identified by name rather than their path on the host.
+func mountsFromAPI(mounts []container.MountPoint) []Mount {
+	result := make([]Mount, 0, len(mounts))
+	for _, m := range mounts {
+		source := m.Source
+		if m.Name != "" {
+			source = m.Name
+		}
+		result = append(result, Mount{
+			Type:        string(m.Type),
+			Source:      source,
+			Destination: m.Destination,
+			ReadOnly:    !m.RW,
+		})
+	}
+	return result
+}
+
+// restartPolicy renders a restart policy the way docker run accepts it
+func restartPolicy(policy container.RestartPolicy) string {
+	if policy.Name == "" {
+		return string(container.RestartPolicyDisabled)
+	}
+	if policy.Name == container.RestartPolicyOnFailure && policy.MaximumRetryCount > 0 {
+		return fmt.Sprintf("%s:%d", policy.Name, policy.MaximumRetryCount)
+	}
+	return string(policy.Name)
+}
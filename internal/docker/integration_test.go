@@ -0,0 +1,499 @@
+//go:build integration
+
+// Integration tests against a real Docker daemon, run with
+//
+//	go test -tags integration ./internal/docker/
+//
+// The daemon is the one DOCKER_HOST points at, e.g. a docker:dind service in
+// CI. The tests pull busybox, build their own images and create containers,
+// networks and volumes named dockwarden-it-*, all of which are removed
+// again. Update cycles and the health watcher only manage containers with
+// the dockwarden.enable label, so other containers on the host are left
+// alone.
+package docker_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/build"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/emon5122/dockwarden/internal/clock"
+	"github.com/emon5122/dockwarden/internal/config"
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/health"
+	"github.com/emon5122/dockwarden/internal/updater"
+	"github.com/spf13/cobra"
+)
+
+// baseImage is the only image pulled from a registry; the test images are
+// built on top of it
+const baseImage = "busybox:1.36"
+
+// testTimeout bounds each test, including pulls and builds
+const testTimeout = 3 * time.Minute
+
+// daemon returns a raw API client for setting up and inspecting test
+// resources, and the DockWarden client under test
+func daemon(t *testing.T) (*dockerclient.Client, docker.Client) {
+	t.Helper()
+	api, err := dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation())
+	if err != nil {
+		t.Fatalf("failed to create Docker client: %v", err)
+	}
+	t.Cleanup(func() { api.Close() })
+
+	client, err := docker.NewClient(docker.ClientOptions{})
+	if err != nil {
+		t.Fatalf("failed to create DockWarden client: %v", err)
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatalf("Docker daemon is not reachable: %v", err)
+	}
+	return api, client
+}
+
+// testContext returns a context bounded by testTimeout
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// uniqueName returns a resource name that doesn't clash with earlier runs
+func uniqueName(kind string) string {
+	return fmt.Sprintf("dockwarden-it-%s-%d", kind, time.Now().UnixNano()%1_000_000_000)
+}
+
+// pullBase makes sure the base image is present
+func pullBase(t *testing.T, ctx context.Context, api *dockerclient.Client) {
+	t.Helper()
+	body, err := api.ImagePull(ctx, baseImage, image.PullOptions{})
+	if err != nil {
+		t.Fatalf("failed to pull %s: %v", baseImage, err)
+	}
+	defer body.Close()
+	if err := streamError(body); err != nil {
+		t.Fatalf("failed to pull %s: %v", baseImage, err)
+	}
+}
+
+// streamError drains a pull or build progress stream and returns the error
+// it reports, if any
+func streamError(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+	}
+}
+
+// buildImage builds an image from baseImage with the given files, tags it
+// as ref and returns its ID. Every build gets a unique file, so two builds
+// never share an ID.
+func buildImage(t *testing.T, ctx context.Context, api *dockerclient.Client, ref string, files ...string) string {
+	t.Helper()
+	dockerfile := "FROM " + baseImage + "\n"
+	for _, file := range append(files, "/build-"+strconv.FormatInt(time.Now().UnixNano(), 10)) {
+		dockerfile += "RUN touch " + file + "\n"
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "Dockerfile", Mode: 0o644, Size: int64(len(dockerfile))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(dockerfile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := api.ImageBuild(ctx, &buf, build.ImageBuildOptions{
+		Tags:        []string{ref},
+		Remove:      true,
+		ForceRemove: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to build %s: %v", ref, err)
+	}
+	defer resp.Body.Close()
+	if err := streamError(resp.Body); err != nil {
+		t.Fatalf("failed to build %s: %v", ref, err)
+	}
+
+	info, err := api.ImageInspect(ctx, ref)
+	if err != nil {
+		t.Fatalf("failed to inspect %s: %v", ref, err)
+	}
+	t.Cleanup(func() {
+		api.ImageRemove(context.Background(), info.ID, image.RemoveOptions{Force: true, PruneChildren: true})
+	})
+	return info.ID
+}
+
+// runContainer creates and starts a container, which is removed with the
+// test under its name, so containers recreated in between are removed too
+func runContainer(t *testing.T, ctx context.Context, api *dockerclient.Client, name string, cfg *container.Config, hostCfg *container.HostConfig, netCfg *network.NetworkingConfig) string {
+	t.Helper()
+	if hostCfg == nil {
+		hostCfg = &container.HostConfig{}
+	}
+	created, err := api.ContainerCreate(ctx, cfg, hostCfg, netCfg, nil, name)
+	if err != nil {
+		t.Fatalf("failed to create container %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		api.ContainerRemove(context.Background(), name, container.RemoveOptions{Force: true})
+	})
+	if err := api.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		t.Fatalf("failed to start container %s: %v", name, err)
+	}
+	return created.ID
+}
+
+// inspect inspects the container currently holding name
+func inspect(t *testing.T, ctx context.Context, api *dockerclient.Client, name string) container.InspectResponse {
+	t.Helper()
+	info, err := api.ContainerInspect(ctx, name)
+	if err != nil {
+		t.Fatalf("failed to inspect container %s: %v", name, err)
+	}
+	return info
+}
+
+// freePort returns a TCP port that is free on this host
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+// loadConfig loads the configuration with its defaults, the state kept in a
+// temporary directory and only labelled containers managed, plus flags
+func loadConfig(t *testing.T, flags map[string]string) *config.Config {
+	t.Helper()
+	cmd := &cobra.Command{Use: "dockwarden"}
+	config.RegisterFlags(cmd)
+	defaults := map[string]string{
+		"data-dir":     t.TempDir(),
+		"label-enable": "true",
+		"stop-timeout": "1s",
+	}
+	for name, value := range defaults {
+		if _, ok := flags[name]; !ok {
+			flags[name] = value
+		}
+	}
+	for name, value := range flags {
+		if err := cmd.PersistentFlags().Set(name, value); err != nil {
+			t.Fatalf("failed to set %s: %v", name, err)
+		}
+	}
+	cfg, err := config.Load(cmd)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg
+}
+
+// managedLabels are the labels of containers the update cycles and health
+// watcher of the tests may touch
+func managedLabels() map[string]string {
+	return map[string]string{"dockwarden.enable": "true"}
+}
+
+// sleeper runs the image until it is stopped, as long as /ok exists in it
+func sleeper(ref string) *container.Config {
+	return &container.Config{
+		Image:  ref,
+		Cmd:    []string{"sh", "-c", "test -f /ok && exec sleep 3600"},
+		Labels: managedLabels(),
+	}
+}
+
+// runCycle runs an update cycle limited to the container named name
+func runCycle(t *testing.T, u *updater.Updater, name string) updater.CycleSummary {
+	t.Helper()
+	err := u.RunMatching(func(ctr docker.Container) bool { return ctr.Name == name })
+	if err != nil {
+		t.Fatalf("update cycle failed: %v", err)
+	}
+	return u.LastCycle()
+}
+
+func TestIntegrationPullImage(t *testing.T) {
+	_, client := daemon(t)
+	ctx := testContext(t)
+
+	if _, err := client.PullImage(ctx, baseImage); err != nil {
+		t.Fatalf("PullImage() error = %v", err)
+	}
+
+	digest, err := client.GetImageDigest(ctx, baseImage)
+	if err != nil {
+		t.Fatalf("GetImageDigest() error = %v", err)
+	}
+	if !strings.Contains(digest, "sha256:") {
+		t.Errorf("GetImageDigest() = %q, want a repository digest", digest)
+	}
+
+	local, err := client.IsLocalImage(ctx, baseImage)
+	if err != nil {
+		t.Fatalf("IsLocalImage() error = %v", err)
+	}
+	if local {
+		t.Errorf("IsLocalImage(%s) = true, want false for a pulled image", baseImage)
+	}
+}
+
+func TestIntegrationRecreatePreservesConfig(t *testing.T) {
+	api, client := daemon(t)
+	ctx := testContext(t)
+	pullBase(t, ctx, api)
+
+	// Two networks, the first with an alias, a named volume, a tmpfs mount,
+	// a published port, environment variables and labels
+	var networks []string
+	for range 2 {
+		name := uniqueName("net")
+		if _, err := api.NetworkCreate(ctx, name, network.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create network: %v", err)
+		}
+		t.Cleanup(func() { api.NetworkRemove(context.Background(), name) })
+		networks = append(networks, name)
+	}
+	vol, err := api.VolumeCreate(ctx, volume.CreateOptions{Name: uniqueName("vol")})
+	if err != nil {
+		t.Fatalf("failed to create volume: %v", err)
+	}
+	t.Cleanup(func() { api.VolumeRemove(context.Background(), vol.Name, true) })
+
+	name := uniqueName("recreate")
+	port := freePort(t)
+	labels := managedLabels()
+	labels["com.example.keep"] = "1"
+	oldID := runContainer(t, ctx, api, name,
+		&container.Config{
+			Image:        baseImage,
+			Cmd:          []string{"sleep", "3600"},
+			Env:          []string{"DOCKWARDEN_IT=1", "SECRET=kept"},
+			ExposedPorts: nat.PortSet{"80/tcp": {}},
+			Labels:       labels,
+		},
+		&container.HostConfig{
+			NetworkMode:  container.NetworkMode(networks[0]),
+			PortBindings: nat.PortMap{"80/tcp": {{HostIP: "127.0.0.1", HostPort: port}}},
+			Mounts: []mount.Mount{
+				{Type: mount.TypeVolume, Source: vol.Name, Target: "/data"},
+				{Type: mount.TypeTmpfs, Target: "/scratch"},
+			},
+		},
+		&network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{
+			networks[0]: {Aliases: []string{"it-alias"}},
+		}},
+	)
+	if err := api.NetworkConnect(ctx, networks[1], oldID, &network.EndpointSettings{}); err != nil {
+		t.Fatalf("failed to connect second network: %v", err)
+	}
+
+	newID, err := client.RecreateContainer(ctx, oldID, docker.RecreateOptions{StopTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("RecreateContainer() error = %v", err)
+	}
+	if newID == oldID {
+		t.Fatalf("RecreateContainer() returned the old ID")
+	}
+	if _, err := api.ContainerInspect(ctx, oldID); !dockerclient.IsErrNotFound(err) {
+		t.Errorf("old container still exists (err = %v)", err)
+	}
+
+	info := inspect(t, ctx, api, name)
+	if info.ID != newID {
+		t.Errorf("container %s has ID %s, want %s", name, info.ID, newID)
+	}
+	if !info.State.Running {
+		t.Errorf("recreated container is %s, want running", info.State.Status)
+	}
+
+	for _, env := range []string{"DOCKWARDEN_IT=1", "SECRET=kept"} {
+		if !slices.Contains(info.Config.Env, env) {
+			t.Errorf("env %q lost, got %v", env, info.Config.Env)
+		}
+	}
+
+	bindings := info.HostConfig.PortBindings["80/tcp"]
+	if len(bindings) != 1 || bindings[0].HostIP != "127.0.0.1" || bindings[0].HostPort != port {
+		t.Errorf("port bindings = %v, want 127.0.0.1:%s->80/tcp", bindings, port)
+	}
+	if published := info.NetworkSettings.Ports["80/tcp"]; len(published) == 0 || published[0].HostPort != port {
+		t.Errorf("published ports = %v, want host port %s", published, port)
+	}
+
+	var volumeMounted bool
+	for _, m := range info.Mounts {
+		if m.Type == mount.TypeVolume && m.Name == vol.Name && m.Destination == "/data" {
+			volumeMounted = true
+		}
+	}
+	if !volumeMounted {
+		t.Errorf("volume %s not mounted at /data, mounts = %+v", vol.Name, info.Mounts)
+	}
+	if !slices.ContainsFunc(info.HostConfig.Mounts, func(m mount.Mount) bool {
+		return m.Type == mount.TypeTmpfs && m.Target == "/scratch"
+	}) {
+		t.Errorf("tmpfs mount at /scratch lost, mounts = %+v", info.HostConfig.Mounts)
+	}
+
+	for _, nw := range networks {
+		if _, ok := info.NetworkSettings.Networks[nw]; !ok {
+			t.Errorf("network %s lost, got %v", nw, info.NetworkSettings.Networks)
+		}
+	}
+	if endpoint := info.NetworkSettings.Networks[networks[0]]; endpoint == nil || !slices.Contains(endpoint.Aliases, "it-alias") {
+		t.Errorf("alias it-alias lost on network %s", networks[0])
+	}
+
+	if info.Config.Labels["com.example.keep"] != "1" {
+		t.Errorf("label com.example.keep lost, got %v", info.Config.Labels)
+	}
+	if info.Config.Labels[docker.ManagedLabel] != "true" {
+		t.Errorf("label %s = %q, want true", docker.ManagedLabel, info.Config.Labels[docker.ManagedLabel])
+	}
+}
+
+func TestIntegrationUpdate(t *testing.T) {
+	api, client := daemon(t)
+	ctx := testContext(t)
+	pullBase(t, ctx, api)
+
+	ref := uniqueName("image") + ":latest"
+	oldImage := buildImage(t, ctx, api, ref, "/ok")
+	name := uniqueName("update")
+	runContainer(t, ctx, api, name, sleeper(ref), nil, nil)
+
+	// Offline, the update comes from the image the tag points at locally
+	u := updater.New(client, loadConfig(t, map[string]string{"offline": "true"}))
+	if summary := runCycle(t, u, name); summary.Updated != 0 {
+		t.Fatalf("cycle without a new image updated %d containers", summary.Updated)
+	}
+
+	newImage := buildImage(t, ctx, api, ref, "/ok")
+	summary := runCycle(t, u, name)
+	if summary.Updated != 1 || summary.Failed != 0 {
+		t.Fatalf("cycle summary = %+v, want 1 updated", summary)
+	}
+
+	info := inspect(t, ctx, api, name)
+	if info.Image != newImage {
+		t.Errorf("container runs image %s, want the new image %s (old %s)", info.Image, newImage, oldImage)
+	}
+	if !info.State.Running {
+		t.Errorf("updated container is %s, want running", info.State.Status)
+	}
+}
+
+func TestIntegrationRollback(t *testing.T) {
+	api, client := daemon(t)
+	ctx := testContext(t)
+	pullBase(t, ctx, api)
+
+	// The new image lacks /ok, so the updated container exits right away
+	ref := uniqueName("image") + ":latest"
+	goodImage := buildImage(t, ctx, api, ref, "/ok")
+	name := uniqueName("rollback")
+	runContainer(t, ctx, api, name, sleeper(ref), nil, nil)
+	buildImage(t, ctx, api, ref)
+
+	u := updater.New(client, loadConfig(t, map[string]string{
+		"offline":          "true",
+		"cleanup":          "false",
+		"rollback-timeout": "20s",
+	}))
+	summary := runCycle(t, u, name)
+	if summary.Updated != 0 || summary.Failed != 1 {
+		t.Fatalf("cycle summary = %+v, want 1 failed", summary)
+	}
+
+	info := inspect(t, ctx, api, name)
+	if info.Image != goodImage {
+		t.Errorf("container runs image %s, want the previous image %s", info.Image, goodImage)
+	}
+	if !info.State.Running {
+		t.Errorf("rolled back container is %s, want running", info.State.Status)
+	}
+}
+
+func TestIntegrationHealthRestart(t *testing.T) {
+	api, client := daemon(t)
+	ctx := testContext(t)
+	pullBase(t, ctx, api)
+
+	// The healthcheck fails as soon as it runs
+	name := uniqueName("health")
+	runContainer(t, ctx, api, name, &container.Config{
+		Image:  baseImage,
+		Cmd:    []string{"sleep", "3600"},
+		Labels: managedLabels(),
+		Healthcheck: &container.HealthConfig{
+			Test:     []string{"CMD-SHELL", "test -f /healthy"},
+			Interval: time.Second,
+			Timeout:  time.Second,
+			Retries:  1,
+		},
+	}, nil, nil)
+	started := inspect(t, ctx, api, name).State.StartedAt
+
+	w := health.NewWatcher(client, loadConfig(t, map[string]string{
+		"health-action":   "restart",
+		"health-interval": "1s",
+	}), clock.Real)
+	go w.Start()
+	defer w.Stop()
+
+	deadline := time.Now().Add(time.Minute)
+	for time.Now().Before(deadline) {
+		info := inspect(t, ctx, api, name)
+		if info.State.StartedAt != started {
+			if !info.State.Running {
+				t.Errorf("restarted container is %s, want running", info.State.Status)
+			}
+			return
+		}
+		time.Sleep(time.Second)
+	}
+	t.Fatalf("unhealthy container %s wasn't restarted within a minute", name)
+}
@@ -0,0 +1,179 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// Post-job labels describe a one-shot container run after an update
+const (
+	PostJobImageLabel   = "dockwarden.update.post-job.image"
+	PostJobCommandLabel = "dockwarden.update.post-job.command"
+	PostJobTimeoutLabel = "dockwarden.update.post-job.timeout"
+)
+
+// DefaultJobTimeout bounds how long a post-update job may run
+const DefaultJobTimeout = 10 * time.Minute
+
+// jobLogLines is how much job output is kept for error messages
+const jobLogLines = 20
+
+// Job is a one-shot container run next to an updated container, e.g. to
+// apply database migrations
+type Job struct {
+	// Image defaults to the image of the container the job belongs to
+	Image string
+	// Command is run with /bin/sh -c; empty runs the image's default command
+	Command string
+	Timeout time.Duration
+}
+
+// JobResult is the outcome of a job container
+type JobResult struct {
+	ExitCode int64
+	// Output holds the last lines of the job's output
+	Output string
+}
+
+// PostJob returns the job to run after the container was updated, or nil
+// when none is configured
+func (c Container) PostJob() *Job {
+	image := strings.TrimSpace(c.GetLabel(PostJobImageLabel))
+	command := strings.TrimSpace(c.GetLabel(PostJobCommandLabel))
+	if image == "" && command == "" {
+		return nil
+	}
+
+	job := &Job{Image: image, Command: command, Timeout: DefaultJobTimeout}
+	if timeout, err := time.ParseDuration(c.GetLabel(PostJobTimeoutLabel)); err == nil && timeout > 0 {
+		job.Timeout = timeout
+	}
+	return job
+}
+
+// RunJob runs a job container to completion with the environment and
+// networks of the container id and returns its exit code. The job
+// container is removed afterwards.
+func (c *dockerClient) RunJob(ctx context.Context, id string, job Job) (JobResult, error) {
+	parent, err := c.api.ContainerInspect(ctx, id)
+	if err != nil {
+		return JobResult{}, fmt.Errorf("failed to inspect container %s: %w", id, err)
+	}
+
+	parentName := strings.TrimPrefix(parent.Name, "/")
+	logger := logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldContainer: parentName,
+		logging.FieldAction:    "job",
+	})
+
+	image := job.Image
+	if image == "" {
+		image = parent.Config.Image
+	}
+	config := &container.Config{
+		Image:  image,
+		Env:    parent.Config.Env,
+		Labels: map[string]string{ManagedLabel: "true", "dockwarden.job.parent": parentName},
+	}
+	if job.Command != "" {
+		config.Entrypoint = []string{"/bin/sh", "-c"}
+		config.Cmd = []string{job.Command}
+	}
+
+	hostConfig := &container.HostConfig{NetworkMode: parent.HostConfig.NetworkMode}
+	networkingConfig := &network.NetworkingConfig{EndpointsConfig: make(map[string]*network.EndpointSettings)}
+	var extraNetworks []string
+	if parent.NetworkSettings != nil {
+		// The network of the network mode is joined on create, the others after
+		mode := string(hostConfig.NetworkMode)
+		if mode == "default" {
+			mode = "bridge"
+		}
+		for netName, settings := range parent.NetworkSettings.Networks {
+			if settings == nil {
+				continue
+			}
+			if netName == mode {
+				networkingConfig.EndpointsConfig[netName] = &network.EndpointSettings{NetworkID: settings.NetworkID}
+			} else {
+				extraNetworks = append(extraNetworks, settings.NetworkID)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+
+	created, err := c.api.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, "")
+	if err != nil {
+		return JobResult{}, fmt.Errorf("failed to create job container: %w", err)
+	}
+	jobID := created.ID
+	defer func() {
+		// The job context may have expired already
+		if err := c.api.ContainerRemove(context.Background(), jobID, container.RemoveOptions{Force: true}); err != nil {
+			logger.WithError(err).Debug("Failed to remove job container")
+		}
+	}()
+
+	for _, networkID := range extraNetworks {
+		if err := c.api.NetworkConnect(ctx, networkID, jobID, nil); err != nil {
+			logger.WithError(err).Warn("Failed to connect job container to network")
+		}
+	}
+
+	waitCh, errCh := c.api.ContainerWait(ctx, jobID, container.WaitConditionNextExit)
+	if err := c.api.ContainerStart(ctx, jobID, container.StartOptions{}); err != nil {
+		return JobResult{}, fmt.Errorf("failed to start job container: %w", err)
+	}
+	logger.WithFields(log.Fields{
+		logging.FieldImage: image,
+		"job_id":           shortID(jobID),
+	}).Info("Running post-update job")
+
+	var result JobResult
+	select {
+	case resp := <-waitCh:
+		if resp.Error != nil {
+			return JobResult{}, fmt.Errorf("job container failed: %s", resp.Error.Message)
+		}
+		result.ExitCode = resp.StatusCode
+	case err := <-errCh:
+		return JobResult{}, fmt.Errorf("failed to wait for job container: %w", err)
+	}
+
+	result.Output = c.jobOutput(jobID)
+	logger.WithField("exit_code", result.ExitCode).Debug("Post-update job finished")
+	return result, nil
+}
+
+// jobOutput returns the last lines of a job container's output
+func (c *dockerClient) jobOutput(id string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reader, err := c.api.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       fmt.Sprint(jobLogLines),
+	})
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+
+	var out bytes.Buffer
+	if _, err := stdcopy.StdCopy(&out, &out, reader); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out.String())
+}
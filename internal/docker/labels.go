@@ -0,0 +1,162 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ManagedLabel is stamped on every container DockWarden recreates, so it is
+// possible to tell which containers have been through an update
+const ManagedLabel = "dockwarden.managed"
+
+// IdentityLabel carries a container's stable identity. It is stamped on
+// recreate, so the history, health tracking and metrics of a container
+// continue across updates even though its ID changes, and survive renames.
+const IdentityLabel = "dockwarden.id"
+
+// DefaultProtectedLabels are label patterns that are always carried over to a
+// recreated container exactly as they were, even when they match a label of
+// the old image. A trailing ".*" matches any key with that prefix.
+var DefaultProtectedLabels = []string{
+	"traefik.*",
+	"com.docker.compose.*",
+	"com.docker.stack.*",
+	"com.docker.swarm.*",
+}
+
+// isProtectedLabel reports whether key matches one of the protected patterns
+func isProtectedLabel(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+			continue
+		}
+		if key == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// recreateLabels computes the label set for a recreated container.
+//
+// Docker merges image labels into the container's Config.Labels, so copying
+// them verbatim would pin the old image's metadata (for example
+// org.opencontainers.image.version) onto the new container. Labels whose key
+// and value are identical to the old image's are therefore dropped and left
+// for the new image to provide, unless they are protected. Everything set on
+// the container itself is preserved, and the DockWarden bookkeeping labels
+// are added: the managed marker and, unless already set, the identity.
+func recreateLabels(containerLabels, oldImageLabels map[string]string, protected []string, identity string) map[string]string {
+	labels := make(map[string]string, len(containerLabels)+2)
+	for key, value := range containerLabels {
+		if imageValue, ok := oldImageLabels[key]; ok && imageValue == value && !isProtectedLabel(key, protected) {
+			continue
+		}
+		labels[key] = value
+	}
+	labels[ManagedLabel] = "true"
+	if labels[IdentityLabel] == "" && identity != "" {
+		labels[IdentityLabel] = identity
+	}
+	return labels
+}
+
+// ErrPrivilegedLabel is returned for edits of labels carrying a command,
+// an image or an environment variable by callers that may not edit them
+var ErrPrivilegedLabel = errors.New("editing it requires an unrestricted API token")
+
+// LabelPrefix is the prefix of the labels DockWarden reads from containers
+const LabelPrefix = "dockwarden."
+
+// editableLabels are the labels that can be edited on a running container.
+// They only change how DockWarden treats the container.
+var editableLabels = map[string]bool{
+	"dockwarden.enable":          true,
+	"dockwarden.update.enable":   true,
+	"dockwarden.watch.enable":    true,
+	"dockwarden.scope":           true,
+	"dockwarden.cleanup":         true,
+	"dockwarden.rollback.enable": true,
+	"dockwarden.stop-signal":     true,
+	"dockwarden.stop-timeout":    true,
+	"dockwarden.depends-on":      true,
+	"dockwarden.restart-with":    true,
+	GroupLabel:                   true,
+	OwnerLabel:                   true,
+	NotesLabel:                   true,
+	TagsIncludeLabel:             true,
+	TagsExcludeLabel:             true,
+	TargetTagLabel:               true,
+	TCPProbeLabel:                true,
+	HealthcheckIntervalLabel:     true,
+	HealthcheckTimeoutLabel:      true,
+	HealthcheckStartPeriodLabel:  true,
+	HealthcheckRetriesLabel:      true,
+	PostJobTimeoutLabel:          true,
+}
+
+// privilegedLabels carry a command or an image DockWarden runs, so editing
+// them is as powerful as running containers on the host
+var privilegedLabels = map[string]bool{
+	HealthExecLabel:     true,
+	HealthcheckCmdLabel: true,
+	PostJobImageLabel:   true,
+	PostJobCommandLabel: true,
+}
+
+// IsPrivilegedLabel reports whether key carries a command, an image or an
+// environment variable of the container, which only fully trusted callers
+// may edit
+func IsPrivilegedLabel(key string) bool {
+	return privilegedLabels[key] || (strings.HasPrefix(key, EnvOverrideLabelPrefix) && key != EnvOverrideLabelPrefix)
+}
+
+// ValidateLabelEdits checks that label edits only touch the labels that can
+// be edited: the editable dockwarden.* labels and, when privileged is set,
+// the privileged ones. The bookkeeping labels stamped on recreate and labels
+// marking DockWarden's own containers are never edited.
+func ValidateLabelEdits(edits map[string]string, privileged bool) error {
+	if len(edits) == 0 {
+		return fmt.Errorf("no labels to edit")
+	}
+	for key := range edits {
+		switch {
+		case !strings.HasPrefix(key, LabelPrefix) || key == LabelPrefix:
+			return fmt.Errorf("label %q can't be edited: only %s* labels can", key, LabelPrefix)
+		case key == ManagedLabel || key == IdentityLabel:
+			return fmt.Errorf("label %q is maintained by DockWarden and can't be edited", key)
+		case IsPrivilegedLabel(key):
+			if !privileged {
+				return fmt.Errorf("label %q sets a command, an image or environment variables: %w", key, ErrPrivilegedLabel)
+			}
+		case !editableLabels[key]:
+			return fmt.Errorf("label %q can't be edited", key)
+		}
+	}
+	return nil
+}
+
+// EditLabels returns a copy of labels with the edits applied: an edit with a
+// value sets the label, an empty one removes it
+func EditLabels(labels, edits map[string]string) map[string]string {
+	edited := make(map[string]string, len(labels)+len(edits))
+	for key, value := range labels {
+		edited[key] = value
+	}
+	for key, value := range edits {
+		if value == "" {
+			delete(edited, key)
+			continue
+		}
+		edited[key] = value
+	}
+	return edited
+}
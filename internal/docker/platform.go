@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Platform describes an OS/architecture pair in Go/OCI notation
+// (e.g. linux/arm64, linux/arm/v7)
+type Platform struct {
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	Variant string `json:"variant,omitempty"`
+}
+
+// String returns the platform as os/arch[/variant]
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Arch
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// NormalizeArch converts kernel architecture names as reported by the Docker
+// daemon (uname -m) into OCI architecture and variant names
+func NormalizeArch(arch string) (string, string) {
+	switch strings.ToLower(arch) {
+	case "x86_64", "x86-64", "amd64":
+		return "amd64", ""
+	case "aarch64", "arm64", "armv8", "armv8l":
+		return "arm64", ""
+	case "armv7l", "armv7", "armhf", "arm":
+		return "arm", "v7"
+	case "armv6l", "armv6", "armel":
+		return "arm", "v6"
+	case "i386", "i686", "386":
+		return "386", ""
+	default:
+		return strings.ToLower(arch), ""
+	}
+}
+
+// HostPlatform returns the platform of the Docker daemon's host
+func HostPlatform(ctx context.Context, c Client) (Platform, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return Platform{}, err
+	}
+	arch, variant := NormalizeArch(info.Architecture)
+	return Platform{OS: info.OSType, Arch: arch, Variant: variant}, nil
+}
+
+// BinaryPlatform returns the platform DockWarden itself was compiled for
+func BinaryPlatform() Platform {
+	p := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	if p.Arch == "arm" {
+		p.Variant = "v7"
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "GOARM" && setting.Value != "" {
+					// GOARM may carry a float ABI suffix, e.g. "7,softfloat"
+					p.Variant = "v" + strings.SplitN(setting.Value, ",", 2)[0]
+				}
+			}
+		}
+	}
+	return p
+}
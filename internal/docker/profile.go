@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// PlatformProfile adapts container recreation to the container manager of
+// a NAS platform, so it still recognizes containers DockWarden recreated.
+type PlatformProfile struct {
+	Name string
+	// ProtectedLabels are preserved on recreate in addition to the
+	// configured protected labels
+	ProtectedLabels []string
+	// Preserve, when set, adjusts the config of the recreated container,
+	// which starts out as the old container's config with the new labels
+	Preserve func(cfg *container.Config, hostCfg *container.HostConfig)
+}
+
+// GenericProfile makes no platform-specific adjustments
+var GenericProfile = PlatformProfile{Name: "generic"}
+
+// platformProfiles are the built-in profiles by name
+var platformProfiles = map[string]PlatformProfile{
+	"generic": GenericProfile,
+	// Unraid's Docker manager keeps the icon, WebUI link and shell of a
+	// container in net.unraid.docker.* labels
+	"unraid": {
+		Name:            "unraid",
+		ProtectedLabels: []string{"net.unraid.docker.*"},
+	},
+	// Synology Container Manager keeps its metadata in com.synology.* labels
+	"synology": {
+		Name:            "synology",
+		ProtectedLabels: []string{"com.synology.*"},
+	},
+}
+
+// LookupPlatformProfile returns the built-in profile with the given name
+func LookupPlatformProfile(name string) (PlatformProfile, error) {
+	if name == "" {
+		return GenericProfile, nil
+	}
+	profile, ok := platformProfiles[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(platformProfiles))
+		for n := range platformProfiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return PlatformProfile{}, fmt.Errorf("unknown platform profile %q (want one of %s)", name, strings.Join(names, ", "))
+	}
+	return profile, nil
+}
+
+// protectedLabels combines the configured patterns with the profile's
+func (p PlatformProfile) protectedLabels(configured []string) []string {
+	if len(p.ProtectedLabels) == 0 {
+		return configured
+	}
+	return append(slices.Clone(configured), p.ProtectedLabels...)
+}
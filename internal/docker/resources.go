@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// AnonymousVolumeLabel marks volumes created for a container without a
+// name; removing the container with volumes (--remove-volumes) deletes them
+const AnonymousVolumeLabel = "com.docker.volume.anonymous"
+
+// NetworkInfo is a docker network
+type NetworkInfo struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Driver   string    `json:"driver"`
+	Scope    string    `json:"scope"`
+	Internal bool      `json:"internal"`
+	Created  time.Time `json:"created"`
+}
+
+// Volume is a docker volume
+type Volume struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Mountpoint string            `json:"mountpoint"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	// Created is zero if the volume driver doesn't report it
+	Created time.Time `json:"created"`
+	// Anonymous volumes have a generated name and are removed together with
+	// their container when removing volumes
+	Anonymous bool `json:"anonymous"`
+}
+
+// ListNetworks returns the networks, sorted by name
+func (c *dockerClient) ListNetworks(ctx context.Context) ([]NetworkInfo, error) {
+	summaries, err := c.api.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	networks := make([]NetworkInfo, 0, len(summaries))
+	for _, s := range summaries {
+		networks = append(networks, NetworkInfo{
+			ID:       s.ID,
+			Name:     s.Name,
+			Driver:   s.Driver,
+			Scope:    s.Scope,
+			Internal: s.Internal,
+			Created:  s.Created,
+		})
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+	return networks, nil
+}
+
+// ListVolumes returns the volumes, sorted by name
+func (c *dockerClient) ListVolumes(ctx context.Context) ([]Volume, error) {
+	resp, err := c.api.VolumeList(ctx, volume.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	volumes := make([]Volume, 0, len(resp.Volumes))
+	for _, v := range resp.Volumes {
+		if v == nil {
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, v.CreatedAt)
+		_, anonymous := v.Labels[AnonymousVolumeLabel]
+		volumes = append(volumes, Volume{
+			Name:       v.Name,
+			Driver:     v.Driver,
+			Mountpoint: v.Mountpoint,
+			Labels:     v.Labels,
+			Created:    created,
+			Anonymous:  anonymous,
+		})
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	return volumes, nil
+}
+
+// networkNames returns the names of the networks a container is attached
+// to, sorted
+func networkNames(networks map[string]*network.EndpointSettings) []string {
+	if len(networks) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
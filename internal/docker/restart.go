@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	log "github.com/sirupsen/logrus"
+)
+
+// restartsAutomatically reports whether the daemon brings a container with
+// policy back on its own
+func restartsAutomatically(policy container.RestartPolicy) bool {
+	return policy.Name != "" && policy.Name != container.RestartPolicyDisabled
+}
+
+// setRestartPolicy changes the restart policy of an existing container
+func (c *dockerClient) setRestartPolicy(ctx context.Context, id string, policy container.RestartPolicy) error {
+	if _, err := c.api.ContainerUpdate(ctx, id, container.UpdateConfig{RestartPolicy: policy}); err != nil {
+		return fmt.Errorf("failed to set restart policy of container %s: %w", id, err)
+	}
+	return nil
+}
+
+// pauseRestartPolicy disables the restart policy of a container about to be
+// stopped for a recreate, so the daemon can't bring it back while it is
+// replaced. It reports whether the policy was paused.
+func (c *dockerClient) pauseRestartPolicy(ctx context.Context, logger *log.Entry, id string, policy container.RestartPolicy) bool {
+	if !c.opts.PauseRestartPolicy || !restartsAutomatically(policy) {
+		return false
+	}
+	if err := c.setRestartPolicy(ctx, id, container.RestartPolicy{Name: container.RestartPolicyDisabled}); err != nil {
+		logger.WithError(err).Warn("Failed to pause restart policy, stopping anyway")
+		return false
+	}
+	logger.WithField("restart_policy", restartPolicy(policy)).Debug("Paused restart policy")
+	return true
+}
+
+// restoreRestartPolicy puts a paused policy back on the old container when
+// the recreate fails before it was removed
+func (c *dockerClient) restoreRestartPolicy(ctx context.Context, logger *log.Entry, id string, policy container.RestartPolicy) {
+	if err := c.setRestartPolicy(ctx, id, policy); err != nil {
+		logger.WithError(err).Error("Failed to restore restart policy of old container")
+	}
+}
+
+// verifyRestartPolicy makes sure the new container got the restart policy
+// of the one it replaces, repairing it when the daemon dropped it
+func (c *dockerClient) verifyRestartPolicy(ctx context.Context, logger *log.Entry, id string, want container.RestartPolicy) {
+	inspect, err := c.api.ContainerInspect(ctx, id)
+	if err != nil || inspect.HostConfig == nil {
+		logger.WithError(err).Debug("Failed to inspect new container to verify its restart policy")
+		return
+	}
+	if restartPolicy(inspect.HostConfig.RestartPolicy) == restartPolicy(want) {
+		return
+	}
+
+	clog := logger.WithFields(log.Fields{
+		"restart_policy": restartPolicy(inspect.HostConfig.RestartPolicy),
+		"expected":       restartPolicy(want),
+	})
+	if err := c.setRestartPolicy(ctx, id, want); err != nil {
+		clog.WithError(err).Error("New container lost its restart policy")
+		return
+	}
+	clog.Warn("Restored restart policy of new container")
+}
@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// SocketProxyLabel marks the container serving DockWarden's Docker API
+// (true), or opts a container detected as such out of the protection
+// (false)
+const SocketProxyLabel = "dockwarden.socket-proxy"
+
+// composeServiceLabel names the compose service of a container, which is
+// also its host name on the compose network
+const composeServiceLabel = "com.docker.compose.service"
+
+// DaemonHost returns the address DockWarden reaches the Docker API at,
+// e.g. unix:///var/run/docker.sock or tcp://socket-proxy:2375
+func (c *dockerClient) DaemonHost() string {
+	return c.api.DaemonHost()
+}
+
+// daemonHostName returns the host name or address of a TCP daemon host, or
+// "" for sockets and named pipes, which no container provides
+func daemonHostName(daemonHost string) string {
+	u, err := url.Parse(daemonHost)
+	if err != nil {
+		return ""
+	}
+	switch u.Scheme {
+	case "tcp", "http", "https":
+	default:
+		return ""
+	}
+	host := u.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// ProvidesDaemon reports whether the container serves the Docker API at
+// daemonHost, typically a socket proxy referenced by DOCKER_HOST.
+// Restarting or recreating it would cut DockWarden off from Docker midway,
+// so it is left alone. The container is recognized by its name, compose
+// service, ID or address, or by SocketProxyLabel.
+func (c Container) ProvidesDaemon(daemonHost string) bool {
+	switch c.GetLabel(SocketProxyLabel) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	host := daemonHostName(daemonHost)
+	if host == "" {
+		return false
+	}
+	if strings.EqualFold(c.Name, host) || strings.EqualFold(c.GetLabel(composeServiceLabel), host) {
+		return true
+	}
+	if len(host) >= 12 && strings.HasPrefix(c.ID, host) {
+		return true
+	}
+	for _, addr := range c.IPAddresses {
+		if addr == host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+)
+
+// SystemInfo is a summary of the Docker daemon the client is connected to
+type SystemInfo struct {
+	Name              string   `json:"name"`
+	ServerVersion     string   `json:"server_version"`
+	APIVersion        string   `json:"api_version"`
+	OperatingSystem   string   `json:"operating_system"`
+	OSType            string   `json:"os_type"`
+	Architecture      string   `json:"architecture"`
+	KernelVersion     string   `json:"kernel_version"`
+	StorageDriver     string   `json:"storage_driver"`
+	LoggingDriver     string   `json:"logging_driver"`
+	CgroupDriver      string   `json:"cgroup_driver"`
+	DockerRootDir     string   `json:"docker_root_dir"`
+	NCPU              int      `json:"ncpu"`
+	MemTotal          int64    `json:"mem_total"`
+	Containers        int      `json:"containers"`
+	ContainersRunning int      `json:"containers_running"`
+	ContainersStopped int      `json:"containers_stopped"`
+	Images            int      `json:"images"`
+	SystemTime        string   `json:"system_time"`
+	Warnings          []string `json:"warnings,omitempty"`
+}
+
+// DiskUsage summarizes the disk space used by Docker objects
+type DiskUsage struct {
+	LayersSize int64 `json:"layers_size"`
+
+	Images            int   `json:"images"`
+	ImagesSize        int64 `json:"images_size"`
+	ImagesReclaimable int64 `json:"images_reclaimable"`
+
+	Containers     int   `json:"containers"`
+	ContainersSize int64 `json:"containers_size"`
+
+	Volumes            int   `json:"volumes"`
+	VolumesSize        int64 `json:"volumes_size"`
+	VolumesReclaimable int64 `json:"volumes_reclaimable"`
+
+	BuildCache            int   `json:"build_cache"`
+	BuildCacheSize        int64 `json:"build_cache_size"`
+	BuildCacheReclaimable int64 `json:"build_cache_reclaimable"`
+}
+
+// Total returns the combined size of all Docker objects
+func (d DiskUsage) Total() int64 {
+	return d.LayersSize + d.ContainersSize + d.VolumesSize + d.BuildCacheSize
+}
+
+// Info returns information about the Docker daemon
+func (c *dockerClient) Info(ctx context.Context) (SystemInfo, error) {
+	info, err := c.api.Info(ctx)
+	if err != nil {
+		return SystemInfo{}, fmt.Errorf("failed to get Docker info: %w", err)
+	}
+
+	return SystemInfo{
+		Name:              info.Name,
+		ServerVersion:     info.ServerVersion,
+		APIVersion:        c.api.ClientVersion(),
+		OperatingSystem:   info.OperatingSystem,
+		OSType:            info.OSType,
+		Architecture:      info.Architecture,
+		KernelVersion:     info.KernelVersion,
+		StorageDriver:     info.Driver,
+		LoggingDriver:     info.LoggingDriver,
+		CgroupDriver:      info.CgroupDriver,
+		DockerRootDir:     info.DockerRootDir,
+		NCPU:              info.NCPU,
+		MemTotal:          info.MemTotal,
+		Containers:        info.Containers,
+		ContainersRunning: info.ContainersRunning,
+		ContainersStopped: info.ContainersStopped,
+		Images:            info.Images,
+		SystemTime:        info.SystemTime,
+		Warnings:          info.Warnings,
+	}, nil
+}
+
+// DiskUsage returns disk usage of images, containers, volumes and build cache
+func (c *dockerClient) DiskUsage(ctx context.Context) (DiskUsage, error) {
+	du, err := c.api.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+
+	usage := DiskUsage{
+		LayersSize: du.LayersSize,
+		Images:     len(du.Images),
+		Containers: len(du.Containers),
+		Volumes:    len(du.Volumes),
+		BuildCache: len(du.BuildCache),
+	}
+
+	for _, img := range du.Images {
+		if img == nil {
+			continue
+		}
+		usage.ImagesSize += img.Size
+		if img.Containers == 0 {
+			usage.ImagesReclaimable += img.Size
+		}
+	}
+
+	for _, ctr := range du.Containers {
+		if ctr == nil {
+			continue
+		}
+		usage.ContainersSize += ctr.SizeRw
+	}
+
+	for _, vol := range du.Volumes {
+		if vol == nil || vol.UsageData == nil || vol.UsageData.Size < 0 {
+			continue
+		}
+		usage.VolumesSize += vol.UsageData.Size
+		if vol.UsageData.RefCount == 0 {
+			usage.VolumesReclaimable += vol.UsageData.Size
+		}
+	}
+
+	for _, rec := range du.BuildCache {
+		if rec == nil {
+			continue
+		}
+		usage.BuildCacheSize += rec.Size
+		if !rec.InUse && !rec.Shared {
+			usage.BuildCacheReclaimable += rec.Size
+		}
+	}
+
+	return usage, nil
+}
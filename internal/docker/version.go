@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/versions"
+)
+
+// Daemon API versions that introduced features DockWarden adapts to
+const (
+	apiHealthChecks       = "1.24"
+	apiStopSignal         = "1.42"
+	apiMultiNetworkCreate = "1.44"
+)
+
+// Features describes the API version spoken with the daemon and what it
+// supports. Older daemons, as shipped on many NAS devices, lack some of them.
+type Features struct {
+	// APIVersion is the version used for requests
+	APIVersion string `json:"api_version"`
+	// DaemonAPIVersion is the newest version the daemon supports
+	DaemonAPIVersion string `json:"daemon_api_version"`
+	// Pinned is set when the version was fixed with --docker-api-version or
+	// DOCKER_API_VERSION instead of negotiated
+	Pinned bool `json:"pinned"`
+
+	// HealthChecks reports container health status
+	HealthChecks bool `json:"health_checks"`
+	// StopSignal allows restarts with a custom stop signal
+	StopSignal bool `json:"stop_signal"`
+	// MultiNetworkCreate connects a new container to all its networks at
+	// creation; older daemons accept a single network
+	MultiNetworkCreate bool `json:"multi_network_create"`
+}
+
+// Features pings the daemon, negotiating the API version unless it is
+// pinned, and reports what the version in use supports
+func (c *dockerClient) Features(ctx context.Context) (Features, error) {
+	ping, err := c.api.Ping(ctx)
+	if err != nil {
+		return Features{}, fmt.Errorf("failed to ping Docker daemon: %w", err)
+	}
+	if !c.pinned {
+		c.api.NegotiateAPIVersionPing(ping)
+	}
+
+	version := c.api.ClientVersion()
+	return Features{
+		APIVersion:         version,
+		DaemonAPIVersion:   ping.APIVersion,
+		Pinned:             c.pinned,
+		HealthChecks:       versions.GreaterThanOrEqualTo(version, apiHealthChecks),
+		StopSignal:         versions.GreaterThanOrEqualTo(version, apiStopSignal),
+		MultiNetworkCreate: versions.GreaterThanOrEqualTo(version, apiMultiNetworkCreate),
+	}, nil
+}
+
+// supports reports whether the API version in use is at least minVersion
+func (c *dockerClient) supports(minVersion string) bool {
+	return versions.GreaterThanOrEqualTo(c.api.ClientVersion(), minVersion)
+}
@@ -0,0 +1,220 @@
+package eol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/emon5122/dockwarden/internal/meta"
+)
+
+// DefaultAPIURL is the endoflife.date API
+const DefaultAPIURL = "https://endoflife.date/api"
+
+// cacheTTL is how long product release data is reused
+const cacheTTL = 24 * time.Hour
+
+// DefaultProducts maps well-known image repositories to endoflife.date
+// products
+var DefaultProducts = map[string]string{
+	"docker.io/library/postgres":      "postgresql",
+	"docker.io/library/mysql":         "mysql",
+	"docker.io/library/mariadb":       "mariadb",
+	"docker.io/library/mongo":         "mongodb",
+	"docker.io/library/redis":         "redis",
+	"docker.io/library/node":          "nodejs",
+	"docker.io/library/php":           "php",
+	"docker.io/library/python":        "python",
+	"docker.io/library/ruby":          "ruby",
+	"docker.io/library/golang":        "go",
+	"docker.io/library/nginx":         "nginx",
+	"docker.io/library/debian":        "debian",
+	"docker.io/library/ubuntu":        "ubuntu",
+	"docker.io/library/alpine":        "alpine",
+	"docker.io/library/rabbitmq":      "rabbitmq",
+	"docker.io/library/elasticsearch": "elasticsearch",
+}
+
+// versionPrefix extracts the leading version of a tag (16.2-alpine -> 16.2)
+var versionPrefix = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)`)
+
+// Status is the end-of-life state of the release cycle an image tag belongs to
+type Status struct {
+	Product string `json:"product"`
+	Cycle   string `json:"cycle"`
+	// EOL is the end-of-life date, zero when none is published
+	EOL     time.Time `json:"eol"`
+	Expired bool      `json:"expired"`
+}
+
+// Checker looks up end-of-life dates of image tags on endoflife.date
+type Checker struct {
+	http     *http.Client
+	apiURL   string
+	products map[string]string
+
+	mu    sync.Mutex
+	cache map[string]cachedCycles
+}
+
+type cachedCycles struct {
+	cycles  []cycle
+	fetched time.Time
+}
+
+// cycle is a release cycle as served by the endoflife.date API. eol is
+// either a date or a boolean.
+type cycle struct {
+	Cycle string          `json:"cycle"`
+	EOL   json.RawMessage `json:"eol"`
+}
+
+// New creates a Checker. rules add or override image to product mappings as
+// "<image repository>=<product>", e.g. "ghcr.io/acme/php-base=php".
+func New(rules []string) (*Checker, error) {
+	products := make(map[string]string, len(DefaultProducts)+len(rules))
+	for repo, product := range DefaultProducts {
+		products[repo] = product
+	}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		repo, product, ok := strings.Cut(rule, "=")
+		if !ok || repo == "" || product == "" {
+			return nil, fmt.Errorf("invalid EOL rule %q (expected <image>=<product>)", rule)
+		}
+		named, err := reference.ParseNormalizedNamed(strings.TrimSpace(repo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid image in EOL rule %q: %w", rule, err)
+		}
+		products[named.Name()] = strings.TrimSpace(product)
+	}
+
+	return &Checker{
+		http:     &http.Client{Timeout: 15 * time.Second},
+		apiURL:   DefaultAPIURL,
+		products: products,
+		cache:    make(map[string]cachedCycles),
+	}, nil
+}
+
+// Check returns the end-of-life status of an image reference, or nil when
+// the image isn't a known product or its tag doesn't map to a release cycle
+func (c *Checker) Check(ctx context.Context, imageName string) (*Status, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return nil, nil
+	}
+	product, ok := c.products[named.Name()]
+	if !ok {
+		return nil, nil
+	}
+	tagged, ok := reference.TagNameOnly(named).(reference.Tagged)
+	if !ok {
+		return nil, nil
+	}
+	version := versionPrefix.FindStringSubmatch(tagged.Tag())
+	if version == nil {
+		return nil, nil
+	}
+
+	cycles, err := c.cycles(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+
+	match := matchCycle(cycles, version[1])
+	if match == nil {
+		return nil, nil
+	}
+	eol, ended, ok := parseEOL(match.EOL)
+	if !ok {
+		return nil, nil
+	}
+	return &Status{
+		Product: product,
+		Cycle:   match.Cycle,
+		EOL:     eol,
+		Expired: ended || (!eol.IsZero() && time.Now().After(eol)),
+	}, nil
+}
+
+// matchCycle returns the most specific cycle the version belongs to
+// (16.2 -> 16, 8.1.27 -> 8.1)
+func matchCycle(cycles []cycle, version string) *cycle {
+	var best *cycle
+	for i := range cycles {
+		cy := &cycles[i]
+		if version != cy.Cycle && !strings.HasPrefix(version, cy.Cycle+".") {
+			continue
+		}
+		if best == nil || len(cy.Cycle) > len(best.Cycle) {
+			best = cy
+		}
+	}
+	return best
+}
+
+// parseEOL reads the eol field: a date, true (ended without a published
+// date) or false (supported)
+func parseEOL(raw json.RawMessage) (date time.Time, ended, ok bool) {
+	var flag bool
+	if err := json.Unmarshal(raw, &flag); err == nil {
+		return time.Time{}, flag, true
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return time.Time{}, false, false
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	return date, false, true
+}
+
+// cycles returns the release cycles of a product, cached for a day
+func (c *Checker) cycles(ctx context.Context, product string) ([]cycle, error) {
+	c.mu.Lock()
+	cached, ok := c.cache[product]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetched) < cacheTTL {
+		return cached.cycles, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s.json", c.apiURL, product), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EOL request: %w", err)
+	}
+	req.Header.Set("User-Agent", "DockWarden/"+meta.Version)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query endoflife.date: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("endoflife.date returned status %d for %s", resp.StatusCode, product)
+	}
+
+	var cycles []cycle
+	if err := json.NewDecoder(resp.Body).Decode(&cycles); err != nil {
+		return nil, fmt.Errorf("failed to decode EOL data of %s: %w", product, err)
+	}
+
+	c.mu.Lock()
+	c.cache[product] = cachedCycles{cycles: cycles, fetched: time.Now()}
+	c.mu.Unlock()
+	return cycles, nil
+}
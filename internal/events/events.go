@@ -0,0 +1,77 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published by the updater
+const (
+	TypeCycleStart      = "cycle_start"
+	TypeCycleEnd        = "cycle_end"
+	TypeCycleDeferred   = "cycle_deferred"
+	TypeContainerUpdate = "container_updated"
+	TypeContainerFailed = "container_update_failed"
+)
+
+// TypeAPIAction is published by the API for actions triggered through it
+const TypeAPIAction = "api_action"
+
+// Event is something that happened in DockWarden, delivered to subscribers
+// such as the gRPC event stream
+type Event struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	CycleID   string    `json:"cycle_id,omitempty"`
+	Container string    `json:"container,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// Broker fans events out to subscribers. Publishing never blocks: a
+// subscriber that doesn't keep up misses events instead of stalling updates.
+type Broker struct {
+	mu   sync.RWMutex
+	subs map[chan Event]struct{}
+}
+
+// NewBroker creates an event broker
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[chan Event]struct{})}
+}
+
+// Publish delivers e to every subscriber with room in its buffer
+func (b *Broker) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving published events and a function
+// that cancels the subscription and closes the channel
+func (b *Broker) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package feed loads docker-save archives dropped into a directory and
+// updates the containers using the loaded images. Together with offline
+// mode it lets air-gapped hosts receive updates from a USB stick or a
+// synced volume instead of a registry.
+package feed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/clock"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/scheduler"
+	"github.com/emon5122/dockwarden/internal/updater"
+	log "github.com/sirupsen/logrus"
+)
+
+// Suffixes appended to archives once they were processed
+const (
+	LoadedSuffix = ".loaded"
+	FailedSuffix = ".failed"
+)
+
+// settle is how long an archive must go unmodified before it is loaded, so
+// files still being copied into the directory are left alone
+const settle = 5 * time.Second
+
+// archiveSuffixes are the file names recognized as image archives; the
+// daemon decompresses gzip archives itself
+var archiveSuffixes = []string{".tar", ".tar.gz", ".tgz"}
+
+// Watcher polls a directory for image archives
+type Watcher struct {
+	dir   string
+	upd   *updater.Updater
+	sched *scheduler.Scheduler
+	clock clock.Clock
+
+	// done remembers archives that couldn't be renamed after processing,
+	// keyed by path, with the modification time they had
+	done map[string]time.Time
+}
+
+// New creates a Watcher polling dir every interval
+func New(dir string, interval time.Duration, upd *updater.Updater, clk clock.Clock) *Watcher {
+	return &Watcher{
+		dir: dir,
+		upd: upd,
+		sched: scheduler.NewWithOptions(scheduler.Options{
+			Name:       "image-feed",
+			Interval:   interval,
+			RunOnStart: true,
+		}, clk),
+		clock: clk,
+		done:  make(map[string]time.Time),
+	}
+}
+
+// Start starts polling in the background
+func (w *Watcher) Start() {
+	log.WithField("path", w.dir).Info("Watching image feed directory")
+	w.sched.Start(w.Poll)
+}
+
+// Stop stops polling
+func (w *Watcher) Stop() {
+	w.sched.Stop()
+}
+
+// Poll loads every settled archive in the directory, oldest first, then
+// runs an update cycle for the containers using the loaded images
+func (w *Watcher) Poll() {
+	if !w.upd.IsLeader() {
+		log.Debug("Standby instance, skipping image feed")
+		return
+	}
+
+	archives, err := w.archives()
+	if err != nil {
+		log.WithError(err).WithField("path", w.dir).Warn("Failed to read image feed directory")
+		return
+	}
+
+	var loaded []string
+	for _, path := range archives {
+		loaded = append(loaded, w.load(path)...)
+	}
+	if len(loaded) == 0 {
+		return
+	}
+
+	if err := w.upd.RunMatching(updater.UsesImages(loaded)); err != nil {
+		log.WithError(err).Error("Update cycle for loaded images failed")
+	}
+}
+
+// archives lists the settled, unprocessed archives sorted by modification
+// time
+func (w *Watcher) archives() ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type archive struct {
+		path    string
+		modTime time.Time
+	}
+	var found []archive
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !isArchive(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(w.dir, entry.Name())
+		if done, ok := w.done[path]; ok && done.Equal(info.ModTime()) {
+			continue
+		}
+		if w.clock.Now().Sub(info.ModTime()) < settle {
+			continue
+		}
+		found = append(found, archive{path: path, modTime: info.ModTime()})
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.Before(found[j].modTime) })
+	paths := make([]string, len(found))
+	for i, a := range found {
+		paths[i] = a.path
+	}
+	return paths, nil
+}
+
+// load loads a single archive and marks it processed
+func (w *Watcher) load(path string) []string {
+	logger := log.WithFields(log.Fields{
+		"path":              path,
+		logging.FieldAction: "load",
+	})
+
+	images, err := w.loadFile(path)
+	suffix := LoadedSuffix
+	if err != nil {
+		logger.WithError(err).Error("Failed to load image archive")
+		suffix = FailedSuffix
+	}
+	w.markDone(logger, path, suffix)
+	return images
+}
+
+func (w *Watcher) loadFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ctx := logging.WithLogger(context.Background(), log.WithField("path", path))
+	return w.upd.LoadImages(ctx, f)
+}
+
+// markDone renames a processed archive so it isn't loaded again. Read-only
+// feeds are remembered in memory instead.
+func (w *Watcher) markDone(logger *log.Entry, path, suffix string) {
+	err := os.Rename(path, path+suffix)
+	if err == nil {
+		return
+	}
+	logger.WithError(err).Debug("Failed to rename processed archive, remembering it instead")
+
+	if info, err := os.Stat(path); err == nil {
+		w.done[path] = info.ModTime()
+	}
+}
+
+// isArchive reports whether name looks like an image archive. Hidden files
+// are ignored, so archives can be copied under a dot name and renamed once
+// complete.
+func isArchive(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
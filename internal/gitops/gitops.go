@@ -0,0 +1,235 @@
+// Package gitops commits what DockWarden deployed to a Git repository, so
+// infrastructure-as-code repositories reflect automatic updates. It runs the
+// git CLI, which has to be available to DockWarden.
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultCommand is the git binary run when no command is configured
+const DefaultCommand = "git"
+
+// ChangelogFile is the file changelog entries are prepended to
+const ChangelogFile = "CHANGELOG.md"
+
+// changelogHeader starts a new changelog
+const changelogHeader = "# Deployed updates\n\n"
+
+// pushAttempts is how often a commit is rebuilt on the latest branch and
+// pushed again when the push is rejected because the branch moved
+const pushAttempts = 3
+
+// Options configure the repository commits go to
+type Options struct {
+	// URL is the https:// or ssh URL of the repository
+	URL    string
+	Branch string
+	// Path is the directory inside the repository files are written to
+	Path string
+	// Username and Token authenticate https URLs
+	Username string
+	Token    string
+	// SSHKey is the private key file authenticating ssh URLs
+	SSHKey string
+	// Author is the commit author, as "Name <email>"
+	Author string
+	// Command runs git, DefaultCommand when empty
+	Command string
+	// WorkDir holds the local clone
+	WorkDir string
+}
+
+// Change is what one commit adds to the repository
+type Change struct {
+	// Files are written to Path, keyed by file name
+	Files map[string][]byte
+	// Changelog is prepended to CHANGELOG.md in Path when not empty
+	Changelog string
+	Message   string
+}
+
+// Repo commits changes to a Git repository. Commits are serialized.
+type Repo struct {
+	mu   sync.Mutex
+	opts Options
+}
+
+// ParseAuthor splits a commit author given as "Name <email>"
+func ParseAuthor(author string) (name, email string, err error) {
+	addr, err := mail.ParseAddress(author)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid commit author %q, use \"Name <email>\": %w", author, err)
+	}
+	if addr.Name == "" {
+		addr.Name = addr.Address
+	}
+	return addr.Name, addr.Address, nil
+}
+
+// New returns the repository described by opts
+func New(opts Options) *Repo {
+	if opts.Command == "" {
+		opts.Command = DefaultCommand
+	}
+	return &Repo{opts: opts}
+}
+
+// Commit writes a change to the local clone, commits it and pushes it. A
+// push rejected because the branch moved is retried on top of the new head.
+// Changes that leave the repository as it is don't create a commit.
+func (r *Repo) Commit(ctx context.Context, change Change) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	for attempt := 1; attempt <= pushAttempts; attempt++ {
+		if err = r.sync(ctx); err != nil {
+			return err
+		}
+		committed, cerr := r.commit(ctx, change)
+		if cerr != nil || !committed {
+			return cerr
+		}
+		if err = r.git(ctx, r.opts.WorkDir, "push", "origin", "HEAD:refs/heads/"+r.opts.Branch); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to push to %s: %w", r.opts.Branch, err)
+}
+
+// sync brings the local clone to the head of the branch, cloning it first
+// when needed
+func (r *Repo) sync(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(r.opts.WorkDir, ".git")); err != nil {
+		if err := os.RemoveAll(r.opts.WorkDir); err != nil {
+			return fmt.Errorf("failed to reset git work directory: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(r.opts.WorkDir), 0o755); err != nil {
+			return fmt.Errorf("failed to create git work directory: %w", err)
+		}
+		if err := r.git(ctx, "", "clone", "--branch", r.opts.Branch, "--single-branch", "--depth", "1", r.opts.URL, r.opts.WorkDir); err != nil {
+			return fmt.Errorf("failed to clone repository: %w", err)
+		}
+		return nil
+	}
+
+	if err := r.git(ctx, r.opts.WorkDir, "fetch", "--depth", "1", "origin", r.opts.Branch); err != nil {
+		return fmt.Errorf("failed to fetch repository: %w", err)
+	}
+	if err := r.git(ctx, r.opts.WorkDir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+		return fmt.Errorf("failed to reset repository: %w", err)
+	}
+	return nil
+}
+
+// commit writes the change and commits it, reporting whether anything changed
+func (r *Repo) commit(ctx context.Context, change Change) (bool, error) {
+	dir := filepath.Join(r.opts.WorkDir, r.opts.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", r.opts.Path, err)
+	}
+	for name, data := range change.Files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return false, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if change.Changelog != "" {
+		if err := prependChangelog(filepath.Join(dir, ChangelogFile), change.Changelog); err != nil {
+			return false, err
+		}
+	}
+
+	pathspec := r.opts.Path
+	if pathspec == "" {
+		pathspec = "."
+	}
+	if err := r.git(ctx, r.opts.WorkDir, "add", "--all", "--", pathspec); err != nil {
+		return false, fmt.Errorf("failed to stage changes: %w", err)
+	}
+	var exitErr *exec.ExitError
+	switch err := r.git(ctx, r.opts.WorkDir, "diff", "--cached", "--quiet"); {
+	case err == nil:
+		return false, nil
+	case !errors.As(err, &exitErr):
+		return false, fmt.Errorf("failed to diff changes: %w", err)
+	}
+	if err := r.git(ctx, r.opts.WorkDir, "commit", "--message", change.Message); err != nil {
+		return false, fmt.Errorf("failed to commit: %w", err)
+	}
+	return true, nil
+}
+
+// prependChangelog adds an entry at the top of a changelog, below its header
+func prependChangelog(path, entry string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read changelog: %w", err)
+	}
+	rest := strings.TrimPrefix(string(data), changelogHeader)
+	content := changelogHeader + strings.TrimRight(entry, "\n") + "\n"
+	if rest != "" {
+		content += "\n" + rest
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return nil
+}
+
+// git runs a git command in dir. The combined output is included in errors.
+func (r *Repo) git(ctx context.Context, dir string, args ...string) error {
+	command := strings.Fields(r.opts.Command)
+	if len(command) == 0 {
+		command = []string{DefaultCommand}
+	}
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], append(command[1:], args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), r.env()...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("git %s: %w: %s", args[0], err, out)
+		}
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// env returns the environment authenticating git and setting the commit
+// author. The token is passed as an extra HTTP header through the
+// environment, so it never ends up in the clone's configuration or in
+// process listings.
+func (r *Repo) env() []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if name, email, err := ParseAuthor(r.opts.Author); err == nil {
+		env = append(env,
+			"GIT_AUTHOR_NAME="+name, "GIT_AUTHOR_EMAIL="+email,
+			"GIT_COMMITTER_NAME="+name, "GIT_COMMITTER_EMAIL="+email,
+		)
+	}
+	if r.opts.Token != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(r.opts.Username + ":" + r.opts.Token))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+	if r.opts.SSHKey != "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -i "+r.opts.SSHKey+" -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new")
+	}
+	return env
+}
@@ -0,0 +1,169 @@
+// Package grafana pushes DockWarden events to Grafana as annotations, so
+// container updates show up as deployment markers on existing dashboards.
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/events"
+	"github.com/emon5122/dockwarden/internal/meta"
+	log "github.com/sirupsen/logrus"
+)
+
+// requestTimeout bounds a single annotation request
+const requestTimeout = 10 * time.Second
+
+// forwardBuffer is how many events may wait for Grafana before further
+// events are dropped
+const forwardBuffer = 256
+
+// Options configure the annotation client
+type Options struct {
+	// URL is the base URL of Grafana, e.g. https://grafana.example.com.
+	// Credentials in the URL are sent as basic auth.
+	URL string
+	// Token is a service account token, sent as a Bearer token
+	Token string
+	// Tags are added to every annotation
+	Tags []string
+	// DashboardUID limits annotations to one dashboard; without it they are
+	// organization annotations shown by tag queries on any dashboard
+	DashboardUID string
+}
+
+// Annotation is a point in time marked on Grafana dashboards
+type Annotation struct {
+	Time time.Time
+	Tags []string
+	Text string
+}
+
+// Client creates annotations through the Grafana HTTP API
+type Client struct {
+	endpoint     string
+	username     string
+	password     string
+	token        string
+	tags         []string
+	dashboardUID string
+	http         *http.Client
+}
+
+// New creates a client for the Grafana instance at opts.URL
+func New(opts Options) (*Client, error) {
+	u, err := url.Parse(strings.TrimSpace(opts.URL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Grafana URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("unsupported Grafana URL %q, expected http:// or https://", u.Redacted())
+	}
+
+	c := &Client{
+		token:        opts.Token,
+		tags:         opts.Tags,
+		dashboardUID: opts.DashboardUID,
+		http:         &http.Client{Timeout: requestTimeout},
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+		u.User = nil
+	}
+	c.endpoint = strings.TrimSuffix(u.String(), "/") + "/api/annotations"
+	return c, nil
+}
+
+// Annotate creates an annotation. The client's tags are added to a's.
+func (c *Client) Annotate(ctx context.Context, a Annotation) error {
+	body := map[string]interface{}{
+		"time": a.Time.UnixMilli(),
+		"tags": append(append([]string{}, c.tags...), a.Tags...),
+		"text": a.Text,
+	}
+	if c.dashboardUID != "" {
+		body["dashboardUID"] = c.dashboardUID
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode annotation: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DockWarden/"+meta.Version)
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send annotation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("grafana returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Forward annotates the container updates and failed updates published on
+// broker until the returned function is called, which waits for the events
+// already received to be sent
+func Forward(broker *events.Broker, c *Client) func() {
+	ch, cancel := broker.Subscribe(forwardBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range ch {
+			a, ok := annotation(e)
+			if !ok {
+				continue
+			}
+			ctx, stop := context.WithTimeout(context.Background(), requestTimeout)
+			if err := c.Annotate(ctx, a); err != nil {
+				log.WithError(err).WithField("event", e.Type).Warn("Failed to push Grafana annotation")
+			}
+			stop()
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// annotation maps an event to an annotation, tagged with the event type and
+// the container so dashboards can filter them. Only container updates and
+// failed updates are annotated.
+func annotation(e events.Event) (Annotation, bool) {
+	if e.Type != events.TypeContainerUpdate && e.Type != events.TypeContainerFailed {
+		return Annotation{}, false
+	}
+
+	text := e.Container + ": " + e.Message
+	if e.Image != "" {
+		text += " (" + e.Image + ")"
+	}
+	tags := []string{e.Type}
+	if e.Container != "" {
+		tags = append(tags, e.Container)
+	}
+	return Annotation{Time: e.Time, Tags: tags, Text: text}, true
+}
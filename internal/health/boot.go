@@ -0,0 +1,111 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	log "github.com/sirupsen/logrus"
+)
+
+// procStat is where the Linux kernel reports its boot time
+const procStat = "/proc/stat"
+
+// bootTime returns when the Docker host last booted. With a daemon on a
+// local socket DockWarden shares the host's kernel, whose boot time is
+// exact; otherwise it is estimated from the containers.
+func (w *Watcher) bootTime(containers []docker.Container) time.Time {
+	if strings.HasPrefix(w.client.DaemonHost(), "unix://") {
+		if boot, err := kernelBootTime(procStat); err == nil {
+			return boot
+		}
+	}
+	return estimateBootTime(containers)
+}
+
+// kernelBootTime reads the btime line of /proc/stat at path, the boot time
+// of the kernel in seconds since the epoch. Containers share the host's
+// kernel, so it is the host's boot time inside a container as well.
+func kernelBootTime(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != "btime" {
+			continue
+		}
+		secs, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid btime %q in %s: %w", fields[1], path, err)
+		}
+		return time.Unix(secs, 0), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, fmt.Errorf("no btime in %s", path)
+}
+
+// estimateBootTime estimates when the Docker host last booted. After a
+// reboot every running container was started after it, so it is the
+// earliest start of the running containers. It is zero for fewer than two
+// containers, where a single restart can't be told apart from a reboot.
+func estimateBootTime(containers []docker.Container) time.Time {
+	var boot time.Time
+	started := 0
+	for _, ctr := range containers {
+		if !ctr.IsRunning() || ctr.StartedAt.IsZero() {
+			continue
+		}
+		started++
+		if boot.IsZero() || ctr.StartedAt.Before(boot) {
+			boot = ctr.StartedAt
+		}
+	}
+	if started < 2 {
+		return time.Time{}
+	}
+	return boot
+}
+
+// checkStabilizing reports whether health actions are suppressed because
+// the host booted less than HealthStabilization ago. The daemon's clock is
+// used when available, so a skewed container clock doesn't matter.
+func (w *Watcher) checkStabilizing(ctx context.Context, containers []docker.Container) bool {
+	if w.config.HealthStabilization <= 0 {
+		return false
+	}
+	boot := w.bootTime(containers)
+	if boot.IsZero() {
+		return false
+	}
+
+	now := w.clock.Now()
+	if info, err := w.client.Info(ctx); err == nil {
+		if t, err := time.Parse(time.RFC3339Nano, info.SystemTime); err == nil {
+			now = t
+		}
+	}
+	if now.Sub(boot) >= w.config.HealthStabilization {
+		return false
+	}
+
+	if !boot.Equal(w.boot) {
+		w.boot = boot
+		log.WithFields(log.Fields{
+			"boot":          boot.Format(time.RFC3339),
+			"stabilization": w.config.HealthStabilization.String(),
+		}).Info("Host reboot detected, suppressing health actions while containers warm up")
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/notify"
+)
+
+// digestPeriod counts health events between two digests
+type digestPeriod struct {
+	since        time.Time
+	monitored    map[string]bool
+	unhealthy    int
+	restarts     int
+	gaveUp       []string
+	crashLooping []string
+}
+
+func newDigestPeriod(since time.Time) digestPeriod {
+	return digestPeriod{since: since, monitored: make(map[string]bool)}
+}
+
+func (w *Watcher) countMonitored(identity string) {
+	w.periodMu.Lock()
+	defer w.periodMu.Unlock()
+	w.period.monitored[identity] = true
+}
+
+func (w *Watcher) countUnhealthy() {
+	w.periodMu.Lock()
+	defer w.periodMu.Unlock()
+	w.period.unhealthy++
+}
+
+func (w *Watcher) countRestart() {
+	w.periodMu.Lock()
+	defer w.periodMu.Unlock()
+	w.period.restarts++
+}
+
+func (w *Watcher) countGaveUp(name string) {
+	w.periodMu.Lock()
+	defer w.periodMu.Unlock()
+	if !slices.Contains(w.period.gaveUp, name) {
+		w.period.gaveUp = append(w.period.gaveUp, name)
+	}
+}
+
+func (w *Watcher) countCrashLoop(name string) {
+	w.periodMu.Lock()
+	defer w.periodMu.Unlock()
+	if !slices.Contains(w.period.crashLooping, name) {
+		w.period.crashLooping = append(w.period.crashLooping, name)
+	}
+}
+
+// TakeDigest returns the health events counted since the previous digest
+// and starts a new period. Containers given up on earlier and still waiting
+// for a new image are listed again, so every digest shows them.
+func (w *Watcher) TakeDigest() notify.HealthDigest {
+	now := w.clock.Now()
+
+	w.periodMu.Lock()
+	period := w.period
+	w.period = newDigestPeriod(now)
+	w.periodMu.Unlock()
+
+	gaveUp := period.gaveUp
+	for _, status := range w.RestartStatuses() {
+		if status.GaveUp && !slices.Contains(gaveUp, status.Name) {
+			gaveUp = append(gaveUp, status.Name)
+		}
+	}
+	sort.Strings(gaveUp)
+	sort.Strings(period.crashLooping)
+
+	return notify.HealthDigest{
+		Since:        period.since,
+		Until:        now,
+		Monitored:    len(period.monitored),
+		Unhealthy:    period.unhealthy,
+		Restarts:     period.restarts,
+		GaveUp:       gaveUp,
+		CrashLooping: period.crashLooping,
+	}
+}
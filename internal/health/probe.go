@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProbeTimeout bounds a single TCP probe
+const ProbeTimeout = 2 * time.Second
+
+// probeHealth returns the health status of a container. Containers without
+// a HEALTHCHECK that opted into the TCP probe are reported unhealthy once
+// HealthTCPFailures probes of their first published TCP port failed in a
+// row, closing the gap for the many images that ship no healthcheck.
+func (w *Watcher) probeHealth(ctx context.Context, ctr docker.Container, state *containerState) string {
+	if ctr.HealthStatus != "" || !ctr.IsRunning() || !ctr.TCPProbeEnabled(w.config.HealthTCPProbe) {
+		return ctr.HealthStatus
+	}
+	addr, ok := w.probeAddress(ctr)
+	if !ok {
+		return ctr.HealthStatus
+	}
+
+	logger := logging.FromContext(ctx).WithField("probe", addr)
+	conn, err := net.DialTimeout("tcp", addr, ProbeTimeout)
+	if err == nil {
+		conn.Close()
+		if state.probeFailures > 0 {
+			logger.WithField("failures", state.probeFailures).Info("TCP probe succeeded again")
+		}
+		state.probeFailures = 0
+		return "healthy"
+	}
+
+	state.probeFailures++
+	logger.WithError(err).WithFields(log.Fields{
+		"failures":  state.probeFailures,
+		"threshold": w.config.HealthTCPFailures,
+	}).Debug("TCP probe failed")
+	if state.probeFailures >= w.config.HealthTCPFailures {
+		// Count again from zero, so a restarted container gets as many
+		// probes to come up before the next action
+		state.probeFailures = 0
+		return "unhealthy"
+	}
+	return ctr.HealthStatus
+}
+
+// probeAddress returns the address probed for a container: HealthTCPHost on
+// the published port, or the container's first address on the private port
+func (w *Watcher) probeAddress(ctr docker.Container) (string, bool) {
+	port, ok := ctr.ProbePort()
+	if !ok {
+		return "", false
+	}
+	if w.config.HealthTCPHost != "" {
+		return net.JoinHostPort(w.config.HealthTCPHost, strconv.Itoa(int(port.PublicPort))), true
+	}
+	if len(ctr.IPAddresses) == 0 {
+		return "", false
+	}
+	return net.JoinHostPort(ctr.IPAddresses[0], strconv.Itoa(int(port.PrivatePort))), true
+}
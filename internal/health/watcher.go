@@ -2,78 +2,162 @@ package health
 
 import (
 	"context"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/emon5122/dockwarden/internal/clock"
 	"github.com/emon5122/dockwarden/internal/config"
 	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/lock"
+	"github.com/emon5122/dockwarden/internal/logging"
 	"github.com/emon5122/dockwarden/internal/notify"
+	"github.com/emon5122/dockwarden/internal/scheduler"
 	log "github.com/sirupsen/logrus"
 )
 
 const (
 	// MaxRestartAttempts is the maximum number of restart attempts before giving up
 	MaxRestartAttempts = 5
-	// HealthCheckInterval is the interval between health checks
-	HealthCheckInterval = 10 * time.Second
+	// MaxRecreateAttempts is the maximum number of recreate attempts before
+	// giving up; a recreate is heavier than a restart, so fewer are tried
+	MaxRecreateAttempts = 3
+	// CrashLoopRestarts is how many daemon restarts within CrashLoopWindow
+	// mark a container as crash looping
+	CrashLoopRestarts = 3
+	// CrashLoopWindow is the window crash loop restarts are counted in
+	CrashLoopWindow = 5 * time.Minute
+	// ExecTimeout bounds the dockwarden.health.exec command
+	ExecTimeout = 30 * time.Second
+)
+
+// Actions taken on unhealthy containers
+const (
+	ActionRestart = "restart"
+	// ActionRecreate removes the container and creates it again from its
+	// configuration, for failures a restart keeps (corrupted tmpfs, leaked
+	// file descriptors)
+	ActionRecreate = "recreate"
+	ActionNotify   = "notify"
 )
 
 // containerState tracks the state of health monitoring for a container
 type containerState struct {
+	name            string
+	image           string
 	restartAttempts int
-	lastImageID     string
-	gaveUp          bool
-	mu              sync.Mutex
+	// recreateAttempts counts recreates separately from restarts
+	recreateAttempts int
+	lastImageID      string
+	gaveUp           bool
+	// execTried is whether the dockwarden.health.exec command already ran
+	// since the container was last healthy
+	execTried bool
+
+	// Daemon restarts seen through RestartCount, for crash loop detection
+	restartCountSeen bool
+	lastRestartCount int
+	restarts         []time.Time
+	crashLooping     bool
+
+	// probeFailures counts failed TCP probes in a row
+	probeFailures int
+
+	// unhealthy is whether the last check saw the container unhealthy, so
+	// the digest counts each time it becomes unhealthy once
+	unhealthy bool
+
+	mu sync.Mutex
 }
 
 // Watcher monitors container health and takes action using Go's native concurrency
 type Watcher struct {
 	client   docker.Client
 	config   *config.Config
+	clock    clock.Clock
 	notifier *notify.Notifier
+	quiet    scheduler.Window
+	elector  *lock.Elector
 	stopChan chan struct{}
 	wg       sync.WaitGroup
 
-	// Track container states for retry logic
+	// lockCycle, when set, takes the updater's cycle lock around recreates
+	lockCycle func(context.Context) (func(), error)
+
+	// Track container states for retry logic, keyed by container identity
+	// so tracking continues across recreates
 	states   map[string]*containerState
 	statesMu sync.RWMutex
+
+	// period counts health events for the next digest
+	period   digestPeriod
+	periodMu sync.Mutex
+
+	// stabilizing is set for a check that falls into the stabilization
+	// window after a host reboot; boot is the last reboot logged
+	stabilizing bool
+	boot        time.Time
 }
 
-// NewWatcher creates a new health watcher
-func NewWatcher(client docker.Client, cfg *config.Config) *Watcher {
-	var notifier *notify.Notifier
-	if cfg.NotificationURL != "" {
-		notifier = notify.New(cfg.NotificationURL)
+// NewWatcher creates a new health watcher driven by the given clock
+func NewWatcher(client docker.Client, cfg *config.Config, clk clock.Clock) *Watcher {
+	// Validated at startup
+	quiet, _ := scheduler.ParseWindow(cfg.HealthQuietHours)
+
+	// Health events only show up in the digest when it replaces them
+	notifier := notify.FromConfig(cfg)
+	if cfg.HealthDigestOnly {
+		notifier = nil
 	}
 
 	return &Watcher{
 		client:   client,
 		config:   cfg,
+		clock:    clk,
 		notifier: notifier,
+		quiet:    quiet,
 		stopChan: make(chan struct{}),
 		states:   make(map[string]*containerState),
+		period:   newDigestPeriod(clk.Now()),
 	}
 }
 
-// Start begins health monitoring with concurrent container checks
+// Start begins health monitoring with concurrent container checks, timed
+// by the health interval or cron schedule. It blocks until Stop is called.
 func (w *Watcher) Start() {
 	w.wg.Add(1)
 	defer w.wg.Done()
 
-	ticker := time.NewTicker(HealthCheckInterval)
-	defer ticker.Stop()
-
-	log.Info("Health watcher started")
+	sched := scheduler.NewWithOptions(scheduler.Options{
+		Name:     "health",
+		Schedule: w.config.HealthSchedule,
+		Interval: w.config.HealthInterval,
+	}, w.clock)
+	sched.Start(w.checkHealthConcurrently)
 
-	for {
-		select {
-		case <-ticker.C:
-			w.checkHealthConcurrently()
-		case <-w.stopChan:
-			log.Info("Health watcher stopped")
-			return
-		}
+	logger := log.NewEntry(log.StandardLogger())
+	if !w.quiet.IsZero() {
+		logger = logger.WithField("quiet_hours", w.quiet.String())
 	}
+	logger.Info("Health watcher started")
+
+	<-w.stopChan
+	sched.Stop()
+	log.Info("Health watcher stopped")
+}
+
+// SetElector makes the watcher act on containers only while e reports this
+// instance as the leader
+func (w *Watcher) SetElector(e *lock.Elector) {
+	w.elector = e
+}
+
+// SetCycleLock makes the watcher hold the updater's cycle lock while it
+// recreates a container, so it never races an update of the same container.
+// lockCycle must fail instead of waiting while the lock is held.
+func (w *Watcher) SetCycleLock(lockCycle func(context.Context) (func(), error)) {
+	w.lockCycle = lockCycle
 }
 
 // Stop stops the health watcher
@@ -82,22 +166,27 @@ func (w *Watcher) Stop() {
 	w.wg.Wait()
 }
 
-// getContainerState gets or creates state for a container
-func (w *Watcher) getContainerState(containerID string) *containerState {
+// getContainerState gets or creates state for a container identity
+func (w *Watcher) getContainerState(identity string) *containerState {
 	w.statesMu.Lock()
 	defer w.statesMu.Unlock()
 
-	if state, ok := w.states[containerID]; ok {
+	if state, ok := w.states[identity]; ok {
 		return state
 	}
 
 	state := &containerState{}
-	w.states[containerID] = state
+	w.states[identity] = state
 	return state
 }
 
 // checkHealthConcurrently checks all containers for health issues using goroutines
 func (w *Watcher) checkHealthConcurrently() {
+	if !w.elector.IsLeader() {
+		log.Debug("Standby instance, skipping health check")
+		return
+	}
+
 	ctx := context.Background()
 
 	containers, err := w.client.ListContainers(ctx, docker.ListOptions{
@@ -105,10 +194,13 @@ func (w *Watcher) checkHealthConcurrently() {
 		IncludeHealth: true,
 	})
 	if err != nil {
-		log.Errorf("Failed to list containers for health check: %v", err)
+		log.WithError(err).Error("Failed to list containers for health check")
 		return
 	}
 
+	w.stabilizing = w.checkStabilizing(ctx, containers)
+	daemonHost := w.client.DaemonHost()
+
 	// Process containers concurrently using goroutines
 	var wg sync.WaitGroup
 	for _, ctr := range containers {
@@ -117,13 +209,23 @@ func (w *Watcher) checkHealthConcurrently() {
 			continue
 		}
 
+		// Never restart the socket proxy DockWarden talks to Docker through
+		if ctr.ProvidesDaemon(daemonHost) {
+			continue
+		}
+
+		// Skip short-lived containers of CI runners and one-shot jobs
+		if ctr.EphemeralReason(w.config.EphemeralRules, w.config.EphemeralMinAge, w.clock.Now()) != "" && !ctr.WatchSelected(w.config.LabelName) {
+			continue
+		}
+
 		// Check scope filter
 		if w.config.Scope != "" && ctr.GetScope() != w.config.Scope {
 			continue
 		}
 
 		// Check label filter
-		if w.config.LabelEnable && !ctr.IsEnabled(w.config.LabelName, false) {
+		if w.config.LabelEnable && !ctr.WatchSelected(w.config.LabelName) {
 			continue
 		}
 
@@ -131,7 +233,12 @@ func (w *Watcher) checkHealthConcurrently() {
 		wg.Add(1)
 		go func(container docker.Container) {
 			defer wg.Done()
-			w.processContainer(ctx, container)
+			logger := log.WithFields(log.Fields{
+				logging.FieldContainer:   container.Name,
+				logging.FieldContainerID: shortID(container.ID),
+				logging.FieldImage:       container.Image,
+			})
+			w.processContainer(logging.WithLogger(ctx, logger), container)
 		}(ctr)
 	}
 
@@ -141,91 +248,338 @@ func (w *Watcher) checkHealthConcurrently() {
 
 // processContainer handles health check for a single container
 func (w *Watcher) processContainer(ctx context.Context, ctr docker.Container) {
-	state := w.getContainerState(ctr.ID)
+	logger := logging.FromContext(ctx)
+	state := w.getContainerState(ctr.Identity())
 	state.mu.Lock()
 	defer state.mu.Unlock()
 
 	// Check if container image has been updated (reset attempts if new version)
 	if state.lastImageID != "" && state.lastImageID != ctr.ImageID {
-		log.Infof("Container %s has new image, resetting health tracking", ctr.Name)
+		logger.Info("Container has new image, resetting health tracking")
 		state.restartAttempts = 0
+		state.recreateAttempts = 0
 		state.gaveUp = false
 	}
 	state.lastImageID = ctr.ImageID
+	state.name = ctr.Name
+	state.image = ctr.Image
+	w.countMonitored(ctr.Identity())
+
+	w.trackRestarts(ctx, ctr, state)
+	ctr.HealthStatus = w.probeHealth(ctx, ctr, state)
+
+	if ctr.IsUnhealthy() && !state.unhealthy {
+		w.countUnhealthy()
+	}
+	state.unhealthy = ctr.IsUnhealthy()
 
 	// Skip if we've given up on this container version
 	if state.gaveUp {
-		log.Debugf("Container %s: gave up after %d attempts, waiting for new version", ctr.Name, MaxRestartAttempts)
+		logger.Debug("Gave up on container, waiting for new version")
 		return
 	}
 
 	// Handle unhealthy containers
-	if ctr.IsUnhealthy() {
+	if ctr.IsUnhealthy() && w.stabilizing {
+		logger.Debug("Host rebooted recently, deferring health action while containers warm up")
+	} else if ctr.IsUnhealthy() {
 		w.handleUnhealthy(ctx, ctr, state)
 	} else if ctr.IsHealthy() {
 		// Reset attempts if container is now healthy
-		if state.restartAttempts > 0 {
-			log.Infof("Container %s is now healthy after %d restart(s)", ctr.Name, state.restartAttempts)
+		if state.restartAttempts > 0 || state.recreateAttempts > 0 {
+			logger.WithFields(log.Fields{
+				"restarts":  state.restartAttempts,
+				"recreates": state.recreateAttempts,
+			}).Info("Container is now healthy")
 			state.restartAttempts = 0
+			state.recreateAttempts = 0
+		}
+		state.execTried = false
+	}
+}
+
+// trackRestarts detects crash loops from RestartCount deltas. Containers
+// whose restart policy brings them back after every crash never reach the
+// unhealthy state, so they would otherwise go unnoticed.
+func (w *Watcher) trackRestarts(ctx context.Context, ctr docker.Container, state *containerState) {
+	now := w.clock.Now()
+	if state.restartCountSeen && ctr.RestartCount > state.lastRestartCount {
+		for range ctr.RestartCount - state.lastRestartCount {
+			state.restarts = append(state.restarts, now)
+		}
+	}
+	state.restartCountSeen = true
+	state.lastRestartCount = ctr.RestartCount
+	state.restarts = slices.DeleteFunc(state.restarts, func(t time.Time) bool {
+		return now.Sub(t) > CrashLoopWindow
+	})
+
+	logger := logging.FromContext(ctx)
+	switch {
+	case len(state.restarts) >= CrashLoopRestarts && !state.crashLooping:
+		state.crashLooping = true
+		logger.WithFields(log.Fields{
+			"restarts":      len(state.restarts),
+			"window":        CrashLoopWindow.String(),
+			"restart_count": ctr.RestartCount,
+		}).Error("Container is crash looping")
+		w.countCrashLoop(ctr.Name)
+
+		if w.notifier != nil {
+			w.notifier.NotifyContainerCrashLoop(ctr.Name, ctr.Image, len(state.restarts), CrashLoopWindow, contact(ctr))
 		}
+	case len(state.restarts) == 0 && state.crashLooping:
+		state.crashLooping = false
+		logger.Info("Container is no longer crash looping")
 	}
 }
 
 // handleUnhealthy handles an unhealthy container with retry logic
 func (w *Watcher) handleUnhealthy(ctx context.Context, ctr docker.Container, state *containerState) {
-	log.Warnf("Container %s is unhealthy (attempt %d/%d)", ctr.Name, state.restartAttempts+1, MaxRestartAttempts)
+	logger := logging.FromContext(ctx)
+	logger.WithFields(log.Fields{
+		"attempt":      state.restartAttempts + 1,
+		"max_attempts": MaxRestartAttempts,
+	}).Warn("Container is unhealthy")
 
 	// Check if we've exceeded max attempts
-	if state.restartAttempts >= MaxRestartAttempts {
-		log.Errorf("Container %s: giving up after %d restart attempts. Will retry when new version is available.", ctr.Name, MaxRestartAttempts)
+	if w.config.HealthAction != ActionRecreate && state.restartAttempts >= MaxRestartAttempts {
+		logger.WithFields(log.Fields{
+			logging.FieldAction: "give_up",
+			"attempts":          MaxRestartAttempts,
+		}).Error("Giving up on container. Will retry when new version is available.")
 		state.gaveUp = true
+		w.countGaveUp(ctr.Name)
 
 		// Send notification about giving up
 		if w.notifier != nil {
-			w.notifier.NotifyContainerGaveUp(ctr.Name, ctr.Image, MaxRestartAttempts)
+			w.notifier.NotifyContainerGaveUp(ctr.Name, ctr.Image, ActionRestart, MaxRestartAttempts, contact(ctr))
 		}
 		return
 	}
 
+	// A soft fix is tried once before escalating to a restart or recreate
+	if cmd := ctr.HealthExec(); cmd != "" && !state.execTried && w.config.HealthAction != ActionNotify {
+		state.execTried = true
+		w.execUnhealthy(ctx, ctr, cmd)
+		return
+	}
+
 	switch w.config.HealthAction {
-	case "restart":
+	case ActionRestart:
+		if w.quiet.Contains(w.clock.Now()) {
+			logger.WithField("quiet_hours", w.quiet.String()).Debug("Restart deferred until quiet hours end")
+			return
+		}
+
 		state.restartAttempts++
-		log.Infof("Restarting unhealthy container %s (attempt %d/%d)", ctr.Name, state.restartAttempts, MaxRestartAttempts)
+		rlog := logger.WithFields(log.Fields{
+			logging.FieldAction: "restart",
+			"attempt":           state.restartAttempts,
+			"max_attempts":      MaxRestartAttempts,
+		})
+		rlog.Info("Restarting unhealthy container")
+
+		dependents := w.dependents(ctx, ctr)
 
 		// Send notification about unhealthy state
 		if w.notifier != nil {
-			w.notifier.NotifyContainerUnhealthy(ctr.Name, ctr.Image, state.restartAttempts)
+			w.notifier.NotifyContainerUnhealthy(ctr.Name, ctr.Image, state.restartAttempts, contact(ctr), names(dependents))
 		}
 
 		timeout := ctr.GetStopTimeout(w.config.StopTimeout)
-		if err := w.client.RestartContainer(ctx, ctr.ID, timeout); err != nil {
-			log.Errorf("Failed to restart unhealthy container %s: %v", ctr.Name, err)
+		if err := w.client.RestartContainer(ctx, ctr.ID, timeout, ctr.GetStopSignal()); err != nil {
+			rlog.WithError(err).Error("Failed to restart unhealthy container")
 		} else {
-			log.Infof("Restart initiated for container %s", ctr.Name)
+			rlog.Info("Restart initiated")
+			w.countRestart()
+			w.restartDependents(ctx, dependents, "")
 		}
 
-	case "notify":
-		log.Infof("Notifying about unhealthy container %s (attempt %d/%d)", ctr.Name, state.restartAttempts+1, MaxRestartAttempts)
+	case ActionRecreate:
+		w.recreateUnhealthy(ctx, ctr, state)
+
+	case ActionNotify:
+		logger.WithFields(log.Fields{
+			logging.FieldAction: "notify",
+			"attempt":           state.restartAttempts + 1,
+			"max_attempts":      MaxRestartAttempts,
+		}).Info("Notifying about unhealthy container")
 		state.restartAttempts++
 
 		// Send notification
 		if w.notifier != nil {
-			w.notifier.NotifyContainerUnhealthy(ctr.Name, ctr.Image, state.restartAttempts)
+			w.notifier.NotifyContainerUnhealthy(ctr.Name, ctr.Image, state.restartAttempts, contact(ctr), nil)
 		}
 
 	default:
-		log.Debugf("No action configured for unhealthy container %s", ctr.Name)
+		logger.Debug("No action configured for unhealthy container")
+	}
+}
+
+// dependents returns the running containers to restart after ctr, which
+// would keep dead connections to it otherwise
+func (w *Watcher) dependents(ctx context.Context, ctr docker.Container) []docker.Container {
+	containers, err := w.client.ListContainers(ctx, docker.ListOptions{})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to list containers for dependent restarts")
+		return nil
+	}
+
+	var dependents []docker.Container
+	for _, other := range containers {
+		if other.IsRunning() && other.RestartsWith(ctr) {
+			dependents = append(dependents, other)
+		}
 	}
+	return dependents
 }
 
-// ResetContainer resets tracking for a container (called when container is updated)
-func (w *Watcher) ResetContainer(containerID string) {
+// restartDependents restarts the dependents of a restarted container, in
+// order, with their own stop timeout and signal. After a recreate, newID is
+// the ID of the replacement: dependents sharing the network stack of the
+// removed container are recreated to join the new one's, since theirs is
+// gone.
+func (w *Watcher) restartDependents(ctx context.Context, dependents []docker.Container, newID string) {
+	for _, dep := range dependents {
+		dlog := logging.FromContext(ctx).WithFields(log.Fields{
+			logging.FieldAction: "restart_dependent",
+			"dependent":         dep.Name,
+		})
+		timeout := dep.GetStopTimeout(w.config.StopTimeout)
+
+		if newID != "" && dep.NetworkContainer() != "" {
+			if _, err := w.client.RecreateContainer(ctx, dep.ID, docker.RecreateOptions{
+				StopTimeout:      timeout,
+				NetworkContainer: newID,
+			}); err != nil {
+				dlog.WithError(err).Error("Failed to recreate dependent container")
+				continue
+			}
+			dlog.Info("Recreated dependent container")
+			continue
+		}
+
+		if err := w.client.RestartContainer(ctx, dep.ID, timeout, dep.GetStopSignal()); err != nil {
+			dlog.WithError(err).Error("Failed to restart dependent container")
+			continue
+		}
+		dlog.Info("Restarted dependent container")
+	}
+}
+
+// execUnhealthy runs the dockwarden.health.exec command inside an unhealthy
+// container with /bin/sh -c. The next check escalates if the container is
+// still unhealthy.
+func (w *Watcher) execUnhealthy(ctx context.Context, ctr docker.Container, cmd string) {
+	elog := logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldAction: "exec",
+		"command":           cmd,
+	})
+	elog.Info("Running health exec command in unhealthy container")
+
+	ctx, cancel := context.WithTimeout(ctx, ExecTimeout)
+	defer cancel()
+	result, err := w.client.Exec(ctx, ctr.ID, []string{"/bin/sh", "-c", cmd})
+	if err != nil {
+		elog.WithError(err).Error("Failed to run health exec command")
+		return
+	}
+	if result.ExitCode != 0 {
+		elog.WithFields(log.Fields{
+			"exit_code": result.ExitCode,
+			"stderr":    result.Stderr,
+		}).Warn("Health exec command failed")
+		return
+	}
+	elog.Info("Health exec command succeeded, escalating if the container stays unhealthy")
+}
+
+// recreateUnhealthy removes an unhealthy container and creates it again
+// from its configuration with the same image, pulling it first if
+// configured. Recreates have their own attempt counter.
+func (w *Watcher) recreateUnhealthy(ctx context.Context, ctr docker.Container, state *containerState) {
+	logger := logging.FromContext(ctx)
+	if state.recreateAttempts >= MaxRecreateAttempts {
+		logger.WithFields(log.Fields{
+			logging.FieldAction: "give_up",
+			"attempts":          MaxRecreateAttempts,
+		}).Error("Giving up on container. Will retry when new version is available.")
+		state.gaveUp = true
+		w.countGaveUp(ctr.Name)
+
+		if w.notifier != nil {
+			w.notifier.NotifyContainerGaveUp(ctr.Name, ctr.Image, ActionRecreate, MaxRecreateAttempts, contact(ctr))
+		}
+		return
+	}
+	if w.quiet.Contains(w.clock.Now()) {
+		logger.WithField("quiet_hours", w.quiet.String()).Debug("Recreate deferred until quiet hours end")
+		return
+	}
+
+	// An update cycle may be replacing the container right now; try again
+	// on the next check, when the container may have been updated
+	if w.lockCycle != nil {
+		unlock, err := w.lockCycle(ctx)
+		if err != nil {
+			logger.WithError(err).Info("Recreate deferred while an update cycle runs")
+			return
+		}
+		defer unlock()
+		if _, err := w.client.GetContainer(ctx, ctr.ID); err != nil {
+			logger.WithError(err).Debug("Container changed before it was recreated, skipping")
+			return
+		}
+	}
+
+	state.recreateAttempts++
+	rlog := logger.WithFields(log.Fields{
+		logging.FieldAction: "recreate",
+		"attempt":           state.recreateAttempts,
+		"max_attempts":      MaxRecreateAttempts,
+	})
+	rlog.Info("Recreating unhealthy container")
+
+	// Listed before the recreate: the container's ID changes with it
+	dependents := w.dependents(ctx, ctr)
+
+	if w.notifier != nil {
+		w.notifier.NotifyContainerUnhealthy(ctr.Name, ctr.Image, state.recreateAttempts, contact(ctr), names(dependents))
+	}
+
+	if w.config.HealthRecreatePull {
+		if local, err := w.client.IsLocalImage(ctx, ctr.Image); err == nil && !local {
+			if _, err := w.client.PullImage(ctx, ctr.Image); err != nil {
+				rlog.WithError(err).Warn("Failed to pull image, recreating from the local one")
+			}
+		}
+	}
+
+	newID, err := w.client.RecreateContainer(ctx, ctr.ID, docker.RecreateOptions{
+		StopTimeout: ctr.GetStopTimeout(w.config.StopTimeout),
+		Image:       ctr.Image,
+	})
+	if err != nil {
+		rlog.WithError(err).Error("Failed to recreate unhealthy container")
+		return
+	}
+	rlog.WithField("new_id", shortID(newID)).Info("Container recreated")
+	w.countRestart()
+	w.restartDependents(ctx, dependents, newID)
+}
+
+// ResetContainer resets tracking for a container identity (called when the
+// container is updated)
+func (w *Watcher) ResetContainer(identity string) {
 	w.statesMu.Lock()
 	defer w.statesMu.Unlock()
 
-	if state, ok := w.states[containerID]; ok {
+	if state, ok := w.states[identity]; ok {
 		state.mu.Lock()
 		state.restartAttempts = 0
+		state.recreateAttempts = 0
+		state.execTried = false
 		state.gaveUp = false
 		state.lastImageID = ""
 		state.mu.Unlock()
@@ -249,8 +603,71 @@ func (w *Watcher) GetStats() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"monitored_containers": monitored,
-		"gave_up_containers":   gaveUp,
-		"max_restart_attempts": MaxRestartAttempts,
+		"monitored_containers":  monitored,
+		"gave_up_containers":    gaveUp,
+		"max_restart_attempts":  MaxRestartAttempts,
+		"max_recreate_attempts": MaxRecreateAttempts,
+	}
+}
+
+// RestartStatus is the health restart tracking of a single container
+type RestartStatus struct {
+	Name         string
+	Identity     string
+	Image        string
+	Attempts     int
+	Recreates    int
+	GaveUp       bool
+	CrashLooping bool
+}
+
+// RestartStatuses returns the restart tracking of monitored containers
+// sorted by name
+func (w *Watcher) RestartStatuses() []RestartStatus {
+	w.statesMu.RLock()
+	defer w.statesMu.RUnlock()
+
+	statuses := make([]RestartStatus, 0, len(w.states))
+	for identity, state := range w.states {
+		state.mu.Lock()
+		if state.name != "" {
+			statuses = append(statuses, RestartStatus{
+				Name:         state.name,
+				Identity:     identity,
+				Image:        state.image,
+				Attempts:     state.restartAttempts,
+				Recreates:    state.recreateAttempts,
+				GaveUp:       state.gaveUp,
+				CrashLooping: state.crashLooping,
+			})
+		}
+		state.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// contact returns who to reach about a container, for notifications
+func contact(ctr docker.Container) notify.Contact {
+	return notify.Contact{
+		Owner: ctr.GetLabel(docker.OwnerLabel),
+		Notes: ctr.GetLabel(docker.NotesLabel),
+	}
+}
+
+// names returns the names of containers
+func names(containers []docker.Container) []string {
+	result := make([]string, len(containers))
+	for i, ctr := range containers {
+		result[i] = ctr.Name
+	}
+	return result
+}
+
+// shortID truncates a container ID for log output
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
 	}
+	return id
 }
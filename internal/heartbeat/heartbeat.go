@@ -0,0 +1,126 @@
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/meta"
+	"github.com/emon5122/dockwarden/internal/redact"
+)
+
+// Pinger reports update cycles to a dead man's switch such as
+// healthchecks.io or an Uptime Kuma push monitor, so monitoring alerts when
+// DockWarden silently stops running. A nil Pinger does nothing.
+type Pinger struct {
+	url    string
+	client *http.Client
+}
+
+// New creates a Pinger for the given heartbeat URL, or nil when url is empty
+func New(url string) *Pinger {
+	if url == "" {
+		return nil
+	}
+	return &Pinger{
+		url: strings.TrimSpace(url),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Start signals that a cycle has started
+func (p *Pinger) Start(ctx context.Context) {
+	// Push monitors only know up and down
+	if p == nil || p.isUptimeKuma() {
+		return
+	}
+	p.ping(ctx, "start", p.withSuffix("/start"))
+}
+
+// Success signals that a cycle completed
+func (p *Pinger) Success(ctx context.Context, msg string) {
+	if p == nil {
+		return
+	}
+	if p.isUptimeKuma() {
+		p.ping(ctx, "success", p.withStatus("up", msg))
+		return
+	}
+	p.ping(ctx, "success", p.url)
+}
+
+// Fail signals that a cycle failed
+func (p *Pinger) Fail(ctx context.Context, msg string) {
+	if p == nil {
+		return
+	}
+	if p.isUptimeKuma() {
+		p.ping(ctx, "fail", p.withStatus("down", msg))
+		return
+	}
+	p.ping(ctx, "fail", p.withSuffix("/fail"))
+}
+
+// isUptimeKuma reports whether the URL is an Uptime Kuma push monitor
+func (p *Pinger) isUptimeKuma() bool {
+	return strings.Contains(p.url, "/api/push/")
+}
+
+// withSuffix appends a path suffix (healthchecks.io style), keeping any query
+func (p *Pinger) withSuffix(suffix string) string {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return p.url
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + suffix
+	return u.String()
+}
+
+// withStatus sets the Uptime Kuma status and message query parameters
+func (p *Pinger) withStatus(status, msg string) string {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return p.url
+	}
+	q := u.Query()
+	q.Set("status", status)
+	q.Set("msg", msg)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// ping sends the heartbeat request; failures are logged and never fail a cycle
+func (p *Pinger) ping(ctx context.Context, kind, target string) {
+	logger := logging.FromContext(ctx).WithField("heartbeat", kind)
+
+	if err := p.get(ctx, target); err != nil {
+		logger.WithError(err).Warn("Failed to ping heartbeat URL")
+		return
+	}
+	logger.Debug("Heartbeat sent")
+}
+
+func (p *Pinger) get(ctx context.Context, target string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create heartbeat request: %w", err)
+	}
+	req.Header.Set("User-Agent", "DockWarden/"+meta.Version)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send heartbeat: %w", redact.Error(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("heartbeat URL returned status %d", resp.StatusCode)
+	}
+	return nil
+}
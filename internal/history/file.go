@@ -0,0 +1,196 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// File names in the data directory
+const (
+	historyFile = "history.jsonl"
+	sbomDir     = "sbom"
+)
+
+// fileBackend appends records to history.jsonl in a directory and stores
+// SBOMs next to it, so the history survives restarts
+type fileBackend struct {
+	dir string
+}
+
+func newFileBackend(dir string) (*fileBackend, error) {
+	if err := os.MkdirAll(filepath.Join(dir, sbomDir), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	return &fileBackend{dir: dir}, nil
+}
+
+func (f *fileBackend) Load(ctx context.Context) ([]Record, error) {
+	file, err := os.Open(filepath.Join(f.dir, historyFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return records, nil
+}
+
+func (f *fileBackend) Append(ctx context.Context, r Record, sbom []byte) error {
+	if len(sbom) > 0 {
+		if err := os.WriteFile(f.sbomPath(r.ID), sbom, 0o644); err != nil {
+			return fmt.Errorf("failed to store SBOM: %w", err)
+		}
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(f.dir, historyFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Remove rewrites history.jsonl without the removed records, atomically, and
+// deletes their SBOMs
+func (f *fileBackend) Remove(ctx context.Context, ids []string) error {
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+	records, err := f.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		if remove[r.ID] {
+			continue
+		}
+		line, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode history record: %w", err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	path := filepath.Join(f.dir, historyFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace history: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := os.Remove(f.sbomPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove SBOM: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f *fileBackend) SBOM(ctx context.Context, id string) ([]byte, error) {
+	data, err := os.ReadFile(f.sbomPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM: %w", err)
+	}
+	return data, nil
+}
+
+func (f *fileBackend) Shared() bool {
+	return false
+}
+
+func (f *fileBackend) sbomPath(id string) string {
+	return filepath.Join(f.dir, sbomDir, id+".json")
+}
+
+// memoryBackend keeps records and SBOMs in memory only
+type memoryBackend struct {
+	mu      sync.Mutex
+	records []Record
+	sboms   map[string][]byte
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{sboms: make(map[string][]byte)}
+}
+
+func (m *memoryBackend) Load(ctx context.Context) ([]Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.records), nil
+}
+
+func (m *memoryBackend) Append(ctx context.Context, r Record, sbom []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records = append(m.records, r)
+	if len(sbom) > 0 {
+		m.sboms[r.ID] = sbom
+	}
+	return nil
+}
+
+func (m *memoryBackend) Remove(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+		delete(m.sboms, id)
+	}
+	m.records = slices.DeleteFunc(m.records, func(r Record) bool { return remove[r.ID] })
+	return nil
+}
+
+func (m *memoryBackend) SBOM(ctx context.Context, id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if sbom, ok := m.sboms[id]; ok {
+		return sbom, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (m *memoryBackend) Shared() bool {
+	return false
+}
@@ -0,0 +1,110 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/emon5122/dockwarden/internal/redis"
+)
+
+// Redis keys of the history: a list of JSON records, oldest first, and one
+// key per SBOM
+const (
+	redisHistoryKey = "dockwarden:history"
+	redisSBOMPrefix = "dockwarden:history:sbom:"
+)
+
+// Lua scripts keep a record and its SBOM consistent: redisAppend pushes a
+// record and stores its SBOM, redisRemove drops records by ID along with
+// their SBOMs
+const (
+	redisAppend = `redis.call('rpush', KEYS[1], ARGV[1])
+if ARGV[3] ~= '' then redis.call('set', ARGV[4] .. ARGV[2], ARGV[3]) end
+return 1`
+	redisRemove = `local remove = {}
+for i = 2, #ARGV do remove[ARGV[i]] = true end
+local removed = 0
+for _, item in ipairs(redis.call('lrange', KEYS[1], 0, -1)) do
+  local id = cjson.decode(item).id
+  if remove[id] then
+    redis.call('lrem', KEYS[1], 1, item)
+    redis.call('del', ARGV[1] .. id)
+    removed = removed + 1
+  end
+end
+return removed`
+)
+
+// redisBackend keeps the history in Redis, shared by every instance using
+// the same database
+type redisBackend struct {
+	client *redis.Client
+}
+
+func newRedisBackend(u *url.URL) (*redisBackend, error) {
+	client, err := redis.New(u)
+	if err != nil {
+		return nil, fmt.Errorf("invalid history URL: %w", err)
+	}
+	return &redisBackend{client: client}, nil
+}
+
+func (b *redisBackend) Load(ctx context.Context) ([]Record, error) {
+	reply, err := b.client.Do(ctx, "LRANGE", redisHistoryKey, "0", "-1")
+	if err != nil {
+		return nil, err
+	}
+	items, _ := reply.([]any)
+	records := make([]Record, 0, len(items))
+	for _, item := range items {
+		line, ok := item.(string)
+		if !ok {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (b *redisBackend) Append(ctx context.Context, r Record, sbom []byte) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+	_, err = b.client.Do(ctx, "EVAL", redisAppend, "1", redisHistoryKey,
+		string(line), r.ID, string(sbom), redisSBOMPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBackend) Remove(ctx context.Context, ids []string) error {
+	args := append([]string{"EVAL", redisRemove, "1", redisHistoryKey, redisSBOMPrefix}, ids...)
+	if _, err := b.client.Do(ctx, args...); err != nil {
+		return fmt.Errorf("failed to remove history records: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBackend) SBOM(ctx context.Context, id string) ([]byte, error) {
+	reply, err := b.client.Do(ctx, "GET", redisSBOMPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM: %w", err)
+	}
+	sbom, ok := reply.(string)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return []byte(sbom), nil
+}
+
+func (b *redisBackend) Shared() bool {
+	return true
+}
@@ -0,0 +1,271 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	log "github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteFile is the SQLite database of the history in the data directory
+const sqliteFile = "history.db"
+
+// sqlDialect holds what differs between the SQL databases
+type sqlDialect struct {
+	driver string
+	// schema creates the history table when it doesn't exist
+	schema string
+	// numbered placeholders ($1, $2, ...) instead of ?
+	numbered bool
+	shared   bool
+}
+
+var (
+	sqliteDialect = sqlDialect{
+		driver: "sqlite",
+		schema: `CREATE TABLE IF NOT EXISTS dockwarden_history (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	id TEXT NOT NULL UNIQUE,
+	recorded_at INTEGER NOT NULL,
+	record TEXT NOT NULL,
+	sbom BLOB
+)`,
+	}
+	postgresDialect = sqlDialect{
+		driver: "pgx",
+		schema: `CREATE TABLE IF NOT EXISTS dockwarden_history (
+	seq BIGSERIAL PRIMARY KEY,
+	id TEXT NOT NULL UNIQUE,
+	recorded_at BIGINT NOT NULL,
+	record TEXT NOT NULL,
+	sbom BYTEA
+)`,
+		numbered: true,
+		shared:   true,
+	}
+)
+
+// sqlBackend keeps the history in the dockwarden_history table of a SQLite
+// or PostgreSQL database. Records are stored as JSON like in the other
+// backends, next to their SBOM.
+type sqlBackend struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+// newSQLiteBackend opens or creates the SQLite database at path
+func newSQLiteBackend(path string) (*sqlBackend, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open(sqliteDialect.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	// SQLite allows a single writer
+	db.SetMaxOpenConns(1)
+	return openSQLBackend(db, sqliteDialect)
+}
+
+// newPostgresBackend connects to the PostgreSQL database of a DSN URL
+func newPostgresBackend(dsn string) (*sqlBackend, error) {
+	db, err := sql.Open(postgresDialect.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	return openSQLBackend(db, postgresDialect)
+}
+
+// openSQLBackend connects to db and creates the history table
+func openSQLBackend(db *sql.DB, dialect sqlDialect) (*sqlBackend, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+	if _, err := db.ExecContext(ctx, dialect.schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to prepare history database: %w", err)
+	}
+	return &sqlBackend{db: db, dialect: dialect}, nil
+}
+
+// checkSQLURL validates a sqlite:// or postgres:// history URL
+func checkSQLURL(u *url.URL) error {
+	switch u.Scheme {
+	case "sqlite":
+		if sqlitePath(u) == "" {
+			return errors.New("invalid history URL: sqlite:// needs a database path, e.g. sqlite:///var/lib/dockwarden/history.db")
+		}
+	default:
+		if _, err := pgx.ParseConfig(u.String()); err != nil {
+			return fmt.Errorf("invalid history URL: %w", err)
+		}
+	}
+	return nil
+}
+
+// sqlitePath returns the database path of a sqlite:// URL
+func sqlitePath(u *url.URL) string {
+	return u.Host + u.Path
+}
+
+// query rewrites the ? placeholders of q for the dialect
+func (b *sqlBackend) query(q string) string {
+	if !b.dialect.numbered {
+		return q
+	}
+	var sb strings.Builder
+	n := 0
+	for _, c := range q {
+		if c == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+func (b *sqlBackend) Load(ctx context.Context) ([]Record, error) {
+	rows, err := b.db.QueryContext(ctx, "SELECT record FROM dockwarden_history ORDER BY seq")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(data), &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return records, nil
+}
+
+func (b *sqlBackend) Append(ctx context.Context, r Record, sbom []byte) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+	var blob any
+	if len(sbom) > 0 {
+		blob = sbom
+	}
+	_, err = b.db.ExecContext(ctx,
+		b.query("INSERT INTO dockwarden_history (id, recorded_at, record, sbom) VALUES (?, ?, ?, ?)"),
+		r.ID, r.Time.UnixMilli(), string(data), blob)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+func (b *sqlBackend) Remove(ctx context.Context, ids []string) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove history records: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, b.query("DELETE FROM dockwarden_history WHERE id = ?"))
+	if err != nil {
+		return fmt.Errorf("failed to remove history records: %w", err)
+	}
+	defer stmt.Close()
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, id); err != nil {
+			return fmt.Errorf("failed to remove history records: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to remove history records: %w", err)
+	}
+	return nil
+}
+
+func (b *sqlBackend) SBOM(ctx context.Context, id string) ([]byte, error) {
+	var sbom []byte
+	err := b.db.QueryRowContext(ctx, b.query("SELECT sbom FROM dockwarden_history WHERE id = ?"), id).Scan(&sbom)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM: %w", err)
+	}
+	if len(sbom) == 0 {
+		return nil, ErrNotFound
+	}
+	return sbom, nil
+}
+
+func (b *sqlBackend) Shared() bool {
+	return b.dialect.shared
+}
+
+// empty reports whether the history table has no records
+func (b *sqlBackend) empty(ctx context.Context) (bool, error) {
+	var n int
+	if err := b.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dockwarden_history").Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to read history: %w", err)
+	}
+	return n == 0, nil
+}
+
+// importFileHistory moves the records and SBOMs of a history.jsonl in dir,
+// written before SQLite became the default, into b. The file is renamed
+// once imported, so it is imported once.
+func importFileHistory(dir string, b *sqlBackend) error {
+	path := filepath.Join(dir, historyFile)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	// A local database, the import isn't bounded like a single call
+	ctx := context.Background()
+	empty, err := b.empty(ctx)
+	if err != nil || !empty {
+		return err
+	}
+	file := &fileBackend{dir: dir}
+	records, err := file.Load(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		var sbom []byte
+		if r.SBOM != "" {
+			sbom, _ = file.SBOM(ctx, r.ID)
+		}
+		if err := b.Append(ctx, r, sbom); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(path, path+".imported"); err != nil {
+		return fmt.Errorf("failed to rename imported history: %w", err)
+	}
+	log.WithFields(log.Fields{
+		"records":  len(records),
+		"database": filepath.Join(dir, sqliteFile),
+	}).Info("Imported update history into SQLite")
+	return nil
+}
@@ -0,0 +1,118 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteBackend(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir, Options{MaxRecords: 2})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	first, err := store.Add(Record{Container: "web", Image: "nginx:1.27", SBOM: "spdx"}, []byte(`{"spdxVersion":"SPDX-2.3"}`))
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	second, err := store.Add(Record{Container: "db", Image: "postgres:17"}, nil)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	sbom, err := store.SBOM(first.ID)
+	if err != nil || string(sbom) != `{"spdxVersion":"SPDX-2.3"}` {
+		t.Errorf("SBOM() = %q, %v", sbom, err)
+	}
+	if _, err := store.SBOM(second.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SBOM() of a record without one: error = %v, want ErrNotFound", err)
+	}
+
+	// Reopened from the database, the oldest record is pruned beyond the
+	// maximum along with its SBOM
+	if _, err := store.Add(Record{Container: "cache", Image: "redis:7", Time: time.Now().Add(time.Second)}, nil); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	reopened, err := Open(dir, Options{MaxRecords: 2})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	recent := reopened.Recent(10, nil)
+	if len(recent) != 2 || recent[0].Container != "cache" || recent[1].Container != "db" {
+		t.Fatalf("Recent() = %+v, want cache and db", recent)
+	}
+	if _, err := reopened.backend.SBOM(context.Background(), first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SBOM of a pruned record: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSQLiteImportsFileHistory(t *testing.T) {
+	dir := t.TempDir()
+	file, err := newFileBackend(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	old := Record{ID: "a1b2c3", Time: time.Now().Add(-time.Hour), Container: "web", Image: "nginx:1.26", SBOM: "cyclonedx"}
+	if err := file.Append(ctx, old, []byte(`{"bomFormat":"CycloneDX"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	r, err := store.Get(old.ID)
+	if err != nil || r.Image != old.Image {
+		t.Fatalf("Get() = %+v, %v", r, err)
+	}
+	if sbom, err := store.SBOM(old.ID); err != nil || string(sbom) != `{"bomFormat":"CycloneDX"}` {
+		t.Errorf("SBOM() = %q, %v", sbom, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, historyFile+".imported")); err != nil {
+		t.Errorf("imported history wasn't renamed: %v", err)
+	}
+
+	// Imported once
+	if _, err := Open(dir, Options{}); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := len(store.Recent(10, nil)); got != 1 {
+		t.Errorf("Recent() returned %d records, want 1", got)
+	}
+}
+
+func TestSQLQueryPlaceholders(t *testing.T) {
+	b := &sqlBackend{dialect: postgresDialect}
+	got := b.query("INSERT INTO t (a, b) VALUES (?, ?)")
+	if want := "INSERT INTO t (a, b) VALUES ($1, $2)"; got != want {
+		t.Errorf("query() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"", false},
+		{"/var/lib/dockwarden", false},
+		{"file:///var/lib/dockwarden", false},
+		{"sqlite:///var/lib/dockwarden/history.db", false},
+		{"sqlite://", true},
+		{"postgres://dockwarden:secret@db:5432/dockwarden?sslmode=disable", false},
+		{"postgresql://db/dockwarden", false},
+		{"redis://:secret@redis:6379/0", false},
+		{"mysql://db/dockwarden", true},
+	}
+	for _, tt := range tests {
+		if err := CheckURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("CheckURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/distribution/reference"
+)
+
+// ImageStats aggregate the recorded updates of one image repository
+type ImageStats struct {
+	// Repository is the image without tag or digest, e.g. nginx or
+	// ghcr.io/owner/app
+	Repository  string
+	Updates     int
+	Containers  int
+	FirstUpdate time.Time
+	LastUpdate  time.Time
+	// AverageInterval is the mean time between two updates of the same
+	// container, 0 until a container was updated twice
+	AverageInterval time.Duration
+	// AverageBehind is the mean time from the creation of an image until it
+	// was deployed, 0 when no record carries the image creation time
+	AverageBehind time.Duration
+}
+
+// ImageStats aggregates the records accepted by match per image repository,
+// the most frequently updated repositories first
+func (s *Store) ImageStats(match func(Record) bool) []ImageStats {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type aggregate struct {
+		stats     ImageStats
+		last      map[string]time.Time
+		intervals int
+		interval  time.Duration
+		behindN   int
+		behindSum time.Duration
+	}
+	repos := make(map[string]*aggregate)
+
+	// Records are stored oldest first
+	for _, r := range s.records {
+		if match != nil && !match(r) {
+			continue
+		}
+		repo := Repository(r.Image)
+		agg, ok := repos[repo]
+		if !ok {
+			agg = &aggregate{
+				stats: ImageStats{Repository: repo, FirstUpdate: r.Time},
+				last:  make(map[string]time.Time),
+			}
+			repos[repo] = agg
+		}
+
+		agg.stats.Updates++
+		if r.Time.Before(agg.stats.FirstUpdate) {
+			agg.stats.FirstUpdate = r.Time
+		}
+		if r.Time.After(agg.stats.LastUpdate) {
+			agg.stats.LastUpdate = r.Time
+		}
+
+		if prev, ok := agg.last[r.Key()]; ok && r.Time.After(prev) {
+			agg.interval += r.Time.Sub(prev)
+			agg.intervals++
+		}
+		agg.last[r.Key()] = r.Time
+
+		if !r.ImageCreated.IsZero() && r.Time.After(r.ImageCreated) {
+			agg.behindSum += r.Time.Sub(r.ImageCreated)
+			agg.behindN++
+		}
+	}
+
+	result := make([]ImageStats, 0, len(repos))
+	for _, agg := range repos {
+		agg.stats.Containers = len(agg.last)
+		if agg.intervals > 0 {
+			agg.stats.AverageInterval = agg.interval / time.Duration(agg.intervals)
+		}
+		if agg.behindN > 0 {
+			agg.stats.AverageBehind = agg.behindSum / time.Duration(agg.behindN)
+		}
+		result = append(result, agg.stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Updates != result[j].Updates {
+			return result[i].Updates > result[j].Updates
+		}
+		return result[i].Repository < result[j].Repository
+	})
+	return result
+}
+
+// Repository returns the repository of an image reference without tag or
+// digest, in the short form docker shows (nginx rather than
+// docker.io/library/nginx). Unparsable references are returned unchanged.
+func Repository(image string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	return reference.FamiliarName(named)
+}
@@ -0,0 +1,134 @@
+// Package i18n translates dashboard strings and notification messages.
+// Locales are embedded JSON files mapping message keys to fmt format
+// strings; missing keys fall back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used when no locale is configured
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Translator looks up messages of one locale
+type Translator struct {
+	locale   string
+	messages map[string]string
+	fallback map[string]string
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]*Translator{}
+)
+
+// New returns the translator of a locale such as "de" or "pt-BR". A region
+// without its own file falls back to the language.
+func New(locale string) (*Translator, error) {
+	locale = normalize(locale)
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if t, ok := cache[locale]; ok {
+		return t, nil
+	}
+
+	fallback, err := load(DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	name := locale
+	messages, err := load(name)
+	if err != nil {
+		name, _, _ = strings.Cut(locale, "-")
+		messages, err = load(name)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported locale %q (available: %s)", locale, strings.Join(Available(), ", "))
+		}
+	}
+
+	t := &Translator{locale: name, messages: messages, fallback: fallback}
+	cache[locale] = t
+	return t, nil
+}
+
+// For returns the translator of a locale, or the English one when the
+// locale isn't available. Locales are validated at startup with New.
+func For(locale string) *Translator {
+	if t, err := New(locale); err == nil {
+		return t
+	}
+	t, _ := New(DefaultLocale)
+	return t
+}
+
+// Available lists the embedded locales
+func Available() []string {
+	entries, _ := fs.ReadDir(locales, "locales")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Locale returns the locale the messages come from
+func (t *Translator) Locale() string {
+	if t == nil {
+		return DefaultLocale
+	}
+	return t.locale
+}
+
+// T formats the message with the given key. Unknown keys are returned as is.
+// A nil Translator translates to English.
+func (t *Translator) T(key string, args ...interface{}) string {
+	if t == nil {
+		t = For(DefaultLocale)
+	}
+	format, ok := t.messages[key]
+	if !ok {
+		format, ok = t.fallback[key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func load(name string) (map[string]string, error) {
+	data, err := locales.ReadFile("locales/" + name + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse locale %s: %w", name, err)
+	}
+	return messages, nil
+}
+
+// normalize turns "de_DE.UTF-8" style locales into "de-de"
+func normalize(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		return DefaultLocale
+	}
+	locale, _, _ = strings.Cut(locale, ".")
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
@@ -0,0 +1,111 @@
+package lock
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Elector makes one of several redundant instances the leader by having
+// them compete for the same lock. The leader keeps the lock for as long as
+// it runs; standbys retry until it goes away.
+type Elector struct {
+	lock     *Lock
+	interval time.Duration
+	leader   atomic.Bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewElector creates an elector competing for l
+func NewElector(l *Lock) *Elector {
+	return &Elector{
+		lock:     l,
+		interval: l.ttl / 3,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// IsLeader reports whether this instance is the leader. Without an elector
+// every instance leads.
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	return e.leader.Load()
+}
+
+// Start campaigns for leadership once, so the outcome is known when it
+// returns, and keeps campaigning in the background until Stop
+func (e *Elector) Start() {
+	log.WithField("owner", e.lock.Owner()).Info("Standing for leader election")
+	e.campaign()
+
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.campaign()
+			case <-e.stop:
+				e.resign()
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the campaign and resigns leadership so a standby can take over
+// right away
+func (e *Elector) Stop() {
+	close(e.stop)
+	<-e.done
+}
+
+// campaign acquires the lock when it is free and steps down when the lease
+// was lost
+func (e *Elector) campaign() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+
+	if e.leader.Load() {
+		if e.lock.Held() {
+			return
+		}
+		e.leader.Store(false)
+		log.Warn("Lost leadership, now standby")
+		if err := e.lock.Unlock(ctx); err != nil {
+			log.WithError(err).Debug("Failed to release lost leader lock")
+		}
+	}
+
+	ok, err := e.lock.TryLock(ctx)
+	switch {
+	case err != nil:
+		log.WithError(err).Warn("Failed to reach leader election backend")
+	case ok:
+		e.leader.Store(true)
+		log.Info("Elected leader")
+	default:
+		log.Debug("Another instance is leader, staying standby")
+	}
+}
+
+// resign gives up leadership
+func (e *Elector) resign() {
+	if !e.leader.Swap(false) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+	if err := e.lock.Unlock(ctx); err != nil {
+		log.WithError(err).Warn("Failed to release leader lock")
+		return
+	}
+	log.Info("Resigned leadership")
+}
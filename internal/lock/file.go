@@ -0,0 +1,122 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fileBackend keeps each lease in <dir>/<key>.lock, holding the owner and
+// the expiry as Unix milliseconds. Files are created exclusively, so it
+// works on any shared volume without relying on flock support. Taking over
+// an expired lease is not atomic; two instances doing so at the same moment
+// could both believe they hold it, which only happens after a holder died.
+type fileBackend struct {
+	dir string
+}
+
+func (f *fileBackend) path(key string) string {
+	return filepath.Join(f.dir, key+".lock")
+}
+
+func (f *fileBackend) acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return false, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	path := f.path(key)
+	content := []byte(owner + " " + strconv.FormatInt(time.Now().Add(ttl).UnixMilli(), 10) + "\n")
+
+	holder, expires, err := readLease(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return false, err
+	case holder == owner:
+		// Renew our own lease
+		if err := writeLease(path, content); err != nil {
+			return false, err
+		}
+		return true, nil
+	case time.Now().Before(expires):
+		return false, nil
+	default:
+		// Expired lease of an instance that went away
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, fmt.Errorf("failed to remove expired lock: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create lock file: %w", err)
+	}
+	_, err = file.Write(content)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return false, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return true, nil
+}
+
+func (f *fileBackend) release(ctx context.Context, key, owner string) error {
+	path := f.path(key)
+	holder, _, err := readLease(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && holder != owner) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+// readLease returns the owner and expiry recorded in a lease file. A file
+// that cannot be parsed, e.g. one caught while being written, counts as held
+// until it is a minute old.
+func readLease(path string) (string, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", time.Time{}, err
+		}
+		return "", time.Time{}, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	owner, expiry, ok := strings.Cut(strings.TrimSpace(string(data)), " ")
+	ms, perr := strconv.ParseInt(expiry, 10, 64)
+	if !ok || perr != nil {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to read lock file: %w", err)
+		}
+		return "", info.ModTime().Add(time.Minute), nil
+	}
+	return owner, time.UnixMilli(ms), nil
+}
+
+// writeLease replaces a lease file atomically
+func writeLease(path string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
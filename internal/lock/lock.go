@@ -0,0 +1,195 @@
+// Package lock coordinates several DockWarden instances managing the same
+// hosts, so only one of them runs an update cycle at a time. A lock is a
+// lease with an owner and an expiry that is renewed while it is held; when
+// an instance dies its lease simply runs out.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultTTL is how long a lease lasts without being renewed
+const DefaultTTL = 15 * time.Minute
+
+// backend stores leases. acquire succeeds when the key is free, expired or
+// already held by owner, and (re)sets its expiry to ttl from now.
+type backend interface {
+	acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	release(ctx context.Context, key, owner string) error
+}
+
+// Name returns the lock name for kind, e.g. "cycle", in a scope. Each scope
+// has its own locks.
+func Name(kind, scope string) string {
+	if scope == "" {
+		return kind
+	}
+	return kind + "-" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, scope)
+}
+
+// Lock is a named lease shared by all instances using the same backend
+type Lock struct {
+	backend backend
+	key     string
+	owner   string
+	ttl     time.Duration
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+
+	// renewed is when the lease was last extended, in Unix nanoseconds, and
+	// lost is set when another instance took it over
+	renewed atomic.Int64
+	lost    atomic.Bool
+}
+
+// New creates the lock named name in the backend at rawURL:
+//
+//	file:///shared/locks        lease files in a directory on a shared volume
+//	redis://:password@host:6379/0
+//	rediss://host:6380          Redis over TLS
+//
+// A plain path is treated as a file URL.
+func New(rawURL, name string, ttl time.Duration) (*Lock, error) {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	var b backend
+	u, err := url.Parse(rawURL)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("invalid lock URL: %w", err)
+	case u.Scheme == "" || u.Scheme == "file":
+		dir := u.Path
+		if u.Scheme == "" {
+			dir = rawURL
+		}
+		b = &fileBackend{dir: dir}
+	case u.Scheme == "redis" || u.Scheme == "rediss":
+		b, err = newRedisBackend(u)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported lock URL scheme %q (use file, redis or rediss)", u.Scheme)
+	}
+
+	return &Lock{
+		backend: b,
+		key:     name,
+		owner:   newOwner(),
+		ttl:     ttl,
+	}, nil
+}
+
+// TryLock acquires the lock without waiting and reports whether it is held.
+// While held, the lease is renewed in the background until Unlock.
+func (l *Lock) TryLock(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stop != nil {
+		return false, fmt.Errorf("lock %s is already held by this instance", l.key)
+	}
+
+	ok, err := l.backend.acquire(ctx, l.key, l.owner, l.ttl)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	l.renewed.Store(time.Now().UnixNano())
+	l.lost.Store(false)
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go l.renew(l.stop, l.done)
+	return true, nil
+}
+
+// Unlock stops renewing the lease and releases it
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stop == nil {
+		return nil
+	}
+	close(l.stop)
+	<-l.done
+	l.stop, l.done = nil, nil
+
+	return l.backend.release(ctx, l.key, l.owner)
+}
+
+// Held reports whether this instance still holds the lease: it was acquired,
+// not taken over, and renewed within its TTL
+func (l *Lock) Held() bool {
+	l.mu.Lock()
+	acquired := l.stop != nil
+	l.mu.Unlock()
+
+	renewed := time.Unix(0, l.renewed.Load())
+	return acquired && !l.lost.Load() && time.Since(renewed) < l.ttl
+}
+
+// Owner identifies this instance as the holder of the lease
+func (l *Lock) Owner() string {
+	return l.owner
+}
+
+// renew extends the lease every third of its TTL
+func (l *Lock) renew(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			ok, err := l.backend.acquire(ctx, l.key, l.owner, l.ttl)
+			cancel()
+			logger := log.WithField("lock", l.key)
+			switch {
+			case err != nil:
+				logger.WithError(err).Warn("Failed to renew lock")
+			case !ok:
+				l.lost.Store(true)
+				logger.Error("Lock was taken over by another instance")
+			default:
+				l.renewed.Store(time.Now().UnixNano())
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// newOwner returns an identifier unique to this process
+func newOwner() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "dockwarden"
+	}
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%s/%d/%s", strings.ReplaceAll(host, " ", "_"), os.Getpid(), hex.EncodeToString(buf))
+}
@@ -0,0 +1,43 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/redis"
+)
+
+// Lua scripts keep check-and-set atomic on the server
+const (
+	redisAcquire = `if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('pexpire', KEYS[1], ARGV[2]) elseif redis.call('set', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then return 1 else return 0 end`
+	redisRelease = `if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('del', KEYS[1]) else return 0 end`
+)
+
+// redisBackend keeps leases as Redis keys with an expiry
+type redisBackend struct {
+	client *redis.Client
+}
+
+func newRedisBackend(u *url.URL) (*redisBackend, error) {
+	client, err := redis.New(u)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock URL: %w", err)
+	}
+	return &redisBackend{client: client}, nil
+}
+
+func (r *redisBackend) acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	reply, err := r.client.Do(ctx, "EVAL", redisAcquire, "1", "dockwarden:lock:"+key, owner, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	return reply == int64(1), nil
+}
+
+func (r *redisBackend) release(ctx context.Context, key, owner string) error {
+	_, err := r.client.Do(ctx, "EVAL", redisRelease, "1", "dockwarden:lock:"+key, owner)
+	return err
+}
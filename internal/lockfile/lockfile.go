@@ -0,0 +1,159 @@
+// Package lockfile records the image digest deployed to each container in a
+// JSON file, so a host can be rebuilt with exactly the images it ran.
+package lockfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// version is the format version written to the lockfile
+const version = 1
+
+// Entry pins the image of a container
+type Entry struct {
+	Container string `json:"container"`
+	// Image is the reference the container was created with, e.g. nginx:1.27
+	Image   string    `json:"image"`
+	Digest  string    `json:"digest"`
+	ImageID string    `json:"image_id,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// pins reports whether e pins the same image as the entry
+func (e Entry) pins(other Entry) bool {
+	return e.Container == other.Container && e.Image == other.Image && e.Digest == other.Digest && e.ImageID == other.ImageID
+}
+
+// document is the JSON layout of the lockfile
+type document struct {
+	Version    int              `json:"version"`
+	Updated    time.Time        `json:"updated"`
+	Containers map[string]Entry `json:"containers"`
+}
+
+// Lockfile holds the pinned images keyed by container identity and writes
+// every change through to its file
+type Lockfile struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Entry
+}
+
+// Open loads the lockfile at path. A missing file is created on the first
+// change.
+func Open(path string) (*Lockfile, error) {
+	l := &Lockfile{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile: %w", err)
+	}
+	if doc.Version > version {
+		return nil, fmt.Errorf("unsupported lockfile version %d", doc.Version)
+	}
+	if doc.Containers != nil {
+		l.entries = doc.Containers
+	}
+	return l, nil
+}
+
+// Path returns the file the lockfile is stored in
+func (l *Lockfile) Path() string {
+	return l.path
+}
+
+// Get returns the entry of a container
+func (l *Lockfile) Get(key string) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	return e, ok
+}
+
+// Entries returns a copy of all entries keyed by container identity
+func (l *Lockfile) Entries() map[string]Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return maps.Clone(l.entries)
+}
+
+// Set stores the entry of a container and writes the lockfile. Unchanged
+// entries are not written again.
+func (l *Lockfile) Set(key string, e Entry) error {
+	if e.Updated.IsZero() {
+		e.Updated = time.Now().UTC()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if old, ok := l.entries[key]; ok && old.pins(e) {
+		return nil
+	}
+	prev, had := l.entries[key]
+	l.entries[key] = e
+	if err := l.save(); err != nil {
+		if had {
+			l.entries[key] = prev
+		} else {
+			delete(l.entries, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the lockfile atomically: a crash leaves either the old or the
+// new file, never a partial one
+func (l *Lockfile) save() error {
+	data, err := json.MarshalIndent(document{
+		Version:    version,
+		Updated:    time.Now().UTC(),
+		Containers: l.entries,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+
+	dir := filepath.Dir(l.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create lockfile directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".dockwarden.lock-*")
+	if err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("failed to replace lockfile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/redact"
+	"github.com/mattn/go-isatty"
+	log "github.com/sirupsen/logrus"
+)
+
+// ANSI color codes used by the pretty formatter
+const (
+	colorReset  = "\x1b[0m"
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorCyan   = "\x1b[36m"
+	colorGray   = "\x1b[90m"
+)
+
+// messageWidth is the column the fields start at, so they line up across lines
+const messageWidth = 44
+
+// leadingFields are printed first (in this order) when present, since they
+// identify what a line is about; all other fields follow alphabetically
+var leadingFields = []string{FieldCycleID, FieldContainer, FieldAction}
+
+// PrettyFormatter renders human-friendly, colorized console output with
+// level colors and aligned key=value fields
+type PrettyFormatter struct {
+	// TimestampFormat defaults to "15:04:05" when empty
+	TimestampFormat string
+	// DisableColors prints the same layout without ANSI escapes
+	DisableColors bool
+}
+
+// Format implements logrus.Formatter
+func (f *PrettyFormatter) Format(entry *log.Entry) ([]byte, error) {
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+
+	tsFormat := f.TimestampFormat
+	if tsFormat == "" {
+		tsFormat = "15:04:05"
+	}
+
+	f.write(b, colorDim, entry.Time.Format(tsFormat))
+	b.WriteByte(' ')
+	f.write(b, levelColor(entry.Level), levelText(entry.Level))
+	b.WriteByte(' ')
+
+	b.WriteString(entry.Message)
+
+	keys := sortedKeys(entry.Data)
+	if len(keys) > 0 {
+		if pad := messageWidth - len(entry.Message); pad > 0 {
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+		for _, k := range keys {
+			b.WriteByte(' ')
+			color := colorCyan
+			if k == log.ErrorKey {
+				color = colorRed
+			}
+			f.write(b, color, k)
+			f.write(b, colorGray, "=")
+			b.WriteString(formatValue(entry.Data[k]))
+		}
+	}
+
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// write appends s to b, wrapped in the given color unless colors are disabled
+func (f *PrettyFormatter) write(b *bytes.Buffer, color, s string) {
+	if f.DisableColors {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(color)
+	b.WriteString(s)
+	b.WriteString(colorReset)
+}
+
+// levelText returns a fixed-width level label
+func levelText(level log.Level) string {
+	switch level {
+	case log.TraceLevel:
+		return "TRAC"
+	case log.DebugLevel:
+		return "DEBU"
+	case log.InfoLevel:
+		return "INFO"
+	case log.WarnLevel:
+		return "WARN"
+	case log.ErrorLevel:
+		return "ERRO"
+	case log.FatalLevel:
+		return "FATA"
+	default:
+		return "PANI"
+	}
+}
+
+// levelColor returns the ANSI color for a log level
+func levelColor(level log.Level) string {
+	switch level {
+	case log.TraceLevel, log.DebugLevel:
+		return colorGray
+	case log.InfoLevel:
+		return colorBlue
+	case log.WarnLevel:
+		return colorYellow
+	case log.ErrorLevel, log.FatalLevel, log.PanicLevel:
+		return colorRed
+	default:
+		return colorGreen
+	}
+}
+
+// sortedKeys orders fields with the identifying ones first, the error last
+// and everything else alphabetically in between
+func sortedKeys(data log.Fields) []string {
+	keys := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(leadingFields))
+	for _, k := range leadingFields {
+		if _, ok := data[k]; ok {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+
+	rest := make([]string, 0, len(data))
+	for k := range data {
+		if !seen[k] && k != log.ErrorKey {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	if _, ok := data[log.ErrorKey]; ok {
+		keys = append(keys, log.ErrorKey)
+	}
+	return keys
+}
+
+// formatValue renders a field value, quoting it when it contains spaces
+func formatValue(v interface{}) string {
+	var s string
+	switch val := v.(type) {
+	case error:
+		s = val.Error()
+	case time.Duration:
+		s = val.String()
+	default:
+		s = fmt.Sprint(val)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// IsTerminal reports whether w is attached to a terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+var hookOnce sync.Once
+
+// Setup configures the standard logger's level and output format.
+// Supported formats are "json", "pretty", "text" and "auto"; "auto" selects
+// the pretty formatter on a terminal and JSON otherwise (e.g. under Docker
+// without a TTY), so log shippers receive structured lines by default.
+// Secrets registered with the redact package are masked in every line.
+func Setup(level, format string) {
+	hookOnce.Do(func() { log.AddHook(redact.Hook{}) })
+
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		lvl = log.InfoLevel
+	}
+	log.SetLevel(lvl)
+
+	out := log.StandardLogger().Out
+	if format == "auto" || format == "" {
+		if IsTerminal(out) {
+			format = "pretty"
+		} else {
+			format = "json"
+		}
+	}
+
+	switch format {
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+		})
+	case "pretty":
+		log.SetFormatter(&PrettyFormatter{
+			TimestampFormat: "2006-01-02 15:04:05",
+			DisableColors:   !IsTerminal(out) || os.Getenv("NO_COLOR") != "",
+		})
+	default:
+		log.SetFormatter(&log.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+		})
+	}
+}
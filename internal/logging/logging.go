@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Standard structured log field names shared across modules, so log
+// pipelines (Loki, Elastic) can index on stable keys.
+const (
+	FieldContainer   = "container"
+	FieldContainerID = "container_id"
+	FieldImage       = "image"
+	FieldCycleID     = "cycle_id"
+	FieldAction      = "action"
+)
+
+type ctxKey struct{}
+
+// NewCycleID returns a short random identifier used to correlate all log
+// lines emitted during a single update cycle
+func NewCycleID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000"
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithLogger returns a copy of ctx carrying the given log entry
+func WithLogger(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, entry)
+}
+
+// FromContext returns the log entry stored in ctx, or a bare entry on the
+// standard logger when none is present
+func FromContext(ctx context.Context) *log.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(ctxKey{}).(*log.Entry); ok && entry != nil {
+			return entry
+		}
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// WithFields returns the context logger extended with the given fields
+func WithFields(ctx context.Context, fields log.Fields) *log.Entry {
+	return FromContext(ctx).WithFields(fields)
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"strings"
+	"time"
+)
+
+// HealthDigest summarizes health watching over a period
+type HealthDigest struct {
+	Since time.Time
+	Until time.Time
+	// Monitored is the number of containers checked in the period
+	Monitored int
+	// Unhealthy counts how often a container became unhealthy
+	Unhealthy int
+	// Restarts counts the restarts of unhealthy containers
+	Restarts     int
+	GaveUp       []string
+	CrashLooping []string
+	// PendingUpdates are the containers with a newer image available
+	PendingUpdates []string
+}
+
+// NotifyHealthDigest sends the health digest as a single notification,
+// listing the containers that need attention
+func (n *Notifier) NotifyHealthDigest(d HealthDigest) {
+	lines := []string{n.tr.T("notify.health_digest",
+		d.Since.Format("2006-01-02 15:04"), d.Monitored, d.Unhealthy, d.Restarts, len(d.GaveUp), len(d.PendingUpdates))}
+	if len(d.GaveUp) > 0 {
+		lines = append(lines, n.tr.T("notify.health_digest_gave_up", strings.Join(d.GaveUp, ", ")))
+	}
+	if len(d.CrashLooping) > 0 {
+		lines = append(lines, n.tr.T("notify.health_digest_crash_loop", strings.Join(d.CrashLooping, ", ")))
+	}
+	if len(d.PendingUpdates) > 0 {
+		lines = append(lines, n.tr.T("notify.health_digest_pending", strings.Join(d.PendingUpdates, ", ")))
+	}
+
+	event := Event{
+		Type:    EventHealthDigest,
+		Message: strings.Join(lines, "\n"),
+		Extra: map[string]interface{}{
+			"since":           d.Since.UTC().Format(time.RFC3339),
+			"until":           d.Until.UTC().Format(time.RFC3339),
+			"monitored":       d.Monitored,
+			"unhealthy":       d.Unhealthy,
+			"restarts":        d.Restarts,
+			"gave_up":         nonNil(d.GaveUp),
+			"crash_looping":   nonNil(d.CrashLooping),
+			"pending_updates": nonNil(d.PendingUpdates),
+		},
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// nonNil returns s, or an empty slice so it is encoded as [] instead of null
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -3,12 +3,21 @@ package notify
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/emon5122/dockwarden/internal/i18n"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/redact"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -20,16 +29,30 @@ const (
 	EventContainerRestarted EventType = "container_restarted"
 	EventContainerUnhealthy EventType = "container_unhealthy"
 	EventContainerGaveUp    EventType = "container_gave_up"
+	EventContainerCrashLoop EventType = "container_crash_loop"
 	EventUpdateCycleStart   EventType = "update_cycle_start"
 	EventUpdateCycleEnd     EventType = "update_cycle_end"
+	EventStarted            EventType = "dockwarden_started"
+	EventStopped            EventType = "dockwarden_stopped"
+	EventVersionChanged     EventType = "dockwarden_version_changed"
+	EventReleaseAvailable   EventType = "dockwarden_release_available"
+	EventAPIAction          EventType = "api_action"
+	EventImageEOL           EventType = "image_eol"
+	EventHealthDigest       EventType = "health_digest"
+	EventQuarantined        EventType = "container_quarantined"
 )
 
 // Event represents a notification event
 type Event struct {
+	// ID is unique per event and stays the same across delivery retries, so
+	// receivers can deduplicate
+	ID            string                 `json:"id"`
 	Type          EventType              `json:"type"`
 	ContainerID   string                 `json:"container_id,omitempty"`
 	ContainerName string                 `json:"container_name,omitempty"`
 	Image         string                 `json:"image,omitempty"`
+	Owner         string                 `json:"owner,omitempty"`
+	Notes         string                 `json:"notes,omitempty"`
 	Message       string                 `json:"message"`
 	Timestamp     time.Time              `json:"timestamp"`
 	Extra         map[string]interface{} `json:"extra,omitempty"`
@@ -39,65 +62,117 @@ type Event struct {
 type Notifier struct {
 	webhookURL string
 	client     *http.Client
+	tr         *i18n.Translator
+	opts       Options
 }
 
-// New creates a new Notifier
-func New(webhookURL string) *Notifier {
+// New creates a new Notifier writing messages in the translator's locale
+// (English when tr is nil)
+func New(webhookURL string, tr *i18n.Translator) *Notifier {
+	return NewWithOptions(webhookURL, tr, Options{})
+}
+
+// NewWithOptions creates a new Notifier with delivery options. A TLS
+// configuration that fails to load is logged and left out.
+func NewWithOptions(webhookURL string, tr *i18n.Translator, opts Options) *Notifier {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	if tlsConfig, err := opts.tlsConfig(); err != nil {
+		log.WithError(err).Error("Failed to load notification TLS configuration")
+	} else if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
 	return &Notifier{
 		webhookURL: webhookURL,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client:     client,
+		tr:         tr,
+		opts:       opts,
 	}
 }
 
-// Send sends a notification event
+// Send formats a notification event and queues it for delivery
 func (n *Notifier) Send(event Event) error {
 	if n.webhookURL == "" {
-		log.Debugf("No notification URL configured, skipping notification")
+		log.Debug("No notification URL configured, skipping notification")
 		return nil
 	}
 
 	event.Timestamp = time.Now()
+	event.ID = newEventID()
 
-	// Detect webhook type and format accordingly
-	if strings.Contains(n.webhookURL, "discord.com/api/webhooks") {
+	switch n.format() {
+	case FormatDiscord:
 		return n.sendDiscord(event)
-	} else if strings.Contains(n.webhookURL, "hooks.slack.com") {
+	case FormatSlack:
 		return n.sendSlack(event)
-	} else {
+	case FormatTeams:
+		return n.sendTeams(event)
+	case FormatGoogleChat:
+		return n.sendGoogleChat(event)
+	default:
 		return n.sendGeneric(event)
 	}
 }
 
+// format returns the configured payload format, or the one detected from
+// the webhook URL
+func (n *Notifier) format() string {
+	if n.opts.Format != "" && n.opts.Format != FormatAuto {
+		return n.opts.Format
+	}
+	return DetectFormat(n.webhookURL)
+}
+
+// generic reports whether the webhook receives the generic JSON payload
+func (n *Notifier) generic() bool {
+	return n.format() == FormatGeneric
+}
+
 // sendDiscord sends a Discord webhook notification
 func (n *Notifier) sendDiscord(event Event) error {
 	color := 0x3498db // Blue default
 
 	switch event.Type {
-	case EventContainerUpdated:
+	case EventContainerUpdated, EventStarted, EventVersionChanged:
 		color = 0x2ecc71 // Green
-	case EventContainerUnhealthy, EventContainerGaveUp:
+	case EventContainerUnhealthy, EventContainerGaveUp, EventContainerCrashLoop, EventQuarantined:
 		color = 0xe74c3c // Red
-	case EventContainerRestarted:
+	case EventContainerRestarted, EventStopped:
 		color = 0xf39c12 // Orange
 	}
 
 	fields := []map[string]interface{}{}
 	if event.ContainerName != "" {
 		fields = append(fields, map[string]interface{}{
-			"name":   "Container",
+			"name":   n.tr.T("notify.field.container"),
 			"value":  event.ContainerName,
 			"inline": true,
 		})
 	}
 	if event.Image != "" {
 		fields = append(fields, map[string]interface{}{
-			"name":   "Image",
+			"name":   n.tr.T("notify.field.image"),
 			"value":  fmt.Sprintf("`%s`", event.Image),
 			"inline": true,
 		})
 	}
+	if event.Owner != "" {
+		fields = append(fields, map[string]interface{}{
+			"name":   n.tr.T("notify.field.owner"),
+			"value":  event.Owner,
+			"inline": true,
+		})
+	}
+	if event.Notes != "" {
+		fields = append(fields, map[string]interface{}{
+			"name":  n.tr.T("notify.field.notes"),
+			"value": event.Notes,
+		})
+	}
 
 	payload := map[string]interface{}{
 		"embeds": []map[string]interface{}{
@@ -114,7 +189,7 @@ func (n *Notifier) sendDiscord(event Event) error {
 		},
 	}
 
-	return n.post(payload)
+	return n.post(event, payload)
 }
 
 // sendSlack sends a Slack webhook notification
@@ -123,30 +198,171 @@ func (n *Notifier) sendSlack(event Event) error {
 	switch event.Type {
 	case EventContainerUpdated:
 		emoji = ":white_check_mark:"
-	case EventContainerUnhealthy, EventContainerGaveUp:
+	case EventContainerUnhealthy, EventContainerGaveUp, EventContainerCrashLoop, EventQuarantined:
 		emoji = ":x:"
 	case EventContainerRestarted:
 		emoji = ":arrows_counterclockwise:"
+	case EventStarted:
+		emoji = ":rocket:"
+	case EventStopped:
+		emoji = ":octagonal_sign:"
+	case EventVersionChanged, EventReleaseAvailable:
+		emoji = ":arrow_up:"
 	}
 
 	text := fmt.Sprintf("%s *DockWarden:* %s", emoji, event.Message)
 	if event.ContainerName != "" {
-		text += fmt.Sprintf("\n• Container: `%s`", event.ContainerName)
+		text += fmt.Sprintf("\n• %s: `%s`", n.tr.T("notify.field.container"), event.ContainerName)
 	}
 	if event.Image != "" {
-		text += fmt.Sprintf("\n• Image: `%s`", event.Image)
+		text += fmt.Sprintf("\n• %s: `%s`", n.tr.T("notify.field.image"), event.Image)
+	}
+	if event.Owner != "" {
+		text += fmt.Sprintf("\n• %s: %s", n.tr.T("notify.field.owner"), event.Owner)
+	}
+	if event.Notes != "" {
+		text += fmt.Sprintf("\n• %s: %s", n.tr.T("notify.field.notes"), event.Notes)
 	}
 
 	payload := map[string]interface{}{
 		"text": text,
 	}
 
-	return n.post(payload)
+	return n.post(event, payload)
+}
+
+// sendTeams sends a Microsoft Teams notification as an Adaptive Card, which
+// both Workflows webhooks and the legacy incoming webhooks accept
+func (n *Notifier) sendTeams(event Event) error {
+	facts := []map[string]string{}
+	if event.ContainerName != "" {
+		facts = append(facts, map[string]string{
+			"title": n.tr.T("notify.field.container"),
+			"value": event.ContainerName,
+		})
+	}
+	if event.Image != "" {
+		facts = append(facts, map[string]string{
+			"title": n.tr.T("notify.field.image"),
+			"value": event.Image,
+		})
+	}
+	if event.Owner != "" {
+		facts = append(facts, map[string]string{
+			"title": n.tr.T("notify.field.owner"),
+			"value": event.Owner,
+		})
+	}
+	if event.Notes != "" {
+		facts = append(facts, map[string]string{
+			"title": n.tr.T("notify.field.notes"),
+			"value": event.Notes,
+		})
+	}
+
+	body := []map[string]interface{}{
+		{
+			"type":   "TextBlock",
+			"text":   fmt.Sprintf("DockWarden: %s", event.Type),
+			"weight": "Bolder",
+			"size":   "Medium",
+		},
+		{
+			"type": "TextBlock",
+			"text": event.Message,
+			"wrap": true,
+		},
+	}
+	if len(facts) > 0 {
+		body = append(body, map[string]interface{}{
+			"type":  "FactSet",
+			"facts": facts,
+		})
+	}
+
+	payload := map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+
+	return n.post(event, payload)
+}
+
+// sendGoogleChat sends a Google Chat card message. The plain text is shown
+// in push notifications and clients that can't render cards.
+func (n *Notifier) sendGoogleChat(event Event) error {
+	widgets := []map[string]interface{}{
+		{"textParagraph": map[string]string{"text": event.Message}},
+	}
+	if event.ContainerName != "" {
+		widgets = append(widgets, map[string]interface{}{
+			"decoratedText": map[string]string{
+				"topLabel": n.tr.T("notify.field.container"),
+				"text":     event.ContainerName,
+			},
+		})
+	}
+	if event.Image != "" {
+		widgets = append(widgets, map[string]interface{}{
+			"decoratedText": map[string]string{
+				"topLabel": n.tr.T("notify.field.image"),
+				"text":     event.Image,
+			},
+		})
+	}
+	if event.Owner != "" {
+		widgets = append(widgets, map[string]interface{}{
+			"decoratedText": map[string]string{
+				"topLabel": n.tr.T("notify.field.owner"),
+				"text":     event.Owner,
+			},
+		})
+	}
+	if event.Notes != "" {
+		widgets = append(widgets, map[string]interface{}{
+			"decoratedText": map[string]interface{}{
+				"topLabel": n.tr.T("notify.field.notes"),
+				"text":     event.Notes,
+				"wrapText": true,
+			},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"text": fmt.Sprintf("DockWarden: %s", event.Message),
+		"cardsV2": []map[string]interface{}{
+			{
+				"cardId": event.ID,
+				"card": map[string]interface{}{
+					"header": map[string]string{
+						"title":    "DockWarden",
+						"subtitle": string(event.Type),
+					},
+					"sections": []map[string]interface{}{
+						{"widgets": widgets},
+					},
+				},
+			},
+		},
+	}
+
+	return n.post(event, payload)
 }
 
 // sendGeneric sends a generic JSON webhook notification
 func (n *Notifier) sendGeneric(event Event) error {
 	payload := map[string]interface{}{
+		"id":        event.ID,
 		"source":    "dockwarden",
 		"type":      event.Type,
 		"message":   event.Message,
@@ -160,92 +376,413 @@ func (n *Notifier) sendGeneric(event Event) error {
 	if event.Image != "" {
 		payload["image"] = event.Image
 	}
+	if event.Owner != "" {
+		payload["owner"] = event.Owner
+	}
+	if event.Notes != "" {
+		payload["notes"] = event.Notes
+	}
 	if event.Extra != nil {
 		for k, v := range event.Extra {
 			payload[k] = v
 		}
 	}
 
-	return n.post(payload)
+	return n.post(event, payload)
 }
 
-// post sends a POST request with JSON payload
-func (n *Notifier) post(payload interface{}) error {
+// post marshals the payload and queues it for delivery. Delivery happens on
+// the destination's worker, so a nil error only means the event was queued.
+func (n *Notifier) post(event Event, payload interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal notification payload: %w", err)
 	}
 
+	dispatcherFor(n).enqueue(event, body)
+	return nil
+}
+
+// deliver sends a single request with a JSON body, a POST unless another
+// method is configured for a generic webhook. When the webhook rate limits
+// the request, the returned duration is how long it asked us to wait before
+// retrying.
+func (n *Notifier) deliver(event Event, body []byte) (time.Duration, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	method := http.MethodPost
+	if n.generic() && n.opts.Method != "" {
+		method = n.opts.Method
+	}
+	req, err := http.NewRequestWithContext(ctx, method, n.webhookURL, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("failed to create notification request: %w", err)
+		return 0, fmt.Errorf("failed to create notification request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "DockWarden/1.0")
+	req.Header.Set("X-DockWarden-Event-ID", event.ID)
+	if n.generic() {
+		for name, value := range n.opts.Headers {
+			req.Header.Set(name, value)
+		}
+		if n.opts.Username != "" || n.opts.Password != "" {
+			req.SetBasicAuth(n.opts.Username, n.opts.Password)
+		}
+	}
+	if n.opts.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-DockWarden-Timestamp", timestamp)
+		req.Header.Set("X-DockWarden-Signature", Sign(n.opts.Secret, timestamp, body))
+	}
 
 	resp, err := n.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send notification: %w", err)
+		return 0, fmt.Errorf("failed to send notification: %w", redact.Error(err))
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return retryAfter(resp.Header.Get("Retry-After")), errRateLimited
+	}
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("notification webhook returned status %d", resp.StatusCode)
+		return 0, fmt.Errorf("notification webhook returned status %d", resp.StatusCode)
 	}
 
-	log.Debugf("Notification sent successfully to %s", n.webhookURL)
-	return nil
+	log.WithField("url", redact.URL(n.webhookURL)).Debug("Notification sent successfully")
+	return 0, nil
+}
+
+// Sign returns the signature header value of a generic webhook request:
+// "sha256=" and the hex HMAC-SHA256 of the timestamp, a dot and the body.
+// Receivers recompute it with the shared secret and should reject old
+// timestamps to prevent replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
 }
 
-// NotifyContainerUpdated sends a container updated notification
-func (n *Notifier) NotifyContainerUpdated(containerName, image, oldDigest, newDigest string) {
+// newEventID returns a random identifier for an event
+func newEventID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// VersionLabel is the OCI image label holding the version of the packaged
+// software
+const VersionLabel = "org.opencontainers.image.version"
+
+// ImageChange describes what changed between the old and new image of an
+// updated container
+type ImageChange struct {
+	OldDigest  string
+	NewDigest  string
+	OldCreated time.Time
+	NewCreated time.Time
+	// Labels are the compared image labels whose value changed
+	Labels []LabelChange
+}
+
+// LabelChange is an image label with its old and new value
+type LabelChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// version returns the old and new value of the version label, if it changed
+func (c ImageChange) version() (string, string, bool) {
+	for _, l := range c.Labels {
+		if l.Key == VersionLabel && l.Old != "" && l.New != "" {
+			return l.Old, l.New, true
+		}
+	}
+	return "", "", false
+}
+
+// NotifyContainerUpdated sends a container updated notification. When the
+// image version label changed, the message names the old and new version,
+// e.g. "grafana 10.4.2 → 10.4.3".
+func (n *Notifier) NotifyContainerUpdated(containerName, image string, change ImageChange) {
+	message := n.tr.T("notify.container_updated", containerName)
+	if oldVersion, newVersion, ok := change.version(); ok {
+		message = n.tr.T("notify.container_updated_version", containerName, shortImageName(image), oldVersion, newVersion)
+	}
+
+	extra := map[string]interface{}{
+		"old_digest": change.OldDigest,
+		"new_digest": change.NewDigest,
+	}
+	if !change.OldCreated.IsZero() {
+		extra["old_created"] = change.OldCreated.UTC().Format(time.RFC3339)
+	}
+	if !change.NewCreated.IsZero() {
+		extra["new_created"] = change.NewCreated.UTC().Format(time.RFC3339)
+	}
+	if len(change.Labels) > 0 {
+		labels := make(map[string]interface{}, len(change.Labels))
+		for _, l := range change.Labels {
+			labels[l.Key] = map[string]string{"old": l.Old, "new": l.New}
+		}
+		extra["labels"] = labels
+	}
+
 	event := Event{
 		Type:          EventContainerUpdated,
 		ContainerName: containerName,
 		Image:         image,
-		Message:       fmt.Sprintf("Container %s has been updated", containerName),
-		Extra: map[string]interface{}{
-			"old_digest": oldDigest,
-			"new_digest": newDigest,
-		},
+		Message:       message,
+		Extra:         extra,
 	}
 	if err := n.Send(event); err != nil {
-		log.Warnf("Failed to send notification: %v", err)
+		logFailure(event, err)
+	}
+}
+
+// shortImageName returns the last path element of an image reference
+// without tag or digest, e.g. "grafana/grafana:latest" -> "grafana"
+func shortImageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
 	}
+	image, _, _ = strings.Cut(image, ":")
+	return image
+}
+
+// Contact says who to reach about a container, from its dockwarden.owner
+// and dockwarden.notes labels
+type Contact struct {
+	Owner string
+	Notes string
 }
 
-// NotifyContainerUnhealthy sends an unhealthy container notification
-func (n *Notifier) NotifyContainerUnhealthy(containerName, image string, attempts int) {
+// NotifyContainerUnhealthy sends an unhealthy container notification,
+// naming the dependent containers restarted along with it
+func (n *Notifier) NotifyContainerUnhealthy(containerName, image string, attempts int, contact Contact, dependents []string) {
+	message := n.tr.T("notify.container_unhealthy", containerName, attempts)
+	if len(dependents) > 0 {
+		message += "\n" + n.tr.T("notify.dependents", strings.Join(dependents, ", "))
+	}
 	event := Event{
 		Type:          EventContainerUnhealthy,
 		ContainerName: containerName,
 		Image:         image,
-		Message:       fmt.Sprintf("Container %s is unhealthy (attempt %d)", containerName, attempts),
+		Owner:         contact.Owner,
+		Notes:         contact.Notes,
+		Message:       message,
 		Extra: map[string]interface{}{
 			"restart_attempts": attempts,
 		},
 	}
+	if len(dependents) > 0 {
+		event.Extra["dependents"] = dependents
+	}
 	if err := n.Send(event); err != nil {
-		log.Warnf("Failed to send notification: %v", err)
+		logFailure(event, err)
 	}
 }
 
 // NotifyContainerGaveUp sends a gave up notification
-func (n *Notifier) NotifyContainerGaveUp(containerName, image string, maxAttempts int) {
+func (n *Notifier) NotifyContainerGaveUp(containerName, image, action string, maxAttempts int, contact Contact) {
+	key := "notify.container_gave_up"
+	if action == "recreate" {
+		key = "notify.container_gave_up_recreate"
+	}
+
 	event := Event{
 		Type:          EventContainerGaveUp,
 		ContainerName: containerName,
 		Image:         image,
-		Message:       fmt.Sprintf("Container %s: giving up after %d restart attempts. Waiting for new image version.", containerName, maxAttempts),
+		Owner:         contact.Owner,
+		Notes:         contact.Notes,
+		Message:       n.tr.T(key, containerName, maxAttempts),
 		Extra: map[string]interface{}{
+			"action":       action,
 			"max_attempts": maxAttempts,
 		},
 	}
 	if err := n.Send(event); err != nil {
-		log.Warnf("Failed to send notification: %v", err)
+		logFailure(event, err)
+	}
+}
+
+// NotifyContainerCrashLoop sends a notification that a container keeps
+// being restarted by the daemon
+func (n *Notifier) NotifyContainerCrashLoop(containerName, image string, restarts int, window time.Duration, contact Contact) {
+	event := Event{
+		Type:          EventContainerCrashLoop,
+		ContainerName: containerName,
+		Image:         image,
+		Owner:         contact.Owner,
+		Notes:         contact.Notes,
+		Message:       n.tr.T("notify.container_crash_loop", containerName, restarts, window),
+		Extra: map[string]interface{}{
+			"restarts": restarts,
+			"window":   window.String(),
+		},
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// NotifyStarted sends a notification that DockWarden has started, with a
+// summary of the effective configuration
+func (n *Notifier) NotifyStarted(version string, summary map[string]interface{}) {
+	event := Event{
+		Type:    EventStarted,
+		Message: n.tr.T("notify.started", version, formatSummary(summary)),
+		Extra: map[string]interface{}{
+			"version": version,
+			"config":  summary,
+		},
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// NotifyStopped sends a notification that DockWarden is shutting down
+func (n *Notifier) NotifyStopped(version, reason string) {
+	event := Event{
+		Type:    EventStopped,
+		Message: n.tr.T("notify.stopped", version, reason),
+		Extra: map[string]interface{}{
+			"version": version,
+			"reason":  reason,
+		},
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// NotifyVersionChanged sends a notification that DockWarden itself was
+// updated since its previous start
+func (n *Notifier) NotifyVersionChanged(oldVersion, newVersion string) {
+	event := Event{
+		Type:    EventVersionChanged,
+		Message: n.tr.T("notify.version_changed", oldVersion, newVersion),
+		Extra: map[string]interface{}{
+			"old_version": oldVersion,
+			"new_version": newVersion,
+		},
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// NotifyReleaseAvailable sends a notification that a newer DockWarden
+// release was published
+func (n *Notifier) NotifyReleaseAvailable(current, latest, url string) {
+	event := Event{
+		Type:    EventReleaseAvailable,
+		Message: n.tr.T("notify.release_available", latest, current),
+		Extra: map[string]interface{}{
+			"current_version": current,
+			"latest_version":  latest,
+			"release_url":     url,
+		},
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// NotifyAPIAction sends a notification about an action triggered through the
+// API or the dashboard, naming who triggered it
+func (n *Notifier) NotifyAPIAction(action, target, identity, failure string) {
+	message := n.tr.T("notify.api_action", action, identity)
+	if target != "" {
+		message = n.tr.T("notify.api_action_target", action, target, identity)
+	}
+	if failure != "" {
+		message += ": failed: " + failure
+	}
+
+	event := Event{
+		Type:          EventAPIAction,
+		ContainerName: target,
+		Message:       message,
+		Extra: map[string]interface{}{
+			"action":       action,
+			"triggered_by": identity,
+		},
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// NotifyImageEOL warns that a container runs an end-of-life release
+func (n *Notifier) NotifyImageEOL(containerName, image, product, cycle string, eol time.Time) {
+	message := n.tr.T("notify.image_eol", containerName, product, cycle)
+	extra := map[string]interface{}{
+		"product": product,
+		"cycle":   cycle,
+	}
+	if !eol.IsZero() {
+		message = n.tr.T("notify.image_eol_date", containerName, product, cycle, eol.Format("2006-01-02"))
+		extra["eol"] = eol.Format("2006-01-02")
 	}
+
+	event := Event{
+		Type:          EventImageEOL,
+		ContainerName: containerName,
+		Image:         image,
+		Message:       message,
+		Extra:         extra,
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// NotifyQuarantined sends a single escalation notification when a container
+// is quarantined after failing its update too many times in a row
+func (n *Notifier) NotifyQuarantined(containerName, image string, failures int, lastError string) {
+	event := Event{
+		Type:          EventQuarantined,
+		ContainerName: containerName,
+		Image:         image,
+		Message:       n.tr.T("notify.quarantined", containerName, failures, lastError),
+		Extra: map[string]interface{}{
+			"failures":   failures,
+			"last_error": lastError,
+		},
+	}
+	if err := n.Send(event); err != nil {
+		logFailure(event, err)
+	}
+}
+
+// formatSummary renders a config summary as sorted key=value pairs
+func formatSummary(summary map[string]interface{}) string {
+	keys := make([]string, 0, len(summary))
+	for k := range summary {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, summary[k]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// logFailure logs a failed notification with the event's context fields
+func logFailure(event Event, err error) {
+	log.WithFields(log.Fields{
+		"event":                event.Type,
+		logging.FieldContainer: event.ContainerName,
+		logging.FieldImage:     event.Image,
+	}).WithError(err).Warn("Failed to send notification")
 }
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/config"
+	"github.com/emon5122/dockwarden/internal/i18n"
+)
+
+// Payload formats
+const (
+	FormatAuto       = "auto"
+	FormatGeneric    = "generic"
+	FormatDiscord    = "discord"
+	FormatSlack      = "slack"
+	FormatTeams      = "teams"
+	FormatGoogleChat = "googlechat"
+)
+
+// DetectFormat picks the payload format from the webhook URL, falling back
+// to the generic JSON payload
+func DetectFormat(webhookURL string) string {
+	switch {
+	case strings.Contains(webhookURL, "discord.com/api/webhooks"):
+		return FormatDiscord
+	case strings.Contains(webhookURL, "hooks.slack.com"):
+		return FormatSlack
+	case strings.Contains(webhookURL, ".webhook.office.com"),
+		strings.Contains(webhookURL, "outlook.office.com/webhook"),
+		strings.Contains(webhookURL, ".logic.azure.com"):
+		return FormatTeams
+	case strings.Contains(webhookURL, "chat.googleapis.com"):
+		return FormatGoogleChat
+	default:
+		return FormatGeneric
+	}
+}
+
+// Options configure how notifications are delivered. Method, headers and
+// basic auth only apply to generic webhooks; chat services authenticate
+// through their URLs.
+type Options struct {
+	// Format selects the payload format, detected from the URL when empty
+	// or "auto"
+	Format string
+
+	// Secret signs generic webhook payloads with HMAC-SHA256
+	Secret string
+
+	// Method is the HTTP method, POST when empty
+	Method string
+	// Headers are added to every request, e.g. Authorization
+	Headers map[string]string
+	// Username and Password are sent as basic auth when either is set
+	Username string
+	Password string
+
+	// TLSCert and TLSKey are a client certificate presented to the webhook,
+	// TLSCA a CA bundle trusted in addition to the system roots
+	TLSCert string
+	TLSKey  string
+	TLSCA   string
+}
+
+// FromConfig creates the notifier configured by cfg, or returns nil when no
+// notification URL is set
+func FromConfig(cfg *config.Config) *Notifier {
+	if cfg.NotificationURL == "" {
+		return nil
+	}
+	opts, _ := optionsFromConfig(cfg)
+	return NewWithOptions(cfg.NotificationURL, i18n.For(cfg.Locale), opts)
+}
+
+// ValidateConfig checks the notification settings of cfg, so mistakes are
+// reported at startup instead of on the first notification
+func ValidateConfig(cfg *config.Config) error {
+	opts, err := optionsFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	_, err = opts.tlsConfig()
+	return err
+}
+
+func optionsFromConfig(cfg *config.Config) (Options, error) {
+	opts := Options{
+		Format:   strings.ToLower(cfg.NotificationFormat),
+		Secret:   cfg.NotificationSecret,
+		Method:   strings.ToUpper(cfg.NotificationMethod),
+		Username: cfg.NotificationUsername,
+		Password: cfg.NotificationPassword,
+		TLSCert:  cfg.NotificationTLSCert,
+		TLSKey:   cfg.NotificationTLSKey,
+		TLSCA:    cfg.NotificationTLSCA,
+	}
+
+	switch opts.Format {
+	case "", FormatAuto, FormatGeneric, FormatDiscord, FormatSlack, FormatTeams, FormatGoogleChat:
+	default:
+		return opts, fmt.Errorf("unsupported notification format %q (use auto, generic, discord, slack, teams or googlechat)", cfg.NotificationFormat)
+	}
+
+	switch opts.Method {
+	case "", http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return opts, fmt.Errorf("unsupported notification method %q (use POST, PUT or PATCH)", cfg.NotificationMethod)
+	}
+
+	headers, err := ParseHeaders(cfg.NotificationHeaders)
+	if err != nil {
+		return opts, err
+	}
+	opts.Headers = headers
+	return opts, nil
+}
+
+// ParseHeaders parses "Name: value" entries
+func ParseHeaders(entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid notification header %q, expected \"Name: value\"", entry)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// tlsConfig loads the client certificate and CA bundle, or returns nil when
+// neither is configured
+func (o Options) tlsConfig() (*tls.Config, error) {
+	if o.TLSCert == "" && o.TLSKey == "" && o.TLSCA == "" {
+		return nil, nil
+	}
+	if (o.TLSCert == "") != (o.TLSKey == "") {
+		return nil, errors.New("notification TLS client certificate and key must be set together")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCert, o.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load notification client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if o.TLSCA != "" {
+		pem, err := os.ReadFile(o.TLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notification CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.TLSCA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// queueSize is how many notifications may wait per destination before
+	// senders block
+	queueSize = 256
+
+	// maxAttempts bounds how often a rate limited notification is retried
+	maxAttempts = 5
+
+	// defaultRetryAfter is used when a 429 response carries no Retry-After
+	defaultRetryAfter = 5 * time.Second
+
+	// maxRetryAfter caps the wait requested by a webhook
+	maxRetryAfter = 5 * time.Minute
+)
+
+// errRateLimited is returned by deliver when the webhook answered 429
+var errRateLimited = errors.New("notification webhook rate limited the request")
+
+var (
+	dispatchersMu sync.Mutex
+	dispatchers   = map[string]*dispatcher{}
+)
+
+// dispatcher funnels all notifications for one webhook URL through a single
+// worker, spacing requests out and honouring Retry-After so bursts of events
+// (many containers updating at once) are delivered instead of dropped.
+// Notifiers sharing a URL share its dispatcher.
+type dispatcher struct {
+	notifier *Notifier
+	interval time.Duration
+	queue    chan queued
+	pending  sync.WaitGroup
+}
+
+type queued struct {
+	event Event
+	body  []byte
+}
+
+// dispatcherFor returns the dispatcher of n's webhook URL, starting it on
+// first use
+func dispatcherFor(n *Notifier) *dispatcher {
+	dispatchersMu.Lock()
+	defer dispatchersMu.Unlock()
+
+	if d, ok := dispatchers[n.webhookURL]; ok {
+		return d
+	}
+	d := &dispatcher{
+		notifier: n,
+		interval: minInterval(n.format()),
+		queue:    make(chan queued, queueSize),
+	}
+	dispatchers[n.webhookURL] = d
+	go d.run()
+	return d
+}
+
+// minInterval is the spacing between requests to a destination. Discord
+// allows about 30 messages per minute per webhook, Slack and Google Chat
+// one per second and Teams four.
+func minInterval(format string) time.Duration {
+	switch format {
+	case FormatDiscord:
+		return 2 * time.Second
+	case FormatSlack, FormatGoogleChat:
+		return time.Second
+	case FormatTeams:
+		return 250 * time.Millisecond
+	default:
+		return 100 * time.Millisecond
+	}
+}
+
+// enqueue adds a notification to the queue, blocking while it is full
+func (d *dispatcher) enqueue(event Event, body []byte) {
+	d.pending.Add(1)
+	d.queue <- queued{event: event, body: body}
+}
+
+func (d *dispatcher) run() {
+	var last time.Time
+	for item := range d.queue {
+		for attempt := 1; ; attempt++ {
+			if wait := d.interval - time.Since(last); wait > 0 {
+				time.Sleep(wait)
+			}
+			delay, err := d.notifier.deliver(item.event, item.body)
+			last = time.Now()
+			if err == nil {
+				break
+			}
+			if !errors.Is(err, errRateLimited) || attempt == maxAttempts {
+				logFailure(item.event, err)
+				break
+			}
+			log.WithFields(log.Fields{
+				"event":       item.event.Type,
+				"retry_after": delay.String(),
+			}).Debug("Notification rate limited, retrying")
+			time.Sleep(delay)
+		}
+		d.pending.Done()
+	}
+}
+
+// Flush waits until every queued notification has been delivered or has
+// failed, or until timeout passes. It reports whether the queues drained.
+func Flush(timeout time.Duration) bool {
+	dispatchersMu.Lock()
+	all := make([]*dispatcher, 0, len(dispatchers))
+	for _, d := range dispatchers {
+		all = append(all, d)
+	}
+	dispatchersMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for _, d := range all {
+			d.pending.Wait()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header string) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return defaultRetryAfter
+	}
+
+	var delay time.Duration
+	if secs, err := strconv.ParseFloat(header, 64); err == nil {
+		delay = time.Duration(secs * float64(time.Second))
+	} else if at, err := http.ParseTime(header); err == nil {
+		delay = time.Until(at)
+	} else {
+		return defaultRetryAfter
+	}
+
+	if delay <= 0 {
+		return time.Second
+	}
+	return min(delay, maxRetryAfter)
+}
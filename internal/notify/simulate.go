@@ -0,0 +1,42 @@
+package notify
+
+import "fmt"
+
+// SimulatedEvents are the event types that can be simulated
+var SimulatedEvents = []EventType{
+	EventContainerUpdated,
+	EventContainerUnhealthy,
+	EventContainerGaveUp,
+}
+
+// SimulatedPrefix starts the message of every simulated event
+const SimulatedPrefix = "[simulated] "
+
+// Simulate sends a synthetic event of a real event type, so operators can
+// check their alert routing without breaking a container. The message is
+// prefixed with SimulatedPrefix and generic payloads carry
+// "simulated": true; everything else matches the real event.
+func (n *Notifier) Simulate(eventType EventType, containerName, image string) error {
+	event := Event{
+		Type:          eventType,
+		ContainerName: containerName,
+		Image:         image,
+		Extra:         map[string]interface{}{"simulated": true},
+	}
+
+	switch eventType {
+	case EventContainerUpdated:
+		event.Message = n.tr.T("notify.container_updated", containerName)
+	case EventContainerUnhealthy:
+		event.Message = n.tr.T("notify.container_unhealthy", containerName, 1)
+		event.Extra["restart_attempts"] = 1
+	case EventContainerGaveUp:
+		event.Message = n.tr.T("notify.container_gave_up", containerName, 5)
+		event.Extra["max_attempts"] = 5
+	default:
+		return fmt.Errorf("event type %q can't be simulated", eventType)
+	}
+	event.Message = SimulatedPrefix + event.Message
+
+	return n.Send(event)
+}
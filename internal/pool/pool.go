@@ -0,0 +1,174 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/logging"
+)
+
+// ErrPanic is returned for tasks that panicked
+var ErrPanic = errors.New("task panicked")
+
+// Task is a unit of work run by the pool
+type Task func(ctx context.Context) error
+
+// Options configures a Pool
+type Options struct {
+	// Workers is the number of tasks run concurrently (at least 1)
+	Workers int
+	// QueueSize bounds the tasks waiting for a worker; Submit blocks when
+	// the queue is full. Defaults to Workers.
+	QueueSize int
+	// TaskTimeout cancels the context of a task running longer (0: none)
+	TaskTimeout time.Duration
+	// Metrics, when set, accumulates statistics across pools
+	Metrics *Metrics
+}
+
+// Metrics are statistics of the tasks run by one or more pools
+type Metrics struct {
+	tasks         atomic.Int64
+	panics        atomic.Int64
+	timeouts      atomic.Int64
+	queueWaitNano atomic.Int64
+	maxWaitNano   atomic.Int64
+}
+
+// Snapshot is a point-in-time copy of Metrics
+type Snapshot struct {
+	Tasks     int64
+	Panics    int64
+	Timeouts  int64
+	QueueWait time.Duration
+	MaxWait   time.Duration
+}
+
+// Snapshot returns the current statistics
+func (m *Metrics) Snapshot() Snapshot {
+	return Snapshot{
+		Tasks:     m.tasks.Load(),
+		Panics:    m.panics.Load(),
+		Timeouts:  m.timeouts.Load(),
+		QueueWait: time.Duration(m.queueWaitNano.Load()),
+		MaxWait:   time.Duration(m.maxWaitNano.Load()),
+	}
+}
+
+func (m *Metrics) observeWait(wait time.Duration) {
+	m.tasks.Add(1)
+	m.queueWaitNano.Add(int64(wait))
+	for {
+		current := m.maxWaitNano.Load()
+		if int64(wait) <= current || m.maxWaitNano.CompareAndSwap(current, int64(wait)) {
+			return
+		}
+	}
+}
+
+type job struct {
+	ctx      context.Context
+	task     Task
+	done     func(error)
+	enqueued time.Time
+}
+
+// Pool runs tasks on a fixed set of workers. A panicking task is recovered
+// and reported as ErrPanic instead of taking the process down.
+type Pool struct {
+	opts    Options
+	queue   chan job
+	wg      sync.WaitGroup
+	pending sync.WaitGroup
+}
+
+// New starts a pool
+func New(opts Options) *Pool {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.QueueSize < 1 {
+		opts.QueueSize = opts.Workers
+	}
+	if opts.Metrics == nil {
+		opts.Metrics = &Metrics{}
+	}
+
+	p := &Pool{
+		opts:  opts,
+		queue: make(chan job, opts.QueueSize),
+	}
+	for i := 0; i < opts.Workers; i++ {
+		p.wg.Add(1)
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues a task, blocking while the queue is full. done, when set, is
+// called with the task's result. An error is returned without running the
+// task when ctx ends before it could be queued.
+func (p *Pool) Submit(ctx context.Context, task Task, done func(error)) error {
+	p.pending.Add(1)
+	select {
+	case p.queue <- job{ctx: ctx, task: task, done: done, enqueued: time.Now()}:
+		return nil
+	case <-ctx.Done():
+		p.pending.Done()
+		return ctx.Err()
+	}
+}
+
+// Wait blocks until every submitted task has finished
+func (p *Pool) Wait() {
+	p.pending.Wait()
+}
+
+// Close waits for the submitted tasks and stops the workers. The pool can't
+// be used afterwards.
+func (p *Pool) Close() {
+	p.pending.Wait()
+	close(p.queue)
+	p.wg.Wait()
+}
+
+func (p *Pool) worker() {
+	defer p.wg.Done()
+	for j := range p.queue {
+		p.opts.Metrics.observeWait(time.Since(j.enqueued))
+		err := p.run(j)
+		if j.done != nil {
+			j.done(err)
+		}
+		p.pending.Done()
+	}
+}
+
+// run executes a job with its timeout, containing panics
+func (p *Pool) run(j job) (err error) {
+	ctx := j.ctx
+	if p.opts.TaskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.opts.TaskTimeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.opts.Metrics.panics.Add(1)
+			logging.FromContext(ctx).WithField("stack", string(debug.Stack())).Errorf("Recovered from panic: %v", r)
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+
+	err = j.task(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		p.opts.Metrics.timeouts.Add(1)
+	}
+	return err
+}
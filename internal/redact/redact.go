@@ -0,0 +1,145 @@
+// Package redact keeps secrets out of logs, API responses and
+// notifications. Webhook URLs carry their token in the path (Discord,
+// Slack) or query (Google Chat), so they are reduced to scheme and host
+// before being shown, and configured secrets are masked wherever they
+// appear in a log line.
+package redact
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Mask replaces redacted values
+const Mask = "***"
+
+// minSecretLen keeps very short values, which would mask unrelated text,
+// from being registered
+const minSecretLen = 6
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// Register adds values that must never appear in logs. URLs are registered
+// together with the password of their userinfo.
+func Register(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		if len(s) < minSecretLen {
+			return
+		}
+		for _, existing := range secrets {
+			if existing == s {
+				return
+			}
+		}
+		secrets = append(secrets, s)
+	}
+	for _, v := range values {
+		add(v)
+		if u, err := url.Parse(strings.TrimSpace(v)); err == nil && u.User != nil {
+			if password, ok := u.User.Password(); ok {
+				add(password)
+			}
+		}
+	}
+	// Longest first, so a secret containing another is masked as a whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// String masks every registered secret in s
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	return s
+}
+
+// URL reduces a URL to its scheme and host, masking the path, query and
+// credentials, e.g. "https://discord.com/***". Values that don't parse as a
+// URL are masked entirely.
+func URL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return Mask
+	}
+
+	s := u.Scheme + "://"
+	if u.User != nil {
+		s += Mask + "@"
+	}
+	s += u.Host
+	if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" {
+		s += "/" + Mask
+	}
+	return s
+}
+
+// Error returns err with URLs reported by the HTTP client and registered
+// secrets masked in its message. The original error stays available to
+// errors.Is and errors.As.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.URL != "" {
+		msg = strings.ReplaceAll(msg, urlErr.URL, URL(urlErr.URL))
+	}
+	msg = String(msg)
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// Hook is a logrus hook masking registered secrets in messages and fields,
+// so a secret passed to a log statement by mistake is never written out
+type Hook struct{}
+
+// Levels implements log.Hook
+func (Hook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire implements log.Hook
+func (Hook) Fire(entry *log.Entry) error {
+	entry.Message = String(entry.Message)
+
+	// Fields are copied per log call before hooks run, so masking them in
+	// place doesn't affect the logger they derive from
+	for k, v := range entry.Data {
+		switch v := v.(type) {
+		case string:
+			entry.Data[k] = String(v)
+		case error:
+			entry.Data[k] = Error(v)
+		}
+	}
+	return nil
+}
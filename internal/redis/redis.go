@@ -0,0 +1,165 @@
+// Package redis speaks just enough of the Redis protocol for the state
+// DockWarden instances share: cycle locks and the update history. It opens
+// a connection per call, as calls are rare.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialTimeout bounds connecting to Redis, and calls without a deadline
+const dialTimeout = 10 * time.Second
+
+// Client runs commands against one Redis database
+type Client struct {
+	addr     string
+	tls      bool
+	username string
+	password string
+	db       int
+}
+
+// New returns a client for a redis:// or rediss:// (TLS) URL, e.g.
+// redis://:password@host:6379/0
+func New(u *url.URL) (*Client, error) {
+	c := &Client{
+		addr: u.Host,
+		tls:  u.Scheme == "rediss",
+	}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		n, err := strconv.Atoi(db)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis database %q", db)
+		}
+		c.db = n
+	}
+	return c, nil
+}
+
+// Do runs a single command, authenticating and selecting the database
+// first. Replies are strings, int64s, nil or []any for arrays.
+func (c *Client) Do(ctx context.Context, args ...string) (any, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if c.tls {
+		conn, err = (&tls.Dialer{NetDialer: dialer}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(dialTimeout))
+	}
+
+	var commands [][]string
+	if c.password != "" {
+		if c.username != "" {
+			commands = append(commands, []string{"AUTH", c.username, c.password})
+		} else {
+			commands = append(commands, []string{"AUTH", c.password})
+		}
+	}
+	if c.db != 0 {
+		commands = append(commands, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	commands = append(commands, args)
+
+	reader := bufio.NewReader(conn)
+	var reply any
+	for _, cmd := range commands {
+		if _, err := conn.Write(encodeCommand(cmd)); err != nil {
+			return nil, fmt.Errorf("failed to send Redis command: %w", err)
+		}
+		if reply, err = readReply(reader); err != nil {
+			return nil, fmt.Errorf("redis %s failed: %w", cmd[0], err)
+		}
+	}
+	return reply, nil
+}
+
+// encodeCommand encodes a command as a RESP array of bulk strings
+func encodeCommand(args []string) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return []byte(sb.String())
+}
+
+// readReply reads a simple string, error, integer, bulk string or array
+// reply. Nil bulk strings and arrays are returned as nil.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, errors.New(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			// Errors inside arrays are returned as the error of the reply
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/emon5122/dockwarden/internal/meta"
+)
+
+// manifestMediaTypes are accepted when resolving a tag, so multi-arch tags
+// resolve to their manifest-list/index digest like `docker pull` records it
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// ErrUnauthorized is returned when the registry requires credentials to read
+// the manifest
+var ErrUnauthorized = errors.New("registry requires authentication")
+
+// ErrNotFound is returned when the repository or tag does not exist
+var ErrNotFound = errors.New("manifest not found")
+
+// Client queries registries over the distribution HTTP API without pulling.
+// Public images on registries that require a token even for anonymous
+// reads (ghcr.io, Docker Hub) are handled by answering the Bearer challenge.
+type Client struct {
+	http   *http.Client
+	tokens tokenCache
+	tags   tagCache
+}
+
+// NewClient creates a registry client. A nil httpClient uses a default client
+// with a 30 second timeout.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{http: httpClient, tags: tagCache{ttl: DefaultTagCacheTTL}}
+}
+
+// Digest returns the digest the registry currently serves for an image
+// reference, using a HEAD request on the manifest. Digest references are
+// returned as-is without contacting the registry.
+func (c *Client) Digest(ctx context.Context, imageName string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference %q: %w", imageName, err)
+	}
+
+	if digested, ok := named.(reference.Digested); ok {
+		return digested.Digest().String(), nil
+	}
+
+	tag := "latest"
+	if tagged, ok := reference.TagNameOnly(named).(reference.Tagged); ok {
+		tag = tagged.Tag()
+	}
+
+	url := fmt.Sprintf("%s/v2/%s/manifests/%s", registryURL(reference.Domain(named)), reference.Path(named), tag)
+
+	resp, err := c.do(ctx, http.MethodHead, url)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// Some registries omit the digest header on HEAD; hash the manifest instead
+	resp, err = c.do(ctx, http.MethodGet, url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// do sends a manifest request and maps error statuses. A Bearer challenge
+// is answered with an anonymous token and the request retried once.
+func (c *Client) do(ctx context.Context, method, url string) (*http.Response, error) {
+	return c.doWithHeaders(ctx, method, url, nil)
+}
+
+// doWithHeaders is do with extra request headers. A 304 Not Modified answer
+// to a conditional request is returned like a success.
+func (c *Client) doWithHeaders(ctx context.Context, method, url string, header http.Header) (*http.Response, error) {
+	repo := repository(url)
+	token, _ := c.tokens.get(repo)
+
+	resp, err := c.send(ctx, method, url, token, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		if ch, ok := parseChallenge(resp.Header.Get("WWW-Authenticate")); ok {
+			resp.Body.Close()
+			if ch.scope == "" && challengeVendor(url, ch).defaultScope {
+				ch.scope = pullScope(repo)
+			}
+			tok, err := c.fetchToken(ctx, ch)
+			if err != nil {
+				return nil, err
+			}
+			c.tokens.put(repo, ch, tok)
+			if resp, err = c.send(ctx, method, url, tok.token, header); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return resp, nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		resp.Body.Close()
+		return nil, ErrUnauthorized
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode >= 300:
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry returned %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// send performs a single registry request
+func (c *Client) send(ctx context.Context, method, url, token string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	req.Header.Set("User-Agent", "DockWarden/"+meta.Version)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query registry: %w", err)
+	}
+	return resp, nil
+}
+
+// repository returns the registry and repository part of an API URL, e.g.
+// "https://ghcr.io/v2/owner/app" for a manifest, blob, referrers or tag list
+// URL
+func repository(url string) string {
+	for _, sep := range []string{"/manifests/", "/blobs/", "/referrers/", "/tags/list"} {
+		if i := strings.LastIndex(url, sep); i != -1 {
+			return url[:i]
+		}
+	}
+	return url
+}
+
+// Domain returns the registry domain of an image reference, e.g. docker.io
+// or ghcr.io. Unparsable references return "unknown".
+func Domain(imageName string) string {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "unknown"
+	}
+	return reference.Domain(named)
+}
+
+// registryURL returns the API base URL for a registry domain
+func registryURL(domain string) string {
+	if domain == "docker.io" {
+		domain = "registry-1.docker.io"
+	}
+	// Local registries are commonly served without TLS
+	host := domain
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		host = host[:i]
+	}
+	if host == "localhost" || host == "127.0.0.1" {
+		return "http://" + domain
+	}
+	return "https://" + domain
+}
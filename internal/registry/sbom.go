@@ -0,0 +1,121 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+
+	"github.com/distribution/reference"
+)
+
+// SBOM formats
+const (
+	SBOMSPDX      = "spdx"
+	SBOMCycloneDX = "cyclonedx"
+)
+
+// sbomPredicates maps in-toto predicate types to SBOM formats
+var sbomPredicates = map[string]string{
+	"https://spdx.dev/Document": SBOMSPDX,
+	"https://cyclonedx.org/bom": SBOMCycloneDX,
+}
+
+// maxSBOMSize bounds the attestation blobs read
+const maxSBOMSize = 64 << 20
+
+// index is the subset of an OCI image index needed to find attestations
+type index struct {
+	Manifests []struct {
+		Digest      string            `json:"digest"`
+		Platform    *platform         `json:"platform"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"manifests"`
+}
+
+type platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+}
+
+type manifest struct {
+	Layers []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+// ErrNoSBOM is returned when the image has no SBOM attestation
+var ErrNoSBOM = errors.New("no SBOM attestation found")
+
+// SBOM fetches the SBOM attested for an image by BuildKit (docker buildx
+// build --sbom) for the platform DockWarden runs on. digest is the image's
+// index digest; the SBOM document and its format are returned.
+func (c *Client) SBOM(ctx context.Context, imageName, digest string) ([]byte, string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse image reference %q: %w", imageName, err)
+	}
+	base := fmt.Sprintf("%s/v2/%s", registryURL(reference.Domain(named)), reference.Path(named))
+
+	var idx index
+	if err := c.getJSON(ctx, base+"/manifests/"+digest, &idx); err != nil {
+		return nil, "", err
+	}
+
+	// Attestation manifests reference the platform manifest they describe
+	var platformDigest string
+	for _, m := range idx.Manifests {
+		if m.Platform != nil && m.Platform.OS == "linux" && m.Platform.Architecture == runtime.GOARCH {
+			platformDigest = m.Digest
+			break
+		}
+	}
+	var attestation string
+	for _, m := range idx.Manifests {
+		if m.Annotations["vnd.docker.reference.type"] == "attestation-manifest" && m.Annotations["vnd.docker.reference.digest"] == platformDigest {
+			attestation = m.Digest
+			break
+		}
+	}
+	if platformDigest == "" || attestation == "" {
+		return nil, "", ErrNoSBOM
+	}
+
+	var att manifest
+	if err := c.getJSON(ctx, base+"/manifests/"+attestation, &att); err != nil {
+		return nil, "", err
+	}
+
+	for _, layer := range att.Layers {
+		format, ok := sbomPredicates[layer.Annotations["in-toto.io/predicate-type"]]
+		if !ok {
+			continue
+		}
+		var statement struct {
+			Predicate json.RawMessage `json:"predicate"`
+		}
+		if err := c.getJSON(ctx, base+"/blobs/"+layer.Digest, &statement); err != nil {
+			return nil, "", err
+		}
+		return statement.Predicate, format, nil
+	}
+	return nil, "", ErrNoSBOM
+}
+
+// getJSON fetches and decodes a manifest or blob
+func (c *Client) getJSON(ctx context.Context, url string, v interface{}) error {
+	resp, err := c.do(ctx, http.MethodGet, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSBOMSize)).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
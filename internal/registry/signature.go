@@ -0,0 +1,313 @@
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+)
+
+// Notary v2 (Notation) signatures are OCI artifacts referring to the signed
+// manifest, whose single layer is a JWS or COSE signature envelope
+const (
+	notationArtifactType = "application/vnd.cncf.notary.signature"
+	notationPayloadType  = "application/vnd.cncf.notary.payload.v1+json"
+	jwsMediaType         = "application/jose+json"
+	coseMediaType        = "application/cose"
+)
+
+// notationCritical are the critical protected headers of a JWS envelope
+// that are understood; envelopes requiring others are rejected
+var notationCritical = []string{"io.cncf.notary.signingScheme", "io.cncf.notary.expiry"}
+
+// ErrUnsigned is returned when an image has no signature that verifies
+// against the trust store
+var ErrUnsigned = errors.New("no valid signature found")
+
+// referrers is the subset of the OCI referrers index needed to find
+// signatures
+type referrers struct {
+	Manifests []struct {
+		Digest       string `json:"digest"`
+		ArtifactType string `json:"artifactType"`
+	} `json:"manifests"`
+}
+
+// signatureManifest is the manifest of a Notation signature artifact
+type signatureManifest struct {
+	ArtifactType string `json:"artifactType"`
+	Config       struct {
+		MediaType string `json:"mediaType"`
+	} `json:"config"`
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"layers"`
+	Subject *struct {
+		Digest string `json:"digest"`
+	} `json:"subject"`
+}
+
+// jwsEnvelope is a Notation signature in the JWS JSON serialization
+type jwsEnvelope struct {
+	Payload   string `json:"payload"`
+	Protected string `json:"protected"`
+	Header    struct {
+		X5C []string `json:"x5c"`
+	} `json:"header"`
+	Signature string `json:"signature"`
+}
+
+type jwsProtected struct {
+	Alg           string     `json:"alg"`
+	Cty           string     `json:"cty"`
+	Crit          []string   `json:"crit"`
+	SigningScheme string     `json:"io.cncf.notary.signingScheme"`
+	Expiry        *time.Time `json:"io.cncf.notary.expiry"`
+}
+
+// LoadTrustStore reads the CA certificates signatures are verified against
+// from a PEM file, or from every file of a directory
+func LoadTrustStore(path string) (*x509.CertPool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trust store: %w", err)
+		}
+		files = files[:0]
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+
+	pool := x509.NewCertPool()
+	found := false
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trust store: %w", err)
+		}
+		if pool.AppendCertsFromPEM(data) {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// VerifySignature checks that the manifest digest of an image is signed
+// with Notation by a certificate chaining up to roots. Signatures are found
+// through the referrers API, or the sha256-<hex> tag of registries without
+// it. The subject of the signing certificate is returned.
+func (c *Client) VerifySignature(ctx context.Context, imageName, digest string, roots *x509.CertPool) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference %q: %w", imageName, err)
+	}
+	base := fmt.Sprintf("%s/v2/%s", registryURL(reference.Domain(named)), reference.Path(named))
+
+	var refs referrers
+	err = c.getJSON(ctx, base+"/referrers/"+digest+"?artifactType="+url.QueryEscape(notationArtifactType), &refs)
+	if errors.Is(err, ErrNotFound) {
+		err = c.getJSON(ctx, base+"/manifests/"+strings.Replace(digest, ":", "-", 1), &refs)
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrUnsigned
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to list signatures: %w", err)
+	}
+
+	var reason error = ErrUnsigned
+	for _, m := range refs.Manifests {
+		// The filter is optional for registries, and the tag schema index
+		// lists every referrer
+		if m.ArtifactType != "" && m.ArtifactType != notationArtifactType {
+			continue
+		}
+		signer, err := c.verifySignatureManifest(ctx, base, m.Digest, digest, roots)
+		if err == nil {
+			return signer, nil
+		}
+		reason = fmt.Errorf("%w: %w", ErrUnsigned, err)
+	}
+	return "", reason
+}
+
+// verifySignatureManifest verifies the signature envelope of a signature
+// artifact referring to digest
+func (c *Client) verifySignatureManifest(ctx context.Context, base, manifestDigest, digest string, roots *x509.CertPool) (string, error) {
+	var sig signatureManifest
+	if err := c.getJSON(ctx, base+"/manifests/"+manifestDigest, &sig); err != nil {
+		return "", err
+	}
+	if sig.ArtifactType != notationArtifactType && sig.Config.MediaType != notationArtifactType {
+		return "", errors.New("not a Notation signature")
+	}
+	if sig.Subject == nil || sig.Subject.Digest != digest || len(sig.Layers) != 1 {
+		return "", errors.New("signature doesn't refer to the image")
+	}
+
+	layer := sig.Layers[0]
+	switch layer.MediaType {
+	case jwsMediaType:
+	case coseMediaType:
+		return "", errors.New("COSE signature envelopes aren't supported")
+	default:
+		return "", fmt.Errorf("unknown signature envelope %s", layer.MediaType)
+	}
+	var envelope jwsEnvelope
+	if err := c.getJSON(ctx, base+"/blobs/"+layer.Digest, &envelope); err != nil {
+		return "", err
+	}
+	return verifyJWS(envelope, digest, roots, time.Now())
+}
+
+// verifyJWS verifies a Notation JWS envelope signing digest at a time
+func verifyJWS(envelope jwsEnvelope, digest string, roots *x509.CertPool, now time.Time) (string, error) {
+	protectedJSON, err := base64.RawURLEncoding.DecodeString(envelope.Protected)
+	if err != nil {
+		return "", fmt.Errorf("invalid protected header: %w", err)
+	}
+	var protected jwsProtected
+	if err := json.Unmarshal(protectedJSON, &protected); err != nil {
+		return "", fmt.Errorf("invalid protected header: %w", err)
+	}
+	if protected.Cty != notationPayloadType {
+		return "", fmt.Errorf("unknown payload type %q", protected.Cty)
+	}
+	if protected.SigningScheme != "notary.x509" {
+		return "", fmt.Errorf("unsupported signing scheme %q", protected.SigningScheme)
+	}
+	for _, header := range protected.Crit {
+		if !slices.Contains(notationCritical, header) {
+			return "", fmt.Errorf("unsupported critical header %q", header)
+		}
+	}
+	if protected.Expiry != nil && now.After(*protected.Expiry) {
+		return "", fmt.Errorf("signature expired at %s", protected.Expiry.Format(time.RFC3339))
+	}
+
+	if len(envelope.Header.X5C) == 0 {
+		return "", errors.New("signature has no certificate chain")
+	}
+	var chain []*x509.Certificate
+	for _, encoded := range envelope.Header.X5C {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("invalid certificate chain: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return "", fmt.Errorf("invalid certificate chain: %w", err)
+		}
+		chain = append(chain, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	leaf := chain[0]
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return "", fmt.Errorf("untrusted signing certificate: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(envelope.Signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+	if err := verifyJWSSignature(protected.Alg, leaf.PublicKey, []byte(envelope.Protected+"."+envelope.Payload), signature); err != nil {
+		return "", err
+	}
+
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid payload: %w", err)
+	}
+	var payload struct {
+		TargetArtifact struct {
+			Digest string `json:"digest"`
+		} `json:"targetArtifact"`
+	}
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		return "", fmt.Errorf("invalid payload: %w", err)
+	}
+	if payload.TargetArtifact.Digest != digest {
+		return "", fmt.Errorf("signature is for %s", payload.TargetArtifact.Digest)
+	}
+	return leaf.Subject.String(), nil
+}
+
+// verifyJWSSignature checks a JWS signature over input with the PS and ES
+// algorithms Notation signs with
+func verifyJWSSignature(alg string, key crypto.PublicKey, input, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "PS256", "ES256":
+		hash = crypto.SHA256
+	case "PS384", "ES384":
+		hash = crypto.SHA384
+	case "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(input)
+	sum := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "PS") {
+			return fmt.Errorf("algorithm %s doesn't match the RSA signing key", alg)
+		}
+		if err := rsa.VerifyPSS(key, hash, sum, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return errors.New("signature doesn't verify")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s doesn't match the ECDSA signing key", alg)
+		}
+		// JWS encodes ECDSA signatures as r || s of the curve's size
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("signature doesn't verify")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, sum, r, s) {
+			return errors.New("signature doesn't verify")
+		}
+	default:
+		return errors.New("unsupported signing key type")
+	}
+	return nil
+}
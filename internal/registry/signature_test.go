@@ -0,0 +1,283 @@
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	signedImage  = "registry.example.com/team/app:1.0"
+	signedDigest = "sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b"
+)
+
+// signingCA is a CA issuing code signing certificates
+type signingCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newSigningCA(t *testing.T, name string) signingCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return signingCA{cert: cert, key: key}
+}
+
+func (ca signingCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// sign returns a Notation JWS envelope signing digest with a leaf
+// certificate issued by the CA
+func (ca signingCA) sign(t *testing.T, digest string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "release signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	leaf, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	protected := encodeSegment(t, map[string]any{
+		"alg":                          "ES256",
+		"cty":                          notationPayloadType,
+		"crit":                         []string{"io.cncf.notary.signingScheme"},
+		"io.cncf.notary.signingScheme": "notary.x509",
+		"io.cncf.notary.signingTime":   time.Now().Format(time.RFC3339),
+	})
+	payload := encodeSegment(t, map[string]any{
+		"targetArtifact": map[string]any{
+			"mediaType": "application/vnd.oci.image.index.v1+json",
+			"digest":    digest,
+			"size":      1024,
+		},
+	})
+	sum := sha256.Sum256([]byte(protected + "." + payload))
+	r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	envelope, _ := json.Marshal(map[string]any{
+		"payload":   payload,
+		"protected": protected,
+		"header": map[string]any{
+			"x5c": []string{base64.StdEncoding.EncodeToString(leaf), base64.StdEncoding.EncodeToString(ca.cert.Raw)},
+		},
+		"signature": base64.RawURLEncoding.EncodeToString(signature),
+	})
+	return envelope
+}
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signedRegistry serves the signatures of signedImage's repository, through
+// the referrers API or, without it, the tag schema
+type signedRegistry struct {
+	referrersAPI bool
+	envelopes    [][]byte
+}
+
+func (reg signedRegistry) client(t *testing.T) *Client {
+	t.Helper()
+	objects := map[string][]byte{}
+	var refs referrers
+	for _, envelope := range reg.envelopes {
+		blob := fmt.Sprintf("sha256:%x", sha256.Sum256(envelope))
+		objects["/blobs/"+blob] = envelope
+		manifest, _ := json.Marshal(map[string]any{
+			"mediaType":    "application/vnd.oci.image.manifest.v1+json",
+			"artifactType": notationArtifactType,
+			"config":       map[string]any{"mediaType": "application/vnd.oci.empty.v1+json"},
+			"layers":       []map[string]any{{"mediaType": jwsMediaType, "digest": blob}},
+			"subject":      map[string]any{"digest": signedDigest},
+		})
+		digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+		objects["/manifests/"+digest] = manifest
+		refs.Manifests = append(refs.Manifests, struct {
+			Digest       string `json:"digest"`
+			ArtifactType string `json:"artifactType"`
+		}{digest, notationArtifactType})
+	}
+	index, _ := json.Marshal(refs)
+	if reg.referrersAPI {
+		objects["/referrers/"+signedDigest+"?artifactType="+url.QueryEscape(notationArtifactType)] = index
+	} else if len(reg.envelopes) > 0 {
+		objects["/manifests/"+strings.Replace(signedDigest, ":", "-", 1)] = index
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, ok := objects[strings.TrimPrefix(req.URL.RequestURI(), "/v2/team/app")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return NewClient(&http.Client{Transport: redirectTransport{target: target}})
+}
+
+func TestVerifySignature(t *testing.T) {
+	ca := newSigningCA(t, "release CA")
+	other := newSigningCA(t, "other CA")
+
+	tampered := ca.sign(t, signedDigest)
+	var envelope jwsEnvelope
+	json.Unmarshal(tampered, &envelope)
+	envelope.Payload = encodeSegment(t, map[string]any{"targetArtifact": map[string]any{"digest": signedDigest, "size": 1}})
+	tampered, _ = json.Marshal(envelope)
+
+	tests := []struct {
+		name     string
+		registry signedRegistry
+		wantErr  string
+	}{
+		{
+			name:     "referrers API",
+			registry: signedRegistry{referrersAPI: true, envelopes: [][]byte{ca.sign(t, signedDigest)}},
+		},
+		{
+			name:     "tag schema without the referrers API",
+			registry: signedRegistry{envelopes: [][]byte{ca.sign(t, signedDigest)}},
+		},
+		{
+			name:     "one trusted signature among others",
+			registry: signedRegistry{referrersAPI: true, envelopes: [][]byte{other.sign(t, signedDigest), ca.sign(t, signedDigest)}},
+		},
+		{
+			name:    "unsigned",
+			wantErr: "no valid signature found",
+		},
+		{
+			name:     "untrusted CA",
+			registry: signedRegistry{referrersAPI: true, envelopes: [][]byte{other.sign(t, signedDigest)}},
+			wantErr:  "untrusted signing certificate",
+		},
+		{
+			name:     "signature of another image",
+			registry: signedRegistry{referrersAPI: true, envelopes: [][]byte{ca.sign(t, "sha256:0000000000000000000000000000000000000000000000000000000000000000")}},
+			wantErr:  "signature is for sha256:0000",
+		},
+		{
+			name:     "tampered payload",
+			registry: signedRegistry{referrersAPI: true, envelopes: [][]byte{tampered}},
+			wantErr:  "signature doesn't verify",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.registry.client(t)
+			signer, err := c.VerifySignature(context.Background(), signedImage, signedDigest, ca.pool())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifySignature() error = %v", err)
+				}
+				if signer != "CN=release signer" {
+					t.Errorf("VerifySignature() signer = %q", signer)
+				}
+				return
+			}
+			if !errors.Is(err, ErrUnsigned) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifySignature() error = %v, want ErrUnsigned with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyJWSSignatureAlgorithm(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	sum := crypto.SHA256.New().Sum(nil)
+	r, s, _ := ecdsa.Sign(rand.Reader, key, sum)
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	if err := verifyJWSSignature("ES256", &key.PublicKey, nil, signature); err != nil {
+		t.Errorf("ES256: error = %v", err)
+	}
+	if err := verifyJWSSignature("PS256", &key.PublicKey, nil, signature); err == nil {
+		t.Error("PS256 with an ECDSA key: expected an error")
+	}
+	if err := verifyJWSSignature("none", &key.PublicKey, nil, signature); err == nil {
+		t.Error("alg none: expected an error")
+	}
+}
+
+func TestLoadTrustStore(t *testing.T) {
+	ca := newSigningCA(t, "release CA")
+	dir := t.TempDir()
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+	os.WriteFile(filepath.Join(dir, "release-ca.pem"), certPEM, 0o644)
+	os.WriteFile(filepath.Join(dir, "README"), []byte("trusted signing CAs"), 0o644)
+
+	for _, path := range []string{dir, filepath.Join(dir, "release-ca.pem")} {
+		if _, err := LoadTrustStore(path); err != nil {
+			t.Errorf("LoadTrustStore(%q) error = %v", path, err)
+		}
+	}
+	if _, err := LoadTrustStore(filepath.Join(dir, "README")); err == nil {
+		t.Error("LoadTrustStore() of a file without certificates: expected an error")
+	}
+	if _, err := LoadTrustStore(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("LoadTrustStore() of a missing file: expected an error")
+	}
+}
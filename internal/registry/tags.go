@@ -0,0 +1,164 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+)
+
+// maxTagPages bounds how many pages of a tag list are followed
+const maxTagPages = 100
+
+// DefaultTagCacheTTL is how long a tag list is served from the cache before
+// the registry is asked again
+const DefaultTagCacheTTL = time.Hour
+
+// tagCache keeps tag lists per repository with the validators of their
+// first page, so large repositories aren't enumerated again every cycle and
+// an expired entry is revalidated with a conditional request
+type tagCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]tagEntry
+}
+
+type tagEntry struct {
+	tags         []string
+	etag         string
+	lastModified string
+	fetched      time.Time
+}
+
+func (t *tagCache) get(key string) (tagEntry, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[key]
+	return entry, ok
+}
+
+func (t *tagCache) put(key string, entry tagEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.entries == nil {
+		t.entries = make(map[string]tagEntry)
+	}
+	t.entries[key] = entry
+}
+
+func (t *tagCache) lifetime() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.ttl
+}
+
+// SetTagCacheTTL sets how long tag lists are cached. With zero every call
+// revalidates the cached list with a conditional request.
+func (c *Client) SetTagCacheTTL(ttl time.Duration) {
+	c.tags.mu.Lock()
+	defer c.tags.mu.Unlock()
+	c.tags.ttl = ttl
+}
+
+// Tags returns the tags the registry lists for the repository of an image
+// reference, following the pagination Link headers of the distribution API.
+// Lists are cached per repository; once an entry expires, the registry is
+// asked with If-None-Match/If-Modified-Since and a 304 answer keeps the
+// cached list without enumerating the pages again.
+func (c *Client) Tags(ctx context.Context, imageName string) ([]string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image reference %q: %w", imageName, err)
+	}
+
+	domain := reference.Domain(named)
+	key := registryURL(domain) + "/v2/" + reference.Path(named)
+	cached, ok := c.tags.get(key)
+	if ok && time.Since(cached.fetched) < c.tags.lifetime() {
+		return cached.tags, nil
+	}
+
+	var header http.Header
+	if ok {
+		header = make(http.Header)
+		if cached.etag != "" {
+			header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	next := fmt.Sprintf("%s/tags/list?n=%d", key, vendorFor(domain).tagPageSize)
+	entry := tagEntry{fetched: time.Now()}
+	seen := make(map[string]bool)
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		resp, err := c.doWithHeaders(ctx, http.MethodGet, next, header)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			cached.fetched = entry.fetched
+			c.tags.put(key, cached)
+			return cached.tags, nil
+		}
+		if page == 0 {
+			entry.etag = resp.Header.Get("ETag")
+			entry.lastModified = resp.Header.Get("Last-Modified")
+			header = nil
+		}
+
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&body)
+		link := resp.Header.Get("Link")
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tag list: %w", err)
+		}
+
+		for _, tag := range body.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				entry.tags = append(entry.tags, tag)
+			}
+		}
+		next = nextPage(next, link)
+	}
+
+	c.tags.put(key, entry)
+	return entry.tags, nil
+}
+
+// nextPage returns the URL of the next page named by a Link header, e.g.
+// </v2/app/tags/list?last=v1.2&n=100>; rel="next". Docker Hub and Quay send
+// the link relative to the registry, so it is resolved against the current
+// page.
+func nextPage(current, link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			continue
+		}
+		target = strings.Trim(strings.TrimSpace(target), "<>")
+		base, err := url.Parse(current)
+		if err != nil {
+			return ""
+		}
+		ref, err := url.Parse(target)
+		if err != nil {
+			return ""
+		}
+		return base.ResolveReference(ref).String()
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/meta"
+)
+
+const (
+	// defaultTokenLifetime applies when a token response has no expires_in
+	defaultTokenLifetime = 60 * time.Second
+
+	// tokenExpiryMargin renews tokens shortly before they expire
+	tokenExpiryMargin = 10 * time.Second
+)
+
+// challenge is a parsed WWW-Authenticate Bearer challenge
+type challenge struct {
+	realm   string
+	service string
+	scope   string
+}
+
+// key identifies the tokens a challenge can be answered with
+func (ch challenge) key() string {
+	return ch.realm + "|" + ch.service + "|" + ch.scope
+}
+
+type bearerToken struct {
+	token   string
+	expires time.Time
+}
+
+// tokenCache holds anonymous bearer tokens per realm, service and scope.
+// Registries like ghcr.io and Docker Hub answer every anonymous API request
+// with 401 and a challenge; pulls are allowed for public images once the
+// client fetches a token from the named realm.
+type tokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]bearerToken
+	// scopes remembers the challenge of each repository, so later requests
+	// send the token up front instead of being challenged again
+	scopes map[string]challenge
+}
+
+// get returns an unexpired token for the repository, if any
+func (t *tokenCache) get(repo string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ch, ok := t.scopes[repo]
+	if !ok {
+		return "", false
+	}
+	tok, ok := t.tokens[ch.key()]
+	if !ok || time.Now().After(tok.expires) {
+		return "", false
+	}
+	return tok.token, true
+}
+
+func (t *tokenCache) put(repo string, ch challenge, tok bearerToken) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tokens == nil {
+		t.tokens = make(map[string]bearerToken)
+		t.scopes = make(map[string]challenge)
+	}
+	t.scopes[repo] = ch
+	t.tokens[ch.key()] = tok
+}
+
+// parseChallenge parses a WWW-Authenticate header of the Bearer scheme, e.g.
+// Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:owner/app:pull"
+func parseChallenge(header string) (challenge, bool) {
+	scheme, params, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return challenge{}, false
+	}
+
+	var ch challenge
+	for params != "" {
+		var key, value string
+		key, params, _ = strings.Cut(params, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		params = strings.TrimSpace(params)
+		if strings.HasPrefix(params, `"`) {
+			end := strings.Index(params[1:], `"`)
+			if end < 0 {
+				return challenge{}, false
+			}
+			value, params = params[1:end+1], params[end+2:]
+		} else {
+			value, params, _ = strings.Cut(params, ",")
+		}
+		params = strings.TrimPrefix(strings.TrimSpace(params), ",")
+
+		switch key {
+		case "realm":
+			ch.realm = value
+		case "service":
+			ch.service = value
+		case "scope":
+			ch.scope = value
+		}
+	}
+	return ch, ch.realm != ""
+}
+
+// fetchToken requests an anonymous token for the challenge
+func (c *Client) fetchToken(ctx context.Context, ch challenge) (bearerToken, error) {
+	realm, err := url.Parse(ch.realm)
+	if err != nil || (realm.Scheme != "https" && realm.Scheme != "http") {
+		return bearerToken{}, fmt.Errorf("invalid token realm %q", ch.realm)
+	}
+	q := realm.Query()
+	if ch.service != "" {
+		q.Set("service", ch.service)
+	}
+	if ch.scope != "" {
+		q.Set("scope", ch.scope)
+	}
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return bearerToken{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("User-Agent", "DockWarden/"+meta.Version)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return bearerToken{}, fmt.Errorf("failed to request registry token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return bearerToken{}, ErrUnauthorized
+	}
+	if resp.StatusCode >= 300 {
+		return bearerToken{}, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return bearerToken{}, fmt.Errorf("failed to decode registry token: %w", err)
+	}
+
+	tok := bearerToken{token: body.Token}
+	if tok.token == "" {
+		tok.token = body.AccessToken
+	}
+	if tok.token == "" {
+		return bearerToken{}, ErrUnauthorized
+	}
+	lifetime := defaultTokenLifetime
+	if body.ExpiresIn > 0 {
+		lifetime = time.Duration(body.ExpiresIn) * time.Second
+	}
+	tok.expires = time.Now().Add(lifetime - tokenExpiryMargin)
+	return tok, nil
+}
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"net/url"
+	"strings"
+)
+
+// vendor holds the behaviour that differs between registry implementations
+type vendor struct {
+	// tagPageSize is the page size requested for tag lists; Quay rejects
+	// or caps larger pages
+	tagPageSize int
+	// defaultScope fills in the scope of Bearer challenges that omit it.
+	// GitLab and Harbor answer requests to some endpoints with a challenge
+	// naming only the realm and service, and then issue a token without
+	// any access.
+	defaultScope bool
+}
+
+var (
+	genericVendor = vendor{tagPageSize: 1000}
+	quayVendor    = vendor{tagPageSize: 100}
+	gitlabVendor  = vendor{tagPageSize: 1000, defaultScope: true}
+	harborVendor  = vendor{tagPageSize: 1000, defaultScope: true}
+)
+
+// vendorFor guesses the registry implementation from its domain. Self-hosted
+// GitLab and Harbor instances are recognized by their usual host names, and
+// otherwise by their token realms once challenged (see vendorForRealm).
+func vendorFor(domain string) vendor {
+	host := strings.ToLower(domain)
+	switch {
+	case host == "quay.io" || strings.HasPrefix(host, "quay."):
+		return quayVendor
+	case host == "registry.gitlab.com" || strings.Contains(host, "gitlab"):
+		return gitlabVendor
+	case strings.Contains(host, "harbor"):
+		return harborVendor
+	default:
+		return genericVendor
+	}
+}
+
+// vendorForRealm recognizes self-hosted GitLab (/jwt/auth) and Harbor
+// (/service/token) registries by the token realm of their challenge
+func vendorForRealm(realm string) (vendor, bool) {
+	switch {
+	case strings.HasSuffix(realm, "/jwt/auth"):
+		return gitlabVendor, true
+	case strings.HasSuffix(realm, "/service/token"):
+		return harborVendor, true
+	default:
+		return vendor{}, false
+	}
+}
+
+// challengeVendor returns the vendor of the registry serving rawURL, taking
+// the challenge's realm into account for self-hosted instances
+func challengeVendor(rawURL string, ch challenge) vendor {
+	if v, ok := vendorForRealm(ch.realm); ok {
+		return v
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		return vendorFor(u.Host)
+	}
+	return genericVendor
+}
+
+// pullScope returns the pull scope of a repository API URL, e.g.
+// "repository:group/subgroup/app:pull" for GitLab's nested group images
+func pullScope(repo string) string {
+	_, path, ok := strings.Cut(repo, "/v2/")
+	if !ok {
+		return ""
+	}
+	return "repository:" + path + ":pull"
+}
@@ -0,0 +1,218 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// exchange is a request to a registry or token service and the response
+// that was recorded for it. Requests are matched on host, method, path with
+// query and the Authorization header.
+type exchange struct {
+	Host          string            `json:"host"`
+	Method        string            `json:"method"`
+	Path          string            `json:"path"`
+	Authorization string            `json:"authorization,omitempty"`
+	Status        int               `json:"status"`
+	Header        map[string]string `json:"header,omitempty"`
+	Body          json.RawMessage   `json:"body,omitempty"`
+}
+
+// replay serves the exchanges recorded in a testdata fixture and fails the
+// test on any request that wasn't recorded
+type replay struct {
+	t         *testing.T
+	exchanges []exchange
+
+	mu     sync.Mutex
+	served []string
+}
+
+func newReplay(t *testing.T, fixture string) *replay {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &replay{t: t}
+	if err := json.Unmarshal(data, &r.exchanges); err != nil {
+		t.Fatalf("failed to parse %s: %v", fixture, err)
+	}
+	return r
+}
+
+func (r *replay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	request := req.Host + " " + req.Method + " " + req.URL.RequestURI()
+	r.mu.Lock()
+	r.served = append(r.served, request)
+	r.mu.Unlock()
+
+	for _, e := range r.exchanges {
+		if e.Host != req.Host || e.Method != req.Method || e.Path != req.URL.RequestURI() || e.Authorization != req.Header.Get("Authorization") {
+			continue
+		}
+		for name, value := range e.Header {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(e.Status)
+		if len(e.Body) > 0 {
+			w.Write(e.Body)
+		}
+		return
+	}
+	r.t.Errorf("unexpected request %s (authorization %q)", request, req.Header.Get("Authorization"))
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// requests returns the requests served so far, as "host method path"
+func (r *replay) requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.served)
+}
+
+// client returns a registry client whose requests to any host are served
+// by the replay, so registries are recognized by their real domains
+func (r *replay) client(t *testing.T) *Client {
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return NewClient(&http.Client{Transport: redirectTransport{target: target}})
+}
+
+// redirectTransport sends every request to target, keeping the original
+// host in the Host header
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Host = req.URL.Host
+	out.URL.Scheme = rt.target.Scheme
+	out.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(out)
+}
+
+func TestHarborProjectPath(t *testing.T) {
+	r := newReplay(t, "harbor.json")
+	c := r.client(t)
+	ctx := context.Background()
+	image := "harbor.example.com/dockerhub-proxy/library/nginx:1.27"
+
+	// Harbor's challenge names no scope; the pull scope of the project path
+	// has to be requested explicitly
+	digest, err := c.Digest(ctx, image)
+	if err != nil {
+		t.Fatalf("Digest() error = %v", err)
+	}
+	if want := "sha256:461fece1eb3086e4092949a8e3488b7fbb0ebd02f5ac3a300c0b9ad3a2eb6df1"; digest != want {
+		t.Errorf("Digest() = %s, want %s", digest, want)
+	}
+
+	// The token is reused for the tag list of the same repository
+	tags, err := c.Tags(ctx, image)
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	if want := []string{"1.26", "1.27", "latest"}; !slices.Equal(tags, want) {
+		t.Errorf("Tags() = %v, want %v", tags, want)
+	}
+	if got := len(r.requests()); got != 4 {
+		t.Errorf("sent %d requests, want 4: %v", got, r.requests())
+	}
+}
+
+func TestQuayTagPagination(t *testing.T) {
+	r := newReplay(t, "quay.json")
+	c := r.client(t)
+
+	// Quay caps pages at 100 tags and links the next page by an opaque
+	// token; the tag repeated across pages is listed once
+	tags, err := c.Tags(context.Background(), "quay.io/example/app:v1.0.0")
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	want := []string{"v1.0.0", "v1.0.1", "v1.1.0", "v1.2.0", "v2.0.0-rc.1", "v2.0.0", "latest"}
+	if !slices.Equal(tags, want) {
+		t.Errorf("Tags() = %v, want %v", tags, want)
+	}
+
+	// Served from the cache within its lifetime
+	served := len(r.requests())
+	if _, err := c.Tags(context.Background(), "quay.io/example/app:v2.0.0"); err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	if got := len(r.requests()); got != served {
+		t.Errorf("cached tag list sent %d more requests", got-served)
+	}
+}
+
+func TestGitLabNestedGroup(t *testing.T) {
+	r := newReplay(t, "gitlab.json")
+	c := r.client(t)
+	ctx := context.Background()
+	image := "registry.example.com/group/subgroup/app:2.0"
+
+	// A self-hosted GitLab is recognized by its /jwt/auth realm, and the
+	// scope covers the whole nested group path
+	digest, err := c.Digest(ctx, image)
+	if err != nil {
+		t.Fatalf("Digest() error = %v", err)
+	}
+	if want := "sha256:cd4fe327ca4670f4afadf9fcce53ca23282d0671a825b386e84ffb733b2ecbb5"; digest != want {
+		t.Errorf("Digest() = %s, want %s", digest, want)
+	}
+
+	tags, err := c.Tags(ctx, image)
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	if want := []string{"1.9", "2.0"}; !slices.Equal(tags, want) {
+		t.Errorf("Tags() = %v, want %v", tags, want)
+	}
+}
+
+func TestVendorFor(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   vendor
+	}{
+		{"quay.io", quayVendor},
+		{"quay.example.com", quayVendor},
+		{"registry.gitlab.com", gitlabVendor},
+		{"gitlab-registry.example.com", gitlabVendor},
+		{"harbor.example.com", harborVendor},
+		{"ghcr.io", genericVendor},
+		{"docker.io", genericVendor},
+	}
+	for _, tt := range tests {
+		if got := vendorFor(tt.domain); got != tt.want {
+			t.Errorf("vendorFor(%q) = %+v, want %+v", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestPullScope(t *testing.T) {
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{"https://registry.gitlab.com/v2/group/subgroup/app", "repository:group/subgroup/app:pull"},
+		{"https://harbor.example.com/v2/project/app", "repository:project/app:pull"},
+		{"https://example.com/app", ""},
+	}
+	for _, tt := range tests {
+		if got := pullScope(tt.repo); got != tt.want {
+			t.Errorf("pullScope(%q) = %q, want %q", tt.repo, got, tt.want)
+		}
+	}
+}
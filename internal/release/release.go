@@ -0,0 +1,159 @@
+// Package release checks GitHub for newer releases of DockWarden itself.
+// Requests carry no version, host or configuration details.
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIURL is the GitHub API endpoint of the latest DockWarden release
+const DefaultAPIURL = "https://api.github.com/repos/emon5122/dockwarden/releases/latest"
+
+// Status is the outcome of the last release check
+type Status struct {
+	// Current is the running version
+	Current string `json:"current"`
+	// Latest is the tag of the latest published release
+	Latest string `json:"latest"`
+	// URL is the release page of Latest
+	URL       string    `json:"url"`
+	Published time.Time `json:"published"`
+	// UpdateAvailable is set when Latest is newer than Current. Development
+	// builds are never reported as outdated.
+	UpdateAvailable bool      `json:"update_available"`
+	Checked         time.Time `json:"checked"`
+}
+
+// Checker looks up the latest release and keeps the last result
+type Checker struct {
+	http    *http.Client
+	apiURL  string
+	current string
+
+	mu     sync.RWMutex
+	status *Status
+}
+
+// githubRelease is the part of a GitHub release the check reads
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+}
+
+// New creates a Checker comparing releases with the running version current
+func New(current string) *Checker {
+	return &Checker{
+		http:    &http.Client{Timeout: 15 * time.Second},
+		apiURL:  DefaultAPIURL,
+		current: current,
+	}
+}
+
+// Status returns the result of the last successful check, nil before one
+// succeeded
+func (c *Checker) Status() *Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// Check queries the latest release and stores the result
+func (c *Checker) Check(ctx context.Context) (*Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release request: %w", err)
+	}
+	// No version in the user agent: the check must not tell GitHub what runs
+	req.Header.Set("User-Agent", "DockWarden")
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GitHub releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub releases returned status %d", resp.StatusCode)
+	}
+
+	var latest githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if latest.TagName == "" || latest.Draft || latest.Prerelease {
+		return nil, fmt.Errorf("GitHub returned no published release")
+	}
+
+	status := &Status{
+		Current:         c.current,
+		Latest:          latest.TagName,
+		URL:             latest.HTMLURL,
+		Published:       latest.PublishedAt,
+		UpdateAvailable: Newer(latest.TagName, c.current),
+		Checked:         time.Now(),
+	}
+	c.mu.Lock()
+	c.status = status
+	c.mu.Unlock()
+	return status, nil
+}
+
+// Newer reports whether version a is newer than version b. Both are
+// compared as dotted numeric versions with an optional "v" prefix;
+// pre-release and build suffixes are ignored. A version that doesn't parse
+// is never newer nor older.
+func Newer(a, b string) bool {
+	va, ok := parse(a)
+	if !ok {
+		return false
+	}
+	vb, ok := parse(b)
+	if !ok {
+		return false
+	}
+	for i := range max(len(va), len(vb)) {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// parse splits a version like v1.4.2-rc.1 into its numbers (1, 4, 2)
+func parse(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
@@ -3,30 +3,57 @@ package scheduler
 import (
 	"time"
 
+	"github.com/emon5122/dockwarden/internal/clock"
 	"github.com/emon5122/dockwarden/internal/config"
 	"github.com/robfig/cron/v3"
 	log "github.com/sirupsen/logrus"
 )
 
-// Scheduler manages the timing of update checks
+// Options describe when a Scheduler runs its function
+type Options struct {
+	// Name identifies the job in log messages
+	Name string
+	// Schedule is a cron expression with seconds; it overrides Interval
+	Schedule string
+	// Interval is the time between runs when no Schedule is set
+	Interval time.Duration
+	// RunOnStart runs the function immediately when interval scheduling
+	// starts instead of waiting for the first tick
+	RunOnStart bool
+}
+
+// Scheduler manages the timing of update checks and other periodic jobs
 type Scheduler struct {
-	config   *config.Config
+	opts     Options
+	clock    clock.Clock
 	cron     *cron.Cron
-	ticker   *time.Ticker
+	ticker   clock.Ticker
 	stopChan chan struct{}
 }
 
-// New creates a new scheduler
-func New(cfg *config.Config) *Scheduler {
+// New creates the scheduler of update cycles driven by the given clock
+// (clock.Real in production). Cron schedules always follow the wall clock.
+func New(cfg *config.Config, clk clock.Clock) *Scheduler {
+	return NewWithOptions(Options{
+		Name:       "updates",
+		Schedule:   cfg.Schedule,
+		Interval:   cfg.Interval,
+		RunOnStart: true,
+	}, clk)
+}
+
+// NewWithOptions creates a scheduler for an arbitrary job
+func NewWithOptions(opts Options, clk clock.Clock) *Scheduler {
 	return &Scheduler{
-		config:   cfg,
+		opts:     opts,
+		clock:    clk,
 		stopChan: make(chan struct{}),
 	}
 }
 
 // Start begins the scheduler
 func (s *Scheduler) Start(fn func()) {
-	if s.config.Schedule != "" {
+	if s.opts.Schedule != "" {
 		s.startCron(fn)
 	} else {
 		s.startInterval(fn)
@@ -50,27 +77,32 @@ func (s *Scheduler) Stop() {
 func (s *Scheduler) startCron(fn func()) {
 	s.cron = cron.New(cron.WithSeconds())
 
-	_, err := s.cron.AddFunc(s.config.Schedule, fn)
+	logger := log.WithFields(log.Fields{"job": s.opts.Name, "schedule": s.opts.Schedule})
+	_, err := s.cron.AddFunc(s.opts.Schedule, fn)
 	if err != nil {
-		log.Fatalf("Invalid cron schedule: %v", err)
+		logger.WithError(err).Fatal("Invalid cron schedule")
 	}
 
-	log.Infof("Scheduled updates with cron expression: %s", s.config.Schedule)
+	logger.Info("Scheduled with cron expression")
 	s.cron.Start()
 }
 
 // startInterval starts interval-based scheduling
 func (s *Scheduler) startInterval(fn func()) {
-	// Run immediately on start
-	fn()
+	if s.opts.RunOnStart {
+		fn()
+	}
 
-	s.ticker = time.NewTicker(s.config.Interval)
-	log.Infof("Scheduled updates every %s", s.config.Interval)
+	s.ticker = s.clock.NewTicker(s.opts.Interval)
+	log.WithFields(log.Fields{
+		"job":      s.opts.Name,
+		"interval": s.opts.Interval.String(),
+	}).Info("Scheduled at fixed interval")
 
 	go func() {
 		for {
 			select {
-			case <-s.ticker.C:
+			case <-s.ticker.C():
 				fn()
 			case <-s.stopChan:
 				return
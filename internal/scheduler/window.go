@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is a daily time range such as "22:00-06:00". A window whose end is
+// before its start wraps around midnight.
+type Window struct {
+	start, end time.Duration
+}
+
+// ParseWindow parses a "HH:MM-HH:MM" range. An empty string is the zero
+// Window, which never contains any time.
+func ParseWindow(s string) (Window, error) {
+	if s == "" {
+		return Window{}, nil
+	}
+
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid time window %q: expected HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid time window %q: %w", s, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid time window %q: %w", s, err)
+	}
+	if start == end {
+		return Window{}, fmt.Errorf("invalid time window %q: start and end are equal", s)
+	}
+	return Window{start: start, end: end}, nil
+}
+
+// IsZero reports whether the window is unset
+func (w Window) IsZero() bool {
+	return w.start == w.end
+}
+
+// Contains reports whether t, in its own location, falls inside the window
+func (w Window) Contains(t time.Time) bool {
+	if w.IsZero() {
+		return false
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// String renders the window as "HH:MM-HH:MM"
+func (w Window) String() string {
+	if w.IsZero() {
+		return ""
+	}
+	return formatClock(w.start) + "-" + formatClock(w.end)
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
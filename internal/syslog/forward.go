@@ -0,0 +1,47 @@
+package syslog
+
+import (
+	"github.com/emon5122/dockwarden/internal/events"
+	log "github.com/sirupsen/logrus"
+)
+
+// forwardBuffer is how many events may wait for the syslog server before
+// further events are dropped
+const forwardBuffer = 256
+
+// Forward sends every event published on broker to w until the returned
+// function is called, which waits for the events already received to be sent
+func Forward(broker *events.Broker, w *Writer) func() {
+	ch, cancel := broker.Subscribe(forwardBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range ch {
+			params := []Param{
+				{Name: "cycle_id", Value: e.CycleID},
+				{Name: "container", Value: e.Container},
+				{Name: "image", Value: e.Image},
+			}
+			if err := w.Send(severity(e.Type), e.Type, params, e.Message); err != nil {
+				log.WithError(err).WithField("event", e.Type).Warn("Failed to forward event to syslog")
+			}
+		}
+		w.Close()
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// severity maps an event type to a syslog severity
+func severity(eventType string) int {
+	switch eventType {
+	case events.TypeContainerFailed:
+		return SeverityError
+	case events.TypeContainerUpdate, events.TypeCycleDeferred, events.TypeAPIAction:
+		return SeverityNotice
+	default:
+		return SeverityInfo
+	}
+}
@@ -0,0 +1,202 @@
+// Package syslog forwards DockWarden events to a syslog server as RFC 5424
+// messages over UDP, TCP or TLS, so SIEMs can ingest them without an agent.
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Severities used for events
+const (
+	SeverityError   = 3
+	SeverityWarning = 4
+	SeverityNotice  = 5
+	SeverityInfo    = 6
+)
+
+// sdID identifies DockWarden's structured data element. 32473 is the
+// private enterprise number reserved for documentation (RFC 5612).
+const sdID = "dockwarden@32473"
+
+// dialTimeout bounds connecting to the syslog server
+const dialTimeout = 10 * time.Second
+
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// Param is a structured data parameter
+type Param struct {
+	Name  string
+	Value string
+}
+
+// Writer sends messages to a syslog server, reconnecting when a stream
+// connection breaks
+type Writer struct {
+	network  string
+	addr     string
+	tls      bool
+	facility int
+	hostname string
+	appName  string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// New creates a writer for rawURL, one of udp://host[:514],
+// tcp://host[:514] or tls://host[:6514], using the named facility
+func New(rawURL, facility string) (*Writer, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog URL: %w", err)
+	}
+
+	w := &Writer{network: u.Scheme, addr: u.Host, appName: "dockwarden"}
+	port := "514"
+	switch u.Scheme {
+	case "udp", "tcp":
+	case "tls":
+		w.network, w.tls, port = "tcp", true, "6514"
+	default:
+		return nil, fmt.Errorf("unsupported syslog URL scheme %q (use udp, tcp or tls)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("syslog URL %q has no host", rawURL)
+	}
+	if u.Port() == "" {
+		w.addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	f, ok := facilities[strings.ToLower(facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", facility)
+	}
+	w.facility = f
+
+	w.hostname, _ = os.Hostname()
+	if w.hostname == "" {
+		w.hostname = "-"
+	}
+	return w, nil
+}
+
+// Send writes a message. msgID names the kind of message, e.g. the event
+// type. A failed stream connection is reopened once before giving up.
+func (w *Writer) Send(severity int, msgID string, params []Param, msg string) error {
+	line := w.format(time.Now(), severity, msgID, params, msg)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if w.conn, err = w.dial(); err != nil {
+				return fmt.Errorf("failed to connect to syslog server: %w", err)
+			}
+		}
+		w.conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+		if _, err = w.conn.Write(w.frame(line)); err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	return fmt.Errorf("failed to send syslog message: %w", err)
+}
+
+// Close closes the connection
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *Writer) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if w.tls {
+		host, _, _ := net.SplitHostPort(w.addr)
+		return tls.DialWithDialer(dialer, "tcp", w.addr, &tls.Config{ServerName: host})
+	}
+	return dialer.Dial(w.network, w.addr)
+}
+
+// frame prefixes stream messages with their length (octet counting, RFC
+// 6587); datagrams carry one message each
+func (w *Writer) frame(line string) []byte {
+	if w.network == "udp" {
+		return []byte(line)
+	}
+	return []byte(fmt.Sprintf("%d %s", len(line), line))
+}
+
+// format renders an RFC 5424 message
+func (w *Writer) format(t time.Time, severity int, msgID string, params []Param, msg string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<%d>1 %s %s %s %d %s ",
+		w.facility*8+severity,
+		t.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		header(w.hostname, 255),
+		w.appName,
+		os.Getpid(),
+		header(msgID, 32),
+	)
+
+	if len(params) == 0 {
+		sb.WriteString("-")
+	} else {
+		sb.WriteString("[" + sdID)
+		for _, p := range params {
+			if p.Value == "" {
+				continue
+			}
+			fmt.Fprintf(&sb, ` %s="%s"`, p.Name, escapeParam(p.Value))
+		}
+		sb.WriteString("]")
+	}
+
+	if msg != "" {
+		sb.WriteString(" " + msg)
+	}
+	return sb.String()
+}
+
+// header makes s a valid header field: printable ASCII without spaces, at
+// most max characters, "-" when empty
+func header(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
+
+// escapeParam escapes the characters RFC 5424 reserves in parameter values
+func escapeParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
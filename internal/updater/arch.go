@@ -0,0 +1,53 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrArchMismatch is returned when an update is refused because the new
+// image was built for another platform than the running one
+var ErrArchMismatch = errors.New("new image was built for another architecture")
+
+// checkArch compares the platform of the image a container is about to be
+// recreated from with the platform of the image it runs, or of the host if
+// that image can't be inspected. A mismatch is logged, and refused with
+// BlockArchMismatch, instead of recreating into an "exec format error"
+// crash loop. Images of unknown platform are never refused.
+func (u *Updater) checkArch(ctx context.Context, ctr docker.Container, newImage string) error {
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "arch")
+
+	next, err := u.client.InspectImage(ctx, newImage)
+	if err != nil || next.Platform.Arch == "" {
+		logger.Debug("Platform of new image unknown, skipping architecture check")
+		return nil
+	}
+
+	var running docker.Platform
+	if current, err := u.client.InspectImage(ctx, ctr.ImageID); err == nil && current.Platform.Arch != "" {
+		running = current.Platform
+	} else if running, err = docker.HostPlatform(ctx, u.client); err != nil {
+		logger.WithError(err).Debug("Host platform unknown, skipping architecture check")
+		return nil
+	}
+
+	if next.Platform.OS == running.OS && next.Platform.Arch == running.Arch {
+		return nil
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"running_platform": running.String(),
+		"new_platform":     next.Platform.String(),
+	})
+	if u.config.BlockArchMismatch {
+		logger.Error("New image was built for another architecture, refusing update")
+		return fmt.Errorf("%w: %s instead of %s", ErrArchMismatch, next.Platform, running)
+	}
+	logger.Warn("New image was built for another architecture, the container may fail to start")
+	return nil
+}
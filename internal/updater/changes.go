@@ -0,0 +1,49 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/notify"
+)
+
+// imageInfo inspects the image a container runs before it is updated, while
+// cleanup hasn't removed it yet. Failures leave the info empty.
+func (u *Updater) imageInfo(ctx context.Context, imageID string) docker.ImageInfo {
+	if imageID == "" {
+		return docker.ImageInfo{}
+	}
+	info, err := u.client.InspectImage(ctx, imageID)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Debug("Failed to inspect image")
+	}
+	return info
+}
+
+// notifyUpdated sends the update notification of a container, naming the
+// image labels that changed and the creation times of both images
+func (u *Updater) notifyUpdated(ctx context.Context, result UpdateResult) {
+	if u.notifier == nil {
+		return
+	}
+	newImage := u.imageInfo(ctx, result.NewImageID)
+	u.notifier.NotifyContainerUpdated(result.ContainerName, result.Image, imageChange(result.OldImage, newImage, u.config.NotificationLabels))
+}
+
+// imageChange compares the selected labels of the old and new image
+func imageChange(oldImage, newImage docker.ImageInfo, labels []string) notify.ImageChange {
+	change := notify.ImageChange{
+		OldDigest:  oldImage.Digest,
+		NewDigest:  newImage.Digest,
+		OldCreated: oldImage.Created,
+		NewCreated: newImage.Created,
+	}
+	for _, key := range labels {
+		oldValue, newValue := oldImage.Labels[key], newImage.Labels[key]
+		if oldValue != newValue {
+			change.Labels = append(change.Labels, notify.LabelChange{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+	return change
+}
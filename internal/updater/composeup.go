@@ -0,0 +1,82 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/compose"
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// Recreate strategies
+const (
+	// RecreateInspect clones the runtime configuration of the container
+	RecreateInspect = "inspect"
+	// RecreateCompose runs "docker compose up" for compose-managed
+	// containers, falling back to RecreateInspect for everything else
+	RecreateCompose = "compose"
+)
+
+// composeService returns how to bring up a compose-managed container's
+// service, from the configured compose files or else the files compose
+// recorded on the container when they are readable here
+func (u *Updater) composeService(ctx context.Context, ctr docker.Container) (compose.UpOptions, bool) {
+	logger := logging.FromContext(ctx)
+	opts := compose.UpOptions{
+		Project:    ctr.GetLabel(compose.LabelProject),
+		ProjectDir: ctr.GetLabel(compose.LabelWorkingDir),
+		Service:    ctr.GetLabel(compose.LabelService),
+	}
+	if opts.Project == "" || opts.Service == "" {
+		return opts, false
+	}
+
+	if len(u.config.ComposeFiles) > 0 {
+		defs, err := compose.Load(u.config.ComposeFiles)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to read compose files")
+		} else if svc, ok := defs.Lookup(opts.Project, opts.Service); ok {
+			opts.Files = []string{svc.File}
+			return opts, true
+		}
+	}
+
+	for _, f := range strings.Split(ctr.GetLabel(compose.LabelConfigFiles), ",") {
+		if f == "" {
+			continue
+		}
+		if _, err := os.Stat(f); err != nil {
+			logger.WithField("compose_file", f).Debug("Compose file of container not readable")
+			return opts, false
+		}
+		opts.Files = append(opts.Files, f)
+	}
+	return opts, len(opts.Files) > 0
+}
+
+// composeUp recreates a container through compose and returns the ID of the
+// new container
+func (u *Updater) composeUp(ctx context.Context, ctr docker.Container, opts compose.UpOptions) (string, error) {
+	logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldAction: "update",
+		"compose_files":     opts.Files,
+		"service":           opts.Service,
+	}).Debug("Recreating container with compose")
+
+	if err := compose.Up(ctx, u.config.ComposeCommand, opts); err != nil {
+		return "", err
+	}
+
+	newCtr, err := u.client.GetContainer(ctx, ctr.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to find container after compose up: %w", err)
+	}
+	if newCtr.ID == ctr.ID {
+		return "", fmt.Errorf("compose up left the container in place, check that the compose file uses image %s", ctr.Image)
+	}
+	return newCtr.ID, nil
+}
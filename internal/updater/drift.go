@@ -0,0 +1,53 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/emon5122/dockwarden/internal/compose"
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// warnDrift compares a compose-managed container with its definition in the
+// configured compose files and warns when it was changed by hand, since
+// recreating it from its runtime configuration keeps those changes. It never
+// prevents the update.
+func (u *Updater) warnDrift(ctx context.Context, ctr docker.Container) {
+	project, service := ctr.Labels[compose.LabelProject], ctr.Labels[compose.LabelService]
+	if len(u.config.ComposeFiles) == 0 || project == "" || service == "" {
+		return
+	}
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "drift")
+
+	// Read on every check, so edits to the files are picked up
+	defs, err := compose.Load(u.config.ComposeFiles)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to read compose files")
+		return
+	}
+	svc, ok := defs.Lookup(project, service)
+	if !ok {
+		logger.WithFields(log.Fields{
+			"project": project,
+			"service": service,
+		}).Debug("Service not found in compose files")
+		return
+	}
+
+	running, details, err := u.client.InspectContainer(ctx, ctr.ID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to inspect container for drift")
+		return
+	}
+
+	drift := compose.Drift(svc, running, details)
+	if len(drift) == 0 {
+		logger.Debug("Container matches its compose definition")
+		return
+	}
+	logger.WithFields(log.Fields{
+		"compose_file": svc.File,
+		"drift":        drift,
+	}).Warn("Container has drifted from its compose definition; the update keeps the manual changes")
+}
@@ -0,0 +1,63 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/eol"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkEOL looks up the end-of-life status of the containers' tags, records
+// it and warns once per container and release cycle when it has ended
+func (u *Updater) checkEOL(ctx context.Context, containers []docker.Container) {
+	if u.eol == nil {
+		return
+	}
+
+	for _, ctr := range containers {
+		status, err := u.eol.Check(ctx, ctr.Image)
+		if err != nil {
+			logging.WithFields(ctx, containerFields(ctr)).WithError(err).Debug("Failed to check end of life")
+			continue
+		}
+
+		u.statusesMu.Lock()
+		tracked := u.status(ctr.Identity(), ctr.Name)
+		if tracked.Image == "" {
+			tracked.Image = ctr.Image
+		}
+		tracked.EOL = status
+		notified := tracked.eolNotified
+		if status != nil && status.Expired {
+			tracked.eolNotified = status.Product + ":" + status.Cycle
+		}
+		u.statusesMu.Unlock()
+
+		if status == nil || !status.Expired || notified == status.Product+":"+status.Cycle {
+			continue
+		}
+
+		logging.WithFields(ctx, containerFields(ctr)).WithFields(log.Fields{
+			"product": status.Product,
+			"cycle":   status.Cycle,
+		}).Warn("Container runs an end-of-life release")
+		if u.notifier != nil {
+			u.notifier.NotifyImageEOL(ctr.Name, ctr.Image, status.Product, status.Cycle, status.EOL)
+		}
+	}
+}
+
+// newEOLChecker creates the EOL checker when EOL checks are enabled
+func newEOLChecker(rules []string, enabled bool) *eol.Checker {
+	if !enabled {
+		return nil
+	}
+	checker, err := eol.New(rules)
+	if err != nil {
+		log.WithError(err).Warn("Invalid EOL rules, only checking well-known images")
+		checker, _ = eol.New(nil)
+	}
+	return checker
+}
@@ -0,0 +1,122 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/events"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+// ForceRecreateLabel gives a container its own forced recreate schedule (a
+// cron expression with seconds), or opts it out of the global one with
+// "false"
+const ForceRecreateLabel = "dockwarden.force-recreate"
+
+var cronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseRecreateSchedule validates a forced recreate schedule
+func ParseRecreateSchedule(expr string) (cron.Schedule, error) {
+	sched, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recreate schedule %q: %w", expr, err)
+	}
+	return sched, nil
+}
+
+// recreateSchedule returns the forced recreate schedule of a container: its
+// label, or the global ForceRecreateSchedule
+func (u *Updater) recreateSchedule(ctr docker.Container) string {
+	if expr := ctr.GetLabel(ForceRecreateLabel); expr != "" {
+		if expr == "false" {
+			return ""
+		}
+		return expr
+	}
+	return u.config.ForceRecreateSchedule
+}
+
+// ForceRecreate recreates the managed containers whose forced recreate
+// schedule fired in (since, now], with the image they run, whether or not
+// a newer one exists. It picks up changed mounted configuration and clears
+// state some services accumulate. Like update cycles it doesn't restart
+// containers in monitor only or no restart mode, and it holds the cycle
+// lock while recreating.
+func (u *Updater) ForceRecreate(since, now time.Time) {
+	cycleID := logging.NewCycleID()
+	logger := log.WithFields(log.Fields{
+		logging.FieldCycleID: cycleID,
+		logging.FieldAction:  "force_recreate",
+	})
+	ctx := logging.WithLogger(context.Background(), logger)
+
+	if !u.IsLeader() || u.config.MonitorOnly || u.config.NoRestart {
+		return
+	}
+
+	containers, err := u.client.ListContainers(ctx, docker.ListOptions{})
+	if err != nil {
+		logger.WithError(err).Error("Failed to list containers for forced recreates")
+		return
+	}
+
+	var due []docker.Container
+	for _, ctr := range u.filterContainers(ctx, containers, nil) {
+		expr := u.recreateSchedule(ctr)
+		if expr == "" {
+			continue
+		}
+		sched, err := ParseRecreateSchedule(expr)
+		if err != nil {
+			logging.WithFields(ctx, containerFields(ctr)).WithField("schedule", expr).WithError(err).Warn("Ignoring invalid forced recreate schedule")
+			continue
+		}
+		if !sched.Next(since).After(now) {
+			due = append(due, ctr)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	unlock, err := u.lockCycle(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("Skipping forced recreates")
+		return
+	}
+	defer unlock()
+
+	for _, ctr := range due {
+		// An update cycle may have replaced the container while the lock
+		// was held
+		if current, err := u.client.GetContainer(ctx, ctr.Name); err == nil {
+			ctr = current
+		}
+		clog := logging.WithFields(ctx, containerFields(ctr)).WithField("schedule", u.recreateSchedule(ctr))
+		clog.Info("Recreating container on its forced recreate schedule")
+		newID, err := u.recreate(logging.WithLogger(ctx, clog), ctr, "")
+		if err != nil {
+			clog.WithError(err).Error("Failed to force recreate container")
+			u.events.Publish(events.Event{
+				Type:      events.TypeContainerFailed,
+				CycleID:   cycleID,
+				Container: ctr.Name,
+				Image:     ctr.Image,
+				Message:   err.Error(),
+			})
+			continue
+		}
+		clog.WithField("new_id", truncateID(newID)).Info("Container recreated")
+		u.events.Publish(events.Event{
+			Type:      events.TypeContainerUpdate,
+			CycleID:   cycleID,
+			Container: ctr.Name,
+			Image:     ctr.Image,
+			Message:   "container recreated on schedule",
+		})
+	}
+}
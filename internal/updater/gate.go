@@ -0,0 +1,60 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/meta"
+	"github.com/emon5122/dockwarden/internal/redact"
+)
+
+// gateTimeout bounds the request to the gate URL
+const gateTimeout = 10 * time.Second
+
+// checkGate reports why the cycle has to be deferred, or "" when the gate is
+// open. The gate is closed while the gate file exists or the gate URL answers
+// anything but 200. An unreachable gate URL keeps it closed as well, so a
+// broken change freeze switch never lets updates through.
+func (u *Updater) checkGate(ctx context.Context) string {
+	if path := u.config.GateFile; path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return "gate file " + path + " exists"
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Sprintf("gate file %s cannot be checked: %v", path, err)
+		}
+	}
+
+	if url := u.config.GateURL; url != "" {
+		if err := checkGateURL(ctx, url); err != nil {
+			return err.Error()
+		}
+	}
+
+	return ""
+}
+
+func checkGateURL(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, gateTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create gate request: %w", err)
+	}
+	req.Header.Set("User-Agent", "DockWarden/"+meta.Version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gate URL is not reachable: %w", redact.Error(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gate URL returned status %d", resp.StatusCode)
+	}
+	return nil
+}
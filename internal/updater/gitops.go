@@ -0,0 +1,91 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/config"
+	"github.com/emon5122/dockwarden/internal/gitops"
+	"github.com/emon5122/dockwarden/internal/logging"
+)
+
+// gitopsTimeout bounds committing and pushing the changes of one cycle
+const gitopsTimeout = 2 * time.Minute
+
+// newGitOps returns the GitOps repository when one is configured. The local
+// clone is kept in the data directory.
+func newGitOps(cfg *config.Config) *gitops.Repo {
+	if cfg.GitOpsURL == "" {
+		return nil
+	}
+	return gitops.New(gitops.Options{
+		URL:      cfg.GitOpsURL,
+		Branch:   cfg.GitOpsBranch,
+		Path:     cfg.GitOpsPath,
+		Username: cfg.GitOpsUsername,
+		Token:    cfg.GitOpsToken,
+		SSHKey:   cfg.GitOpsSSHKey,
+		Author:   cfg.GitOpsAuthor,
+		Command:  cfg.GitOpsCommand,
+		WorkDir:  filepath.Join(cfg.DataDir, "gitops"),
+	})
+}
+
+// publishGitOps commits the lockfile and a changelog entry describing the
+// updated containers to the GitOps repository. Failures are logged, the
+// updates themselves stand.
+func (u *Updater) publishGitOps(ctx context.Context, updated []UpdateResult) {
+	if u.gitops == nil || len(updated) == 0 {
+		return
+	}
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "gitops")
+
+	change := gitops.Change{
+		Changelog: gitopsChangelog(time.Now().UTC(), updated),
+		Message:   gitopsMessage(updated),
+	}
+	if u.lockfile != nil {
+		data, err := os.ReadFile(u.lockfile.Path())
+		if err != nil {
+			logger.WithError(err).Warn("Failed to read lockfile for GitOps commit")
+		} else {
+			change.Files = map[string][]byte{filepath.Base(u.lockfile.Path()): data}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitopsTimeout)
+	defer cancel()
+	if err := u.gitops.Commit(ctx, change); err != nil {
+		logger.WithError(err).Error("Failed to commit updates to the GitOps repository")
+		return
+	}
+	logger.WithField("containers", len(updated)).Info("Committed updates to the GitOps repository")
+}
+
+// gitopsMessage is the subject of the commit recording updated containers
+func gitopsMessage(updated []UpdateResult) string {
+	names := make([]string, len(updated))
+	for i, result := range updated {
+		names[i] = result.ContainerName
+	}
+	if len(names) > 3 {
+		return fmt.Sprintf("Update %s and %d more", strings.Join(names[:3], ", "), len(names)-3)
+	}
+	return "Update " + strings.Join(names, ", ")
+}
+
+// gitopsChangelog is the changelog entry of updated containers
+func gitopsChangelog(now time.Time, updated []UpdateResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", now.Format("2006-01-02 15:04 MST"))
+	for _, result := range updated {
+		fmt.Fprintf(&b, "- %s: %s (%s → %s)\n", result.ContainerName, result.Image,
+			truncateID(strings.TrimPrefix(result.OldImageID, "sha256:")),
+			truncateID(strings.TrimPrefix(result.NewImageID, "sha256:")))
+	}
+	return b.String()
+}
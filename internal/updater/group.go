@@ -0,0 +1,417 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/pool"
+	log "github.com/sirupsen/logrus"
+)
+
+// containerGroup is a set of containers sharing a dockwarden.group label
+type containerGroup struct {
+	name    string
+	members []docker.Container
+}
+
+// groupMember tracks a group member through a group update
+type groupMember struct {
+	ctr         docker.Container
+	targetImage string
+	needsUpdate bool
+	// recreate is set for members that get a new container: those with a
+	// new image and those sharing the network stack of one
+	recreate bool
+	// network is the member whose network stack this one shares
+	network *groupMember
+	stopped bool
+	newID   string
+	result  UpdateResult
+}
+
+// splitGroups separates grouped containers from the rest. Groups are sorted
+// by name and their members are put in start order.
+func splitGroups(containers []docker.Container) ([]docker.Container, []containerGroup) {
+	var ungrouped []docker.Container
+	byName := make(map[string][]docker.Container)
+	for _, ctr := range containers {
+		if name := ctr.GetGroup(); name != "" {
+			byName[name] = append(byName[name], ctr)
+		} else {
+			ungrouped = append(ungrouped, ctr)
+		}
+	}
+
+	groups := make([]containerGroup, 0, len(byName))
+	for name, members := range byName {
+		groups = append(groups, containerGroup{name: name, members: startOrder(members)})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return ungrouped, groups
+}
+
+// startOrder sorts containers so that each one starts after the members it
+// names in dockwarden.depends-on. Ties and dependency cycles fall back to
+// name order.
+func startOrder(members []docker.Container) []docker.Container {
+	sorted := slices.Clone(members)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	inGroup := make(map[string]bool, len(sorted))
+	for _, ctr := range sorted {
+		inGroup[ctr.Name] = true
+	}
+
+	ordered := make([]docker.Container, 0, len(sorted))
+	placed := make(map[string]bool, len(sorted))
+	for len(ordered) < len(sorted) {
+		progress := false
+		for _, ctr := range sorted {
+			if placed[ctr.Name] || !dependenciesPlaced(ctr, inGroup, placed) {
+				continue
+			}
+			ordered = append(ordered, ctr)
+			placed[ctr.Name] = true
+			progress = true
+		}
+		if !progress {
+			// Dependency cycle: keep the remaining members in name order
+			for _, ctr := range sorted {
+				if !placed[ctr.Name] {
+					ordered = append(ordered, ctr)
+					placed[ctr.Name] = true
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+func dependenciesPlaced(ctr docker.Container, inGroup, placed map[string]bool) bool {
+	for _, dep := range ctr.DependsOn() {
+		if inGroup[dep] && !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// processGroups updates the groups one after another on a worker pool, so a
+// crash while updating a group fails its members instead of the process
+func (u *Updater) processGroups(ctx context.Context, groups []containerGroup) []UpdateResult {
+	perGroup := make([][]UpdateResult, len(groups))
+	workers := pool.New(pool.Options{Workers: 1, Metrics: u.poolMetrics})
+	for i, group := range groups {
+		gctx := logging.WithLogger(ctx, logging.WithFields(ctx, log.Fields{"group": group.name}))
+		task := func(ctx context.Context) error {
+			perGroup[i] = u.processGroup(ctx, group)
+			return nil
+		}
+		done := func(err error) {
+			if errors.Is(err, pool.ErrPanic) {
+				perGroup[i] = groupFailed(group, fmt.Errorf("group %s: %w", group.name, err))
+			}
+		}
+		if err := workers.Submit(gctx, task, done); err != nil {
+			perGroup[i] = groupFailed(group, err)
+		}
+	}
+	workers.Close()
+
+	var results []UpdateResult
+	for _, r := range perGroup {
+		results = append(results, r...)
+	}
+	return results
+}
+
+// groupFailed reports every member of a group as failed with err
+func groupFailed(group containerGroup, err error) []UpdateResult {
+	results := make([]UpdateResult, len(group.members))
+	for i, ctr := range group.members {
+		results[i] = UpdateResult{
+			ContainerID:   ctr.ID,
+			ContainerName: ctr.Name,
+			Identity:      ctr.Identity(),
+			Image:         ctr.Image,
+			Error:         err,
+		}
+	}
+	return results
+}
+
+// processGroup updates all members of a group together: images are pulled
+// for every member first, then the running members are stopped and started
+// again in order, recreating those with a new image and those sharing their
+// network stack. Members that weren't running stay stopped. If a member
+// fails, the members recreated so far are rolled back to their previous
+// images and the whole group is reported as failed.
+func (u *Updater) processGroup(ctx context.Context, group containerGroup) []UpdateResult {
+	logger := logging.FromContext(ctx)
+
+	members := make([]*groupMember, len(group.members))
+	for i, ctr := range group.members {
+		members[i] = &groupMember{
+			ctr: ctr,
+			result: UpdateResult{
+				ContainerID:   ctr.ID,
+				ContainerName: ctr.Name,
+				Identity:      ctr.Identity(),
+				Image:         ctr.Image,
+				OldImageID:    ctr.ImageID,
+			},
+		}
+	}
+
+	// Pull all: nothing is touched unless every member could be checked
+	anyUpdate := false
+	for _, m := range members {
+		mctx := memberContext(ctx, m.ctr)
+		targetImage, needsUpdate, err := u.checkMember(mctx, m.ctr)
+		if err != nil {
+			return failGroup(members, fmt.Errorf("group %s not updated: %w", group.name, err), m)
+		}
+		if needsUpdate {
+			newImage := m.ctr.Image
+			if targetImage != "" {
+				newImage = targetImage
+			}
+			if err := u.checkArch(mctx, m.ctr, newImage); err != nil {
+				return failGroup(members, fmt.Errorf("group %s not updated: %w", group.name, err), m)
+			}
+		}
+		m.targetImage = targetImage
+		m.needsUpdate = needsUpdate
+		anyUpdate = anyUpdate || needsUpdate
+	}
+
+	if !anyUpdate {
+		logger.WithField(logging.FieldAction, "check").Debug("Group is up to date")
+		return groupResults(members)
+	}
+
+	if u.config.MonitorOnly {
+		for _, m := range members {
+			m.result.UpdateAvailable = m.needsUpdate
+		}
+		logger.WithField(logging.FieldAction, "check").Info("Group update available (monitor only mode)")
+		return groupResults(members)
+	}
+
+	// No restart mode: the new images were pulled by the checks, the members
+	// keep running the old ones
+	if u.config.NoRestart {
+		for _, m := range members {
+			m.result.UpdateAvailable = m.needsUpdate
+		}
+		logger.WithField(logging.FieldAction, "check").Info("Group update pulled (no restart mode)")
+		return groupResults(members)
+	}
+
+	planRecreates(members)
+
+	logger.WithFields(log.Fields{
+		logging.FieldAction: "update",
+		"members":           len(members),
+	}).Info("Updating container group")
+
+	for _, m := range members {
+		if m.needsUpdate {
+			u.warnDrift(memberContext(ctx, m.ctr), m.ctr)
+		}
+	}
+
+	// Stop all, in reverse start order
+	for i := len(members) - 1; i >= 0; i-- {
+		m := members[i]
+		if !m.ctr.IsRunning() {
+			continue
+		}
+		if err := u.client.StopContainer(memberContext(ctx, m.ctr), m.ctr.ID, m.ctr.GetStopTimeout(u.config.StopTimeout)); err != nil {
+			u.rollbackGroup(ctx, members)
+			return failGroup(members, fmt.Errorf("group %s rolled back: failed to stop %s: %w", group.name, m.ctr.Name, err), m)
+		}
+		m.stopped = true
+	}
+
+	// Recreate or start again, in order
+	for _, m := range members {
+		mctx := memberContext(ctx, m.ctr)
+		if !m.recreate {
+			if !m.stopped {
+				continue
+			}
+			if err := u.client.StartContainer(mctx, m.ctr.ID); err != nil {
+				u.rollbackGroup(ctx, members)
+				return failGroup(members, fmt.Errorf("group %s rolled back: failed to start %s: %w", group.name, m.ctr.Name, err), m)
+			}
+			continue
+		}
+
+		m.result.OldImage = u.imageInfo(mctx, m.ctr.ImageID)
+		opts := docker.RecreateOptions{Image: m.targetImage, NoStart: !m.ctr.IsRunning()}
+		if m.network != nil && m.network.newID != "" {
+			opts.NetworkContainer = m.network.newID
+		}
+		newID, err := u.recreateWith(mctx, m.ctr, opts)
+		if err != nil {
+			u.rollbackGroup(ctx, members)
+			return failGroup(members, fmt.Errorf("group %s rolled back: %s: %w", group.name, m.ctr.Name, err), m)
+		}
+		m.newID = newID
+		if opts.NoStart {
+			// Nothing runs that could be checked
+			continue
+		}
+
+		if err := u.runPostJob(mctx, m.ctr, newID); err != nil {
+			u.rollbackGroup(ctx, members)
+			return failGroup(members, fmt.Errorf("group %s rolled back: %s: %w", group.name, m.ctr.Name, err), m)
+		}
+		if err := u.waitSettled(mctx, m.ctr, newID); err != nil {
+			u.rollbackGroup(context.WithoutCancel(ctx), members)
+			return failGroup(members, fmt.Errorf("group %s rolled back: %s: %w", group.name, m.ctr.Name, err), m)
+		}
+	}
+
+	for _, m := range members {
+		if !m.needsUpdate {
+			continue
+		}
+		mctx := memberContext(ctx, m.ctr)
+		m.result.Updated = true
+		if m.targetImage != "" {
+			m.result.Image = m.targetImage
+		}
+		if newCtr, err := u.client.GetContainer(mctx, m.newID); err == nil {
+			m.result.NewImageID = newCtr.ImageID
+		}
+		u.cleanupOldImage(mctx, m.ctr)
+	}
+
+	logger.WithField(logging.FieldAction, "update").Info("Updated container group")
+	return groupResults(members)
+}
+
+// planRecreates marks the members that need a new container: those with a
+// new image, and those sharing the network stack of a recreated member,
+// which would otherwise point at a removed container
+func planRecreates(members []*groupMember) {
+	for _, m := range members {
+		m.network = networkMember(members, m)
+		m.recreate = m.needsUpdate
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, m := range members {
+			if !m.recreate && m.network != nil && m.network.recreate {
+				m.recreate = true
+				changed = true
+			}
+		}
+	}
+}
+
+// networkMember returns the group member whose network stack m shares
+// (network_mode: container:X), nil when it shares none of theirs
+func networkMember(members []*groupMember, m *groupMember) *groupMember {
+	target := m.ctr.NetworkContainer()
+	if target == "" {
+		return nil
+	}
+	for _, other := range members {
+		if other != m && (other.ctr.Name == target || strings.HasPrefix(other.ctr.ID, target)) {
+			return other
+		}
+	}
+	return nil
+}
+
+// checkMember checks a group member for an update, bounded by the container
+// timeout like the checks of ungrouped containers
+func (u *Updater) checkMember(ctx context.Context, ctr docker.Container) (string, bool, error) {
+	if u.config.ContainerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.config.ContainerTimeout)
+		defer cancel()
+	}
+	return u.checkContainer(ctx, ctr)
+}
+
+// rollbackGroup returns the group to its state before the update: recreated
+// members are moved back to the image they ran before, rejoining the
+// restored network stack they share, and members that were only stopped are
+// started again
+func (u *Updater) rollbackGroup(ctx context.Context, members []*groupMember) {
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "rollback")
+	logger.Warn("Rolling back container group")
+
+	for _, m := range members {
+		mctx := memberContext(ctx, m.ctr)
+		mlog := logging.FromContext(mctx).WithField(logging.FieldAction, "rollback")
+
+		if m.newID == "" {
+			// The network stack it shares was recreated by the update
+			// and again by the rollback
+			if m.network != nil && m.network.newID != "" {
+				if _, err := u.client.RecreateContainer(mctx, m.ctr.ID, docker.RecreateOptions{
+					StopTimeout:      m.ctr.GetStopTimeout(u.config.StopTimeout),
+					NetworkContainer: m.network.newID,
+					NoStart:          !m.stopped,
+				}); err != nil {
+					mlog.WithError(err).Error("Failed to rejoin restored network stack")
+				}
+				continue
+			}
+			if m.stopped {
+				if err := u.client.StartContainer(mctx, m.ctr.ID); err != nil {
+					mlog.WithError(err).Error("Failed to restart group member")
+				}
+			}
+			continue
+		}
+
+		network := ""
+		if m.network != nil && m.network.newID != "" {
+			network = m.network.newID
+		}
+		restoredID, err := u.restoreContainer(mctx, m.ctr, m.newID, network)
+		if err != nil {
+			mlog.WithError(err).Error("Failed to roll back group member")
+			continue
+		}
+		m.newID = restoredID
+		mlog.Info("Rolled back group member")
+	}
+}
+
+// failGroup marks every member of a group as failed; cause is the member
+// that broke the group
+func failGroup(members []*groupMember, err error, cause *groupMember) []UpdateResult {
+	for _, m := range members {
+		if m == cause {
+			m.result.Error = err
+		} else {
+			m.result.Error = fmt.Errorf("not updated: group member %s failed", cause.ctr.Name)
+		}
+	}
+	return groupResults(members)
+}
+
+func groupResults(members []*groupMember) []UpdateResult {
+	results := make([]UpdateResult, len(members))
+	for i, m := range members {
+		results[i] = m.result
+	}
+	return results
+}
+
+func memberContext(ctx context.Context, ctr docker.Container) context.Context {
+	return logging.WithLogger(ctx, logging.WithFields(ctx, containerFields(ctr)))
+}
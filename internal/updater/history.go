@@ -0,0 +1,90 @@
+package updater
+
+import (
+	"context"
+	"errors"
+
+	"github.com/emon5122/dockwarden/internal/config"
+	"github.com/emon5122/dockwarden/internal/history"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+// History returns the update history
+func (u *Updater) History() *history.Store {
+	return u.history
+}
+
+// recordHistory adds a successful update to the history, with the SBOM of
+// the new image when SBOM capture is enabled
+func (u *Updater) recordHistory(ctx context.Context, result UpdateResult) {
+	logger := logging.FromContext(ctx).WithField(logging.FieldContainer, result.ContainerName)
+
+	record := history.Record{
+		Container:  result.ContainerName,
+		Identity:   result.Identity,
+		Image:      result.Image,
+		OldImageID: result.OldImageID,
+		NewImageID: result.NewImageID,
+	}
+	if digest, err := u.client.GetImageDigest(ctx, result.Image); err == nil {
+		record.Digest = digest
+	}
+	if result.NewImageID != "" {
+		record.ImageCreated = u.imageInfo(ctx, result.NewImageID).Created
+	}
+
+	var sbom []byte
+	if u.config.SBOM && !u.config.Offline && record.Digest != "" {
+		var err error
+		sbom, record.SBOM, err = u.registry.SBOM(ctx, result.Image, record.Digest)
+		switch {
+		case errors.Is(err, registry.ErrNoSBOM):
+			logger.Debug("Image has no SBOM attestation")
+		case err != nil:
+			logger.WithError(err).Warn("Failed to fetch SBOM")
+		}
+	}
+
+	record, err := u.history.Add(record, sbom)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record update history")
+		return
+	}
+	logger.WithFields(log.Fields{
+		"history_id": record.ID,
+		"sbom":       record.SBOM,
+	}).Debug("Recorded update history")
+}
+
+// openHistory opens the update history in its configured backend or the
+// data directory, falling back to memory when it isn't available
+func openHistory(cfg *config.Config) *history.Store {
+	location := cfg.DataDir
+	if cfg.HistoryURL != "" {
+		location = cfg.HistoryURL
+	}
+	store, err := history.Open(location, historyOptions(cfg))
+	if err != nil {
+		log.WithError(err).Warn("Failed to open update history, keeping it in memory")
+		store, _ = history.Open("", historyOptions(cfg))
+	}
+	return store
+}
+
+// historyOptions returns the retention of the update history
+func historyOptions(cfg *config.Config) history.Options {
+	return history.Options{
+		Retention:  cfg.HistoryRetention,
+		MaxRecords: cfg.HistoryMaxRecords,
+	}
+}
+
+// pruneHistory removes update history records beyond the retention, at
+// most hourly
+func (u *Updater) pruneHistory(ctx context.Context) {
+	if err := u.history.PruneIfDue(); err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to prune update history")
+	}
+}
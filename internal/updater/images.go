@@ -0,0 +1,117 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// ImageUsage is a local image with the containers using it
+type ImageUsage struct {
+	docker.Image
+	// Containers are the names of the containers created from the image,
+	// running or not
+	Containers []string `json:"containers"`
+	// Rollback is set for images kept for rollbacks (DOCKWARDEN_ROLLBACK_KEEP)
+	Rollback bool `json:"rollback"`
+}
+
+// Unused reports whether no container uses the image
+func (i ImageUsage) Unused() bool {
+	return len(i.Containers) == 0
+}
+
+// Prunable reports whether removing unused images removes this one; images
+// kept for rollbacks follow their own retention
+func (i ImageUsage) Prunable() bool {
+	return i.Unused() && !i.Rollback
+}
+
+// PruneResult is the outcome of removing unused images
+type PruneResult struct {
+	Removed   []string `json:"removed"`
+	Reclaimed int64    `json:"reclaimed"`
+	Failed    []string `json:"failed,omitempty"`
+}
+
+// Images returns the local images with the containers using them
+func (u *Updater) Images(ctx context.Context) ([]ImageUsage, error) {
+	images, err := u.client.ListImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := u.client.ListContainers(ctx, docker.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string][]string)
+	for _, ctr := range containers {
+		users[ctr.ImageID] = append(users[ctr.ImageID], ctr.Name)
+	}
+
+	usage := make([]ImageUsage, 0, len(images))
+	for _, img := range images {
+		usage = append(usage, ImageUsage{
+			Image:      img,
+			Containers: nonNilNames(users[img.ID]),
+			Rollback:   isRollbackImage(img),
+		})
+	}
+	return usage, nil
+}
+
+// PruneImages removes the images no container uses, except those kept for
+// rollbacks. Images that can't be removed, e.g. because a child image
+// depends on them, are reported as failed.
+func (u *Updater) PruneImages(ctx context.Context) (PruneResult, error) {
+	images, err := u.Images(ctx)
+	if err != nil {
+		return PruneResult{}, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	result := PruneResult{Removed: []string{}}
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "prune_images")
+	for _, img := range images {
+		if !img.Prunable() {
+			continue
+		}
+		if err := u.client.RemoveImage(ctx, img.ID); err != nil {
+			logger.WithError(err).WithField("image_id", truncateID(img.ID)).Debug("Failed to remove unused image")
+			result.Failed = append(result.Failed, img.ID)
+			continue
+		}
+		result.Removed = append(result.Removed, img.ID)
+		result.Reclaimed += img.Size
+	}
+
+	logger.WithFields(log.Fields{
+		"removed":   len(result.Removed),
+		"failed":    len(result.Failed),
+		"reclaimed": result.Reclaimed,
+	}).Info("Removed unused images")
+	return result, nil
+}
+
+// isRollbackImage reports whether an image is tagged into a rollback
+// repository
+func isRollbackImage(img docker.Image) bool {
+	for _, tag := range img.Tags {
+		if strings.HasPrefix(tag, RollbackRepository+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// nonNilNames returns names, or an empty slice so it is encoded as []
+func nonNilNames(names []string) []string {
+	if names == nil {
+		return []string{}
+	}
+	return names
+}
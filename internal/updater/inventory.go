@@ -0,0 +1,115 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+)
+
+// NetworkUsage is a network with the containers attached to it
+type NetworkUsage struct {
+	docker.NetworkInfo
+	// Containers are the names of all containers attached to the network,
+	// Managed those of them DockWarden updates
+	Containers []string `json:"containers"`
+	Managed    []string `json:"managed"`
+}
+
+// VolumeUsage is a volume with the containers mounting it
+type VolumeUsage struct {
+	docker.Volume
+	// Containers are the names of all containers mounting the volume,
+	// running or not, Managed those of them DockWarden updates
+	Containers []string `json:"containers"`
+	Managed    []string `json:"managed"`
+	// Orphaned volumes aren't mounted by any container
+	Orphaned bool `json:"orphaned"`
+}
+
+// containerUsers maps a resource name to the containers using it
+type containerUsers struct {
+	all     map[string][]string
+	managed map[string][]string
+}
+
+// add records that ctr uses the resource
+func (c containerUsers) add(resource string, ctr docker.Container, managed bool) {
+	c.all[resource] = append(c.all[resource], ctr.Name)
+	if managed {
+		c.managed[resource] = append(c.managed[resource], ctr.Name)
+	}
+}
+
+// inventoryUsers lists all containers, stopped ones included, and records
+// the resources each of them uses
+func (u *Updater) inventoryUsers(ctx context.Context, resources func(docker.Container) []string) (containerUsers, error) {
+	containers, err := u.client.ListContainers(ctx, docker.ListOptions{All: true})
+	if err != nil {
+		return containerUsers{}, err
+	}
+
+	users := containerUsers{all: make(map[string][]string), managed: make(map[string][]string)}
+	for _, ctr := range containers {
+		managed := u.filterReason(ctr) == nil
+		for _, resource := range resources(ctr) {
+			users.add(resource, ctr, managed)
+		}
+	}
+	return users, nil
+}
+
+// Networks returns the networks with the containers attached to them
+func (u *Updater) Networks(ctx context.Context) ([]NetworkUsage, error) {
+	networks, err := u.client.ListNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := u.inventoryUsers(ctx, func(ctr docker.Container) []string { return ctr.Networks })
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]NetworkUsage, 0, len(networks))
+	for _, n := range networks {
+		usage = append(usage, NetworkUsage{
+			NetworkInfo: n,
+			Containers:  nonNilNames(users.all[n.Name]),
+			Managed:     nonNilNames(users.managed[n.Name]),
+		})
+	}
+	return usage, nil
+}
+
+// Volumes returns the volumes with the containers mounting them
+func (u *Updater) Volumes(ctx context.Context) ([]VolumeUsage, error) {
+	volumes, err := u.client.ListVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := u.inventoryUsers(ctx, volumeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]VolumeUsage, 0, len(volumes))
+	for _, v := range volumes {
+		usage = append(usage, VolumeUsage{
+			Volume:     v,
+			Containers: nonNilNames(users.all[v.Name]),
+			Managed:    nonNilNames(users.managed[v.Name]),
+			Orphaned:   len(users.all[v.Name]) == 0,
+		})
+	}
+	return usage, nil
+}
+
+// volumeNames returns the names of the volumes a container mounts
+func volumeNames(ctr docker.Container) []string {
+	var names []string
+	for _, m := range ctr.Mounts {
+		if m.Type == "volume" {
+			names = append(names, m.Source)
+		}
+	}
+	return names
+}
@@ -0,0 +1,34 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+)
+
+// EditLabels recreates a container with edits to its dockwarden.* labels
+// (see docker.EditLabels) and returns the ID of the new container. The
+// container keeps its image, so management can be enabled or disabled, the
+// scope or stop timeout changed without editing compose files right away.
+// Compose recreates the container from its file again on the next
+// "docker compose up". Labels carrying a command, an image or an
+// environment variable are only edited when privileged is set. The recreate
+// isn't cancelled with ctx, so a client going away doesn't leave the
+// container removed.
+func (u *Updater) EditLabels(ctx context.Context, ctr docker.Container, edits map[string]string, privileged bool) (string, error) {
+	if err := docker.ValidateLabelEdits(edits, privileged); err != nil {
+		return "", err
+	}
+
+	logging.WithFields(ctx, containerFields(ctr)).WithField(logging.FieldAction, "edit_labels").Info("Recreating container with edited labels")
+	newID, err := u.client.RecreateContainer(context.WithoutCancel(ctx), ctr.ID, docker.RecreateOptions{
+		StopTimeout: ctr.GetStopTimeout(u.config.StopTimeout),
+		Labels:      edits,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to recreate container: %w", err)
+	}
+	return newID, nil
+}
@@ -0,0 +1,98 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/emon5122/dockwarden/internal/config"
+	"github.com/emon5122/dockwarden/internal/lock"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrCycleLocked is returned when another instance holds the cycle lock
+var ErrCycleLocked = errors.New("another instance is running an update cycle")
+
+// ErrCycleRunning is returned by TryLockCycle while this instance runs an
+// update cycle, plan apply or forced recreate
+var ErrCycleRunning = errors.New("an update cycle is running")
+
+// newCycleLock creates the lock shared with other instances when a lock URL
+// is configured. Each scope has its own lock.
+func newCycleLock(cfg *config.Config) *lock.Lock {
+	if cfg.LockURL == "" {
+		return nil
+	}
+	l, err := lock.New(cfg.LockURL, lock.Name("cycle", cfg.Scope), cfg.LockTTL)
+	if err != nil {
+		log.WithError(err).Error("Invalid lock URL, cycles are not coordinated with other instances")
+		return nil
+	}
+	return l
+}
+
+// SetElector makes the updater run cycles only while e reports this instance
+// as the leader. A nil elector always leads.
+func (u *Updater) SetElector(e *lock.Elector) {
+	u.elector = e
+}
+
+// IsLeader reports whether this instance may perform mutations
+func (u *Updater) IsLeader() bool {
+	return u.elector.IsLeader()
+}
+
+// lockCycle waits until no update cycle, plan apply or forced recreate of
+// this instance is running, then takes the lock shared with other instances
+// when one is configured. It fails with ErrCycleLocked when another
+// instance holds it, and with ctx's error when ctx ends first. The returned
+// function releases both.
+func (u *Updater) lockCycle(ctx context.Context) (func(), error) {
+	return u.takeCycle(ctx, true)
+}
+
+// TryLockCycle takes the cycle lock like lockCycle, but fails with
+// ErrCycleRunning instead of waiting while this instance holds it. The
+// health watcher uses it to stay off containers an update may be replacing.
+func (u *Updater) TryLockCycle(ctx context.Context) (func(), error) {
+	return u.takeCycle(ctx, false)
+}
+
+// takeCycle takes the cycle lock, waiting for this instance's holder when
+// wait is set
+func (u *Updater) takeCycle(ctx context.Context, wait bool) (func(), error) {
+	if wait {
+		select {
+		case u.cycle <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		select {
+		case u.cycle <- struct{}{}:
+		default:
+			return nil, ErrCycleRunning
+		}
+	}
+	leave := func() { <-u.cycle }
+	if u.lock == nil {
+		return leave, nil
+	}
+
+	held, err := u.lock.TryLock(ctx)
+	if err != nil {
+		leave()
+		return nil, fmt.Errorf("failed to acquire cycle lock: %w", err)
+	}
+	if !held {
+		leave()
+		return nil, ErrCycleLocked
+	}
+	return func() {
+		if err := u.lock.Unlock(context.WithoutCancel(ctx)); err != nil {
+			logging.FromContext(ctx).WithError(err).Warn("Failed to release cycle lock")
+		}
+		leave()
+	}, nil
+}
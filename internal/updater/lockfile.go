@@ -0,0 +1,195 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/events"
+	"github.com/emon5122/dockwarden/internal/lockfile"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// openLockfile opens the lockfile when one is configured. An unreadable
+// lockfile disables lockfile mode rather than reconciling to a guess.
+func openLockfile(path string) *lockfile.Lockfile {
+	if path == "" {
+		return nil
+	}
+	l, err := lockfile.Open(path)
+	if err != nil {
+		log.WithError(err).WithField("path", path).Error("Failed to open lockfile, lockfile mode is disabled")
+		return nil
+	}
+	return l
+}
+
+// recordLockfile pins the image an updated container now runs
+func (u *Updater) recordLockfile(ctx context.Context, result UpdateResult) {
+	if u.lockfile == nil {
+		return
+	}
+	logger := logging.FromContext(ctx).WithField(logging.FieldContainer, result.ContainerName)
+
+	digest, err := u.client.GetImageDigest(ctx, result.Image)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to pin updated image in the lockfile")
+		return
+	}
+	key := result.Identity
+	if key == "" {
+		key = result.ContainerName
+	}
+	if err := u.lockfile.Set(key, lockfile.Entry{
+		Container: result.ContainerName,
+		Image:     result.Image,
+		Digest:    digest,
+		ImageID:   result.NewImageID,
+	}); err != nil {
+		logger.WithError(err).Warn("Failed to update lockfile")
+	}
+}
+
+// ReconcileLockfile brings managed containers back to the images pinned in
+// the lockfile: a container running another image is recreated with the
+// pinned digest, pulled when it isn't present. Containers the lockfile
+// doesn't know yet are pinned to the image they run. Recreated containers
+// get the grace period and rollback of updates. It runs once on start,
+// holding the cycle lock.
+func (u *Updater) ReconcileLockfile() {
+	if u.lockfile == nil || !u.IsLeader() {
+		return
+	}
+
+	cycleID := logging.NewCycleID()
+	logger := log.WithFields(log.Fields{
+		logging.FieldCycleID: cycleID,
+		logging.FieldAction:  "reconcile",
+	})
+	ctx := logging.WithLogger(context.Background(), logger)
+
+	unlock, err := u.lockCycle(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("Skipping lockfile reconciliation")
+		return
+	}
+	defer unlock()
+
+	containers, err := u.client.ListContainers(ctx, docker.ListOptions{})
+	if err != nil {
+		logger.WithError(err).Error("Failed to list containers to reconcile with the lockfile")
+		return
+	}
+
+	var reconciled, failed int
+	for _, ctr := range u.filterContainers(ctx, containers, nil) {
+		clog := logging.WithFields(ctx, containerFields(ctr))
+		cctx := logging.WithLogger(ctx, clog)
+
+		entry, ok := u.lockfile.Get(ctr.Identity())
+		if !ok {
+			u.pinRunning(cctx, ctr)
+			continue
+		}
+		if u.runsPinned(cctx, ctr, entry) {
+			continue
+		}
+
+		clog = clog.WithFields(log.Fields{"pinned_image": entry.Image, "pinned_digest": entry.Digest})
+		if u.config.MonitorOnly {
+			clog.Info("Container differs from the lockfile (monitor only mode)")
+			continue
+		}
+		if u.config.NoRestart {
+			clog.Info("Container differs from the lockfile (no restart mode)")
+			continue
+		}
+
+		clog.Info("Recreating container with the image pinned in the lockfile")
+		newID, err := u.restorePinned(logging.WithLogger(ctx, clog), ctr, entry)
+		if err != nil {
+			failed++
+			clog.WithError(err).Error("Failed to reconcile container with the lockfile")
+			u.events.Publish(events.Event{
+				Type:      events.TypeContainerFailed,
+				CycleID:   cycleID,
+				Container: ctr.Name,
+				Image:     entry.Image,
+				Message:   err.Error(),
+			})
+			continue
+		}
+		reconciled++
+		clog.WithField("new_id", truncateID(newID)).Info("Container reconciled with the lockfile")
+		u.events.Publish(events.Event{
+			Type:      events.TypeContainerUpdate,
+			CycleID:   cycleID,
+			Container: ctr.Name,
+			Image:     entry.Image,
+			Message:   "container reconciled with the lockfile",
+		})
+	}
+
+	logger.WithFields(log.Fields{
+		"reconciled": reconciled,
+		"failed":     failed,
+		"lockfile":   u.lockfile.Path(),
+	}).Info("Lockfile reconciliation complete")
+}
+
+// runsPinned reports whether a container runs the image its entry pins
+func (u *Updater) runsPinned(ctx context.Context, ctr docker.Container, entry lockfile.Entry) bool {
+	if entry.Digest == "" || (entry.Image == ctr.Image && entry.ImageID == ctr.ImageID) {
+		return true
+	}
+	if entry.Image != ctr.Image {
+		return false
+	}
+	info, err := u.client.InspectImage(ctx, ctr.ImageID)
+	return err == nil && info.Digest == entry.Digest
+}
+
+// pinRunning adds the image a container runs to the lockfile
+func (u *Updater) pinRunning(ctx context.Context, ctr docker.Container) {
+	info, err := u.client.InspectImage(ctx, ctr.ImageID)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to pin container image in the lockfile")
+		return
+	}
+	if err := u.lockfile.Set(ctr.Identity(), lockfile.Entry{
+		Container: ctr.Name,
+		Image:     ctr.Image,
+		Digest:    info.Digest,
+		ImageID:   ctr.ImageID,
+	}); err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to update lockfile")
+	}
+}
+
+// restorePinned tags the pinned digest as the container's image reference
+// and updates the container to it, rolling it back when it doesn't settle
+func (u *Updater) restorePinned(ctx context.Context, ctr docker.Container, entry lockfile.Entry) (string, error) {
+	ref := docker.RepositoryName(entry.Image) + "@" + entry.Digest
+	info, err := u.client.InspectImage(ctx, ref)
+	if err != nil {
+		if u.config.Offline || u.config.NoPull {
+			return "", fmt.Errorf("pinned image %s is not available locally", ref)
+		}
+		if err := u.pullImage(ctx, ref); err != nil {
+			return "", fmt.Errorf("failed to pull pinned image: %w", err)
+		}
+		if info, err = u.client.InspectImage(ctx, ref); err != nil {
+			return "", fmt.Errorf("failed to inspect pinned image: %w", err)
+		}
+	}
+	if err := u.client.TagImage(ctx, info.ID, entry.Image); err != nil {
+		return "", fmt.Errorf("failed to tag pinned image: %w", err)
+	}
+
+	targetImage := ""
+	if entry.Image != ctr.Image {
+		targetImage = entry.Image
+	}
+	return u.updateContainer(ctx, ctr, targetImage)
+}
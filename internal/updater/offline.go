@@ -0,0 +1,123 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/distribution/reference"
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// SkipNotLoaded is reported by the offline plan when a requested tag change
+// targets an image that hasn't been loaded yet
+const SkipNotLoaded = "not_loaded"
+
+// checkLoadedImage reports whether the container's image reference now
+// points at a different local image, e.g. after a newer archive of the same
+// tag was loaded. Nothing is pulled.
+func (u *Updater) checkLoadedImage(ctx context.Context, ctr docker.Container) (bool, error) {
+	info, err := u.client.InspectImage(ctx, ctr.Image)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect loaded image: %w", err)
+	}
+
+	if rejectedID, _ := u.rejectedImage(ctr); rejectedID != "" && info.ID == rejectedID {
+		logging.FromContext(ctx).WithFields(log.Fields{
+			logging.FieldAction: "check",
+			"image_id":          truncateID(info.ID),
+		}).Debug("Skipping the image the container was rolled back from")
+		return false, nil
+	}
+
+	if info.ID != ctr.ImageID {
+		logging.FromContext(ctx).WithFields(log.Fields{
+			logging.FieldAction: "check",
+			"old_image_id":      truncateID(ctr.ImageID),
+			"new_image_id":      truncateID(info.ID),
+		}).Debug("Container has update")
+		return true, nil
+	}
+	return false, nil
+}
+
+// isLoaded reports whether imageName exists in the local image store
+func (u *Updater) isLoaded(ctx context.Context, imageName string) bool {
+	_, err := u.client.InspectImage(ctx, imageName)
+	return err == nil
+}
+
+// planLoadedImage plans a container in offline mode by comparing the image
+// it runs with the local image its reference points at
+func (u *Updater) planLoadedImage(ctx context.Context, ctr docker.Container, item PlanItem) PlanItem {
+	if target := ctr.TargetImage(); target != "" {
+		item.TargetImage = target
+		if !u.isLoaded(ctx, target) {
+			item.Action = PlanSkip
+			item.SkipReason = skip(SkipNotLoaded, "target image %q has not been loaded (offline mode)", target)
+			return item
+		}
+		item.Action = PlanRetag
+		return item
+	}
+
+	needsUpdate, err := u.checkLoadedImage(ctx, ctr)
+	if err != nil {
+		return planError(ctx, item, err)
+	}
+	if needsUpdate {
+		item.Action = PlanUpdate
+	} else {
+		item.Action = PlanUpToDate
+	}
+	return item
+}
+
+// LoadImages loads a docker-save archive into the daemon and returns the
+// references of the images it contained. Standby instances refuse to load.
+func (u *Updater) LoadImages(ctx context.Context, archive io.Reader) ([]string, error) {
+	if !u.IsLeader() {
+		return nil, ErrStandby
+	}
+
+	images, err := u.client.LoadImage(ctx, archive)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldAction: "load",
+		"images":            images,
+	}).Info("Loaded image archive")
+	return images, nil
+}
+
+// UsesImages returns a Filter accepting containers whose image reference is
+// one of images, comparing normalized references so "nginx" matches
+// "docker.io/library/nginx:latest"
+func UsesImages(images []string) Filter {
+	normalized := make([]string, 0, len(images))
+	for _, image := range images {
+		normalized = append(normalized, normalizeImage(image))
+	}
+	return func(ctr docker.Container) bool {
+		return slices.Contains(normalized, normalizeImage(ctr.Image)) ||
+			slices.Contains(normalized, normalizeImage(ctr.TargetImage()))
+	}
+}
+
+// normalizeImage returns the fully-qualified reference of imageName with
+// the implied latest tag, or imageName itself when it doesn't parse
+func normalizeImage(imageName string) string {
+	if imageName == "" {
+		return ""
+	}
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return imageName
+	}
+	return reference.TagNameOnly(named).String()
+}
@@ -0,0 +1,169 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/pool"
+)
+
+// pendingUpdate is a container whose new image was pulled in the first phase
+// of a cycle and that is recreated in the second
+type pendingUpdate struct {
+	index       int
+	ctr         docker.Container
+	targetImage string
+}
+
+// processContainersConcurrently updates containers in two phases. All images
+// are pulled and checked concurrently first; only then are the containers
+// that changed recreated, so no container is down while a slow pull of
+// another image is running. Recreation follows dockwarden.depends-on: a
+// container is only recreated after the containers it depends on.
+func (u *Updater) processContainersConcurrently(ctx context.Context, containers []docker.Container) []UpdateResult {
+	containers = u.scheduleBySize(ctx, containers)
+	results := make([]UpdateResult, len(containers))
+	pending := u.pullPhase(ctx, containers, results)
+	if len(pending) == 0 {
+		return results
+	}
+
+	logging.FromContext(ctx).WithField("containers", len(pending)).Debug("Recreating updated containers")
+	for _, wave := range recreateWaves(pending) {
+		u.recreatePhase(ctx, wave, results)
+	}
+	return results
+}
+
+// newCyclePool returns the worker pool of a cycle phase, which cancels tasks
+// running longer than timeout (0: never). Rolling restarts process one
+// container at a time.
+func (u *Updater) newCyclePool(timeout time.Duration) *pool.Pool {
+	// Determine concurrency limit - higher for faster checks
+	maxConcurrency := 10
+	if u.config.RollingRestart {
+		maxConcurrency = 1 // Sequential for rolling restart
+	}
+	return pool.New(pool.Options{
+		Workers:     maxConcurrency,
+		TaskTimeout: timeout,
+		Metrics:     u.poolMetrics,
+	})
+}
+
+// pullPhase checks all containers and pulls their new images. Results of
+// containers that need no recreation are final; the others are returned.
+func (u *Updater) pullPhase(ctx context.Context, containers []docker.Container, results []UpdateResult) []pendingUpdate {
+	workers := u.newCyclePool(u.config.ContainerTimeout)
+	pending := make([]*pendingUpdate, len(containers))
+	for i, ctr := range containers {
+		results[i] = UpdateResult{ContainerID: ctr.ID, ContainerName: ctr.Name, Identity: ctr.Identity(), Image: ctr.Image}
+		cctx := logging.WithLogger(ctx, logging.WithFields(ctx, containerFields(ctr)))
+		task := func(ctx context.Context) error {
+			result, targetImage, recreate := u.checkPhase(ctx, ctr)
+			results[i] = result
+			if recreate {
+				pending[i] = &pendingUpdate{index: i, ctr: ctr, targetImage: targetImage}
+			}
+			return result.Error
+		}
+		done := func(err error) {
+			if errors.Is(err, pool.ErrPanic) {
+				results[i].Error = err
+				pending[i] = nil
+			}
+		}
+		if err := workers.Submit(cctx, task, done); err != nil {
+			results[i].Error = err
+		}
+	}
+	workers.Close()
+
+	var updates []pendingUpdate
+	for _, p := range pending {
+		if p != nil {
+			updates = append(updates, *p)
+		}
+	}
+	return updates
+}
+
+// recreatePhase recreates the containers of one wave concurrently. The
+// container timeout doesn't apply: a container cancelled between its removal
+// and the creation of its replacement would be lost.
+func (u *Updater) recreatePhase(ctx context.Context, wave []pendingUpdate, results []UpdateResult) {
+	workers := u.newCyclePool(0)
+	for _, p := range wave {
+		cctx := logging.WithLogger(ctx, logging.WithFields(ctx, containerFields(p.ctr)))
+		task := func(ctx context.Context) error {
+			results[p.index] = u.applyUpdate(ctx, p.ctr, p.targetImage, results[p.index])
+			return results[p.index].Error
+		}
+		done := func(err error) {
+			if errors.Is(err, pool.ErrPanic) {
+				results[p.index].Error = err
+			}
+		}
+		if err := workers.Submit(cctx, task, done); err != nil {
+			results[p.index].Error = err
+		}
+	}
+	workers.Close()
+}
+
+// recreateWaves splits pending updates into waves that can be recreated
+// concurrently. Each container is placed in a later wave than the pending
+// containers it depends on; containers in a dependency cycle share the last
+// wave. Waves keep the order of pending.
+func recreateWaves(pending []pendingUpdate) [][]pendingUpdate {
+	wave := make(map[string]int, len(pending))
+	for _, p := range pending {
+		wave[p.ctr.Name] = -1
+	}
+
+	placed := 0
+	for level := 0; placed < len(pending); level++ {
+		progress := false
+		for _, p := range pending {
+			if wave[p.ctr.Name] >= 0 || !dependenciesDone(p.ctr, wave, level) {
+				continue
+			}
+			wave[p.ctr.Name] = level
+			placed++
+			progress = true
+		}
+		if !progress {
+			// Dependency cycle: recreate the remaining containers together
+			for _, p := range pending {
+				if wave[p.ctr.Name] < 0 {
+					wave[p.ctr.Name] = level
+					placed++
+				}
+			}
+		}
+	}
+
+	var waves [][]pendingUpdate
+	for _, p := range pending {
+		level := wave[p.ctr.Name]
+		for len(waves) <= level {
+			waves = append(waves, nil)
+		}
+		waves[level] = append(waves[level], p)
+	}
+	return waves
+}
+
+// dependenciesDone reports whether every pending dependency of ctr was placed
+// in a wave before level
+func dependenciesDone(ctr docker.Container, wave map[string]int, level int) bool {
+	for _, dep := range ctr.DependsOn() {
+		if w, ok := wave[dep]; ok && (w < 0 || w >= level) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,346 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/pool"
+	"github.com/emon5122/dockwarden/internal/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+// Planned actions
+const (
+	PlanUpdate   = "update"
+	PlanRetag    = "retag"
+	PlanUpToDate = "up_to_date"
+	PlanSkip     = "skip"
+	PlanUnknown  = "unknown"
+)
+
+// ErrStandby is returned for mutations requested from a standby instance
+var ErrStandby = errors.New("this instance is a standby, only the leader performs mutations")
+
+// SkipNoPull is reported by the plan when pulling is disabled
+const SkipNoPull = "no_pull"
+
+// PlanItem is what the next cycle would do with a single container
+type PlanItem struct {
+	ContainerID   string      `json:"container_id"`
+	ContainerName string      `json:"container_name"`
+	Identity      string      `json:"identity"`
+	Image         string      `json:"image"`
+	Action        string      `json:"action"`
+	TargetImage   string      `json:"target_image,omitempty"`
+	LocalDigest   string      `json:"local_digest,omitempty"`
+	RemoteDigest  string      `json:"remote_digest,omitempty"`
+	SkipReason    *SkipReason `json:"skip_reason,omitempty"`
+	Error         string      `json:"error,omitempty"`
+}
+
+// Plan is the outcome of a check-only pass over all containers
+type Plan struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	MonitorOnly bool           `json:"monitor_only"`
+	Items       []PlanItem     `json:"items"`
+	Summary     map[string]int `json:"summary"`
+}
+
+// Plan runs the check phase of an update cycle without pulling images or
+// touching containers. Remote digests are resolved with manifest HEAD
+// requests against the registry, so the result shows what the next cycle
+// would update and why everything else would be left alone. Only containers
+// accepted by match are included.
+func (u *Updater) Plan(ctx context.Context, match Filter) (*Plan, error) {
+	cycleID := logging.NewCycleID()
+	ctx = logging.WithLogger(ctx, logging.WithFields(ctx, log.Fields{
+		logging.FieldCycleID: cycleID,
+		logging.FieldAction:  "plan",
+	}))
+
+	containers, err := u.client.ListContainers(ctx, docker.ListOptions{
+		All:           true,
+		IncludeHealth: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	if match != nil {
+		containers = slices.DeleteFunc(containers, func(ctr docker.Container) bool { return !match(ctr) })
+	}
+
+	items := make([]PlanItem, len(containers))
+	workers := pool.New(pool.Options{
+		Workers:     10,
+		TaskTimeout: u.config.ContainerTimeout,
+		Metrics:     u.poolMetrics,
+	})
+	for i, ctr := range containers {
+		items[i] = PlanItem{ContainerID: ctr.ID, ContainerName: ctr.Name, Identity: ctr.Identity(), Image: ctr.Image, Action: PlanUnknown}
+		cctx := logging.WithLogger(ctx, logging.WithFields(ctx, containerFields(ctr)))
+		task := func(ctx context.Context) error {
+			items[i] = u.planContainer(ctx, ctr)
+			return nil
+		}
+		done := func(err error) {
+			if err != nil {
+				items[i].Error = err.Error()
+			}
+		}
+		if err := workers.Submit(cctx, task, done); err != nil {
+			items[i].Error = err.Error()
+		}
+	}
+	workers.Close()
+
+	summary := make(map[string]int)
+	for _, item := range items {
+		summary[item.Action]++
+		u.trackPlanItem(item)
+	}
+
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"updates": summary[PlanUpdate] + summary[PlanRetag],
+		"total":   len(items),
+	}).Debug("Plan generated")
+
+	return &Plan{
+		GeneratedAt: time.Now(),
+		MonitorOnly: u.config.MonitorOnly,
+		Items:       items,
+		Summary:     summary,
+	}, nil
+}
+
+// planContainer decides what the next cycle would do with ctr
+func (u *Updater) planContainer(ctx context.Context, ctr docker.Container) PlanItem {
+	item := PlanItem{
+		ContainerID:   ctr.ID,
+		ContainerName: ctr.Name,
+		Identity:      ctr.Identity(),
+		Image:         ctr.Image,
+	}
+
+	if reason := u.SkipReason(ctx, ctr); reason != nil {
+		item.Action = PlanSkip
+		item.SkipReason = reason
+		return item
+	}
+
+	if u.config.NoPull {
+		item.Action = PlanSkip
+		item.SkipReason = skip(SkipNoPull, "pulling is disabled (--no-pull)")
+		return item
+	}
+
+	if u.config.Offline {
+		return u.planLoadedImage(ctx, ctr, item)
+	}
+
+	if target := ctr.TargetImage(); target != "" {
+		item.TargetImage = target
+		if _, err := u.registry.Digest(ctx, target); err != nil {
+			return planError(ctx, item, fmt.Errorf("failed to resolve target image: %w", err))
+		}
+		item.Action = PlanRetag
+		return item
+	}
+
+	localDigest, err := u.client.GetImageDigest(ctx, ctr.Image)
+	if err != nil {
+		return planError(ctx, item, fmt.Errorf("failed to get current digest: %w", err))
+	}
+	item.LocalDigest = localDigest
+
+	remoteDigest, err := u.registry.Digest(ctx, ctr.Image)
+	if err != nil {
+		return planError(ctx, item, fmt.Errorf("failed to get remote digest: %w", err))
+	}
+	item.RemoteDigest = remoteDigest
+
+	if _, rejected := u.rejectedImage(ctr); rejected != "" && remoteDigest == rejected {
+		item.Action = PlanSkip
+		item.SkipReason = skip(SkipRolledBack, "rolled back from the image the registry serves (%s), waiting for a newer one", truncateID(remoteDigest))
+		return item
+	}
+
+	if localDigest != remoteDigest {
+		item.Action = PlanUpdate
+	} else {
+		item.Action = PlanUpToDate
+	}
+	return item
+}
+
+// planError marks an item whose outcome could not be determined
+func planError(ctx context.Context, item PlanItem, err error) PlanItem {
+	item.Action = PlanUnknown
+	item.Error = err.Error()
+
+	logger := logging.FromContext(ctx).WithError(err)
+	if errors.Is(err, registry.ErrUnauthorized) {
+		logger.Debug("Registry check requires credentials")
+	} else {
+		logger.Warn("Failed to plan container")
+	}
+	return item
+}
+
+// ApplyResult is the outcome of applying a single planned item
+type ApplyResult struct {
+	Container string `json:"container"`
+	Action    string `json:"action,omitempty"`
+	Applied   bool   `json:"applied"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Apply immediately executes the planned updates of the selected containers,
+// identified by name, full ID or ID prefix. Each selected container is
+// planned again first, so only items that still resolve to an update or a
+// tag change are applied; anything else is reported back unchanged.
+// Containers not accepted by match are treated as not found. Applying waits
+// for a running update cycle and fails with ErrCycleLocked while another
+// instance runs one; updates that started aren't cancelled with ctx.
+func (u *Updater) Apply(ctx context.Context, selected []string, match Filter) ([]ApplyResult, error) {
+	if !u.IsLeader() {
+		return nil, ErrStandby
+	}
+
+	ctx = logging.WithLogger(ctx, logging.WithFields(ctx, log.Fields{
+		logging.FieldCycleID: logging.NewCycleID(),
+		logging.FieldAction:  "apply",
+	}))
+
+	unlock, err := u.lockCycle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	containers, err := u.client.ListContainers(ctx, docker.ListOptions{
+		All:           true,
+		IncludeHealth: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	results := make([]ApplyResult, 0, len(selected))
+	var updated, failed int64
+	var updates []UpdateResult
+	for _, sel := range selected {
+		result := ApplyResult{Container: sel}
+
+		ctr, ok := findContainer(containers, sel)
+		if !ok || (match != nil && !match(ctr)) {
+			result.Error = "container not found"
+			results = append(results, result)
+			continue
+		}
+		result.Container = ctr.Name
+
+		cctx := logging.WithLogger(ctx, logging.WithFields(ctx, containerFields(ctr)))
+		item := u.planContainer(cctx, ctr)
+		result.Action = item.Action
+
+		if item.Action != PlanUpdate && item.Action != PlanRetag {
+			result.Error = notApplicable(item)
+			results = append(results, result)
+			continue
+		}
+
+		pullImage := ctr.Image
+		if item.TargetImage != "" {
+			pullImage = item.TargetImage
+		}
+		previousImageID := ctr.ImageID
+		if item.TargetImage != "" {
+			previousImageID = ""
+		}
+		if err := u.pullVerified(cctx, pullImage, previousImageID); err != nil {
+			result.Error = fmt.Sprintf("failed to pull image: %v", err)
+			failed++
+			results = append(results, result)
+			continue
+		}
+		if err := u.checkArch(cctx, ctr, pullImage); err != nil {
+			result.Error = err.Error()
+			failed++
+			results = append(results, result)
+			continue
+		}
+
+		oldImage := u.imageInfo(cctx, ctr.ImageID)
+		newID, err := u.updateContainer(context.WithoutCancel(cctx), ctr, item.TargetImage)
+		if err != nil {
+			logging.FromContext(cctx).WithError(err).Error("Failed to apply planned update")
+			result.Error = fmt.Sprintf("failed to update: %v", err)
+			failed++
+			results = append(results, result)
+			continue
+		}
+
+		result.Applied = true
+		updated++
+		update := UpdateResult{
+			ContainerID:   newID,
+			ContainerName: ctr.Name,
+			Identity:      ctr.Identity(),
+			Image:         pullImage,
+			OldImageID:    ctr.ImageID,
+			OldImage:      oldImage,
+			Updated:       true,
+		}
+		if newCtr, err := u.client.GetContainer(cctx, newID); err == nil {
+			update.NewImageID = newCtr.ImageID
+		}
+		u.trackResult(update)
+		u.recordHistory(cctx, update)
+		u.recordLockfile(cctx, update)
+		u.notifyUpdated(cctx, update)
+		updates = append(updates, update)
+		results = append(results, result)
+	}
+	u.publishGitOps(ctx, updates)
+
+	u.totalUpdated.Add(updated)
+	u.totalFailed.Add(failed)
+
+	return results, nil
+}
+
+// notApplicable explains why a planned item has nothing to apply
+func notApplicable(item PlanItem) string {
+	switch {
+	case item.SkipReason != nil:
+		return item.SkipReason.Message
+	case item.Error != "":
+		return item.Error
+	default:
+		return "already up to date"
+	}
+}
+
+// findContainer looks a container up by name, full ID or ID prefix
+func findContainer(containers []docker.Container, key string) (docker.Container, bool) {
+	key = strings.TrimPrefix(key, "/")
+	for _, ctr := range containers {
+		if ctr.Name == key || ctr.ID == key {
+			return ctr, true
+		}
+	}
+	if len(key) >= 4 {
+		for _, ctr := range containers {
+			if strings.HasPrefix(ctr.ID, key) {
+				return ctr, true
+			}
+		}
+	}
+	return docker.Container{}, false
+}
@@ -0,0 +1,110 @@
+package updater
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/registry"
+)
+
+// PullDurationBuckets are the upper bounds, in seconds, of the pull duration
+// histogram
+var PullDurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600}
+
+// PullStats aggregates the image pulls made against a single registry
+type PullStats struct {
+	Registry string
+	Pulls    int64
+	Failures int64
+	// Skipped counts pulls avoided because the registry still served the
+	// digest of the local image
+	Skipped int64
+	Bytes   int64
+	// DurationBuckets counts pulls per PullDurationBuckets bound
+	// (non-cumulative, the last entry counts pulls slower than every bound)
+	DurationBuckets []int64
+	DurationSum     float64
+}
+
+// pullImage pulls an image and records its size and duration for the
+// registry it came from
+func (u *Updater) pullImage(ctx context.Context, imageName string) error {
+	start := time.Now()
+	bytes, err := u.client.PullImage(ctx, imageName)
+	u.recordPull(registry.Domain(imageName), bytes, time.Since(start), err)
+	if err == nil && bytes > 0 {
+		u.recordPullTime(imageName, time.Since(start))
+	}
+	return err
+}
+
+// recordPullTime remembers how long the last pull downloading an image
+// took, which orders the containers of the next cycle
+func (u *Updater) recordPullTime(imageName string, duration time.Duration) {
+	u.pullsMu.Lock()
+	defer u.pullsMu.Unlock()
+	u.pullTimes[imageName] = duration
+}
+
+// lastPullTimes returns how long the last pull of each image took
+func (u *Updater) lastPullTimes() map[string]time.Duration {
+	u.pullsMu.Lock()
+	defer u.pullsMu.Unlock()
+	return maps.Clone(u.pullTimes)
+}
+
+func (u *Updater) recordPull(domain string, bytes int64, duration time.Duration, err error) {
+	u.pullsMu.Lock()
+	defer u.pullsMu.Unlock()
+
+	stats := u.pullStatsFor(domain)
+	stats.Bytes += bytes
+	if err != nil {
+		stats.Failures++
+		return
+	}
+
+	seconds := duration.Seconds()
+	stats.Pulls++
+	stats.DurationSum += seconds
+	bucket := sort.SearchFloat64s(PullDurationBuckets, seconds)
+	stats.DurationBuckets[bucket]++
+}
+
+// recordSkippedPull counts a pull avoided by the manifest check
+func (u *Updater) recordSkippedPull(domain string) {
+	u.pullsMu.Lock()
+	defer u.pullsMu.Unlock()
+	u.pullStatsFor(domain).Skipped++
+}
+
+// pullStatsFor returns the statistics of a registry, creating them on first
+// use. The caller must hold pullsMu.
+func (u *Updater) pullStatsFor(domain string) *PullStats {
+	stats, ok := u.pulls[domain]
+	if !ok {
+		stats = &PullStats{
+			Registry:        domain,
+			DurationBuckets: make([]int64, len(PullDurationBuckets)+1),
+		}
+		u.pulls[domain] = stats
+	}
+	return stats
+}
+
+// PullStats returns the pull statistics of every registry sorted by domain
+func (u *Updater) PullStats() []PullStats {
+	u.pullsMu.Lock()
+	defer u.pullsMu.Unlock()
+
+	stats := make([]PullStats, 0, len(u.pulls))
+	for _, s := range u.pulls {
+		copied := *s
+		copied.DurationBuckets = append([]int64(nil), s.DurationBuckets...)
+		stats = append(stats, copied)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Registry < stats[j].Registry })
+	return stats
+}
@@ -0,0 +1,168 @@
+package updater
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrNotQuarantined is returned when resetting a container that isn't
+// quarantined
+var ErrNotQuarantined = errors.New("container is not quarantined")
+
+// quarantineFile in the data directory records the failure counts,
+// quarantines and rolled back images of containers, so restarts don't retry
+// a failing image
+const quarantineFile = "quarantine.json"
+
+// quarantineRecord is the persisted failure state of a container identity
+type quarantineRecord struct {
+	Name           string    `json:"name"`
+	Image          string    `json:"image,omitempty"`
+	Failures       int       `json:"failures"`
+	LastError      string    `json:"last_error,omitempty"`
+	QuarantinedAt  time.Time `json:"quarantined_at,omitempty"`
+	RejectedImage  string    `json:"rejected_image_id,omitempty"`
+	RejectedDigest string    `json:"rejected_digest,omitempty"`
+}
+
+// trackFailure counts a failed update and quarantines the container when it
+// failed QuarantineAfter times in a row. Only the update that starts the
+// quarantine is notified, later cycles skip the container.
+func (u *Updater) trackFailure(result UpdateResult) {
+	u.statusesMu.Lock()
+	status := u.status(result.Identity, result.ContainerName)
+	status.Image = result.Image
+	status.Failures++
+	status.LastError = result.Error.Error()
+	quarantine := u.config.QuarantineAfter > 0 && status.Failures >= u.config.QuarantineAfter && !status.Quarantined()
+	if quarantine {
+		status.QuarantinedAt = time.Now()
+	}
+	failures, lastError := status.Failures, status.LastError
+	u.saveQuarantineLocked()
+	u.statusesMu.Unlock()
+
+	if !quarantine {
+		return
+	}
+	log.WithFields(log.Fields{
+		logging.FieldContainer: result.ContainerName,
+		logging.FieldImage:     result.Image,
+		logging.FieldAction:    "quarantine",
+		"failures":             failures,
+	}).Error("Container failed to update too many times in a row, quarantining it until its failures are reset")
+	if u.notifier != nil {
+		u.notifier.NotifyQuarantined(result.ContainerName, result.Image, failures, lastError)
+	}
+}
+
+// ResetQuarantine resets the failures of a quarantined container identity,
+// so the next cycle updates it again
+func (u *Updater) ResetQuarantine(identity string) error {
+	u.statusesMu.Lock()
+	defer u.statusesMu.Unlock()
+
+	status, ok := u.statuses[identity]
+	if !ok || !status.Quarantined() {
+		return ErrNotQuarantined
+	}
+	status.Failures = 0
+	status.LastError = ""
+	status.QuarantinedAt = time.Time{}
+	u.saveQuarantineLocked()
+	return nil
+}
+
+// loadQuarantine restores the failure counts and quarantines recorded in
+// the data directory
+func (u *Updater) loadQuarantine() {
+	if u.config.DataDir == "" {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(u.config.DataDir, quarantineFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.WithError(err).Warn("Failed to read quarantined containers")
+		}
+		return
+	}
+	var records map[string]quarantineRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		log.WithError(err).Warn("Failed to parse quarantined containers")
+		return
+	}
+
+	u.statusesMu.Lock()
+	defer u.statusesMu.Unlock()
+	for identity, r := range records {
+		status := u.status(identity, r.Name)
+		status.Image = r.Image
+		status.Failures = r.Failures
+		status.LastError = r.LastError
+		status.QuarantinedAt = r.QuarantinedAt
+		status.RejectedImageID = r.RejectedImage
+		status.RejectedDigest = r.RejectedDigest
+	}
+}
+
+// saveQuarantineLocked records the containers with failed updates or a
+// rolled back image in the data directory. The caller holds statusesMu.
+func (u *Updater) saveQuarantineLocked() {
+	if u.config.DataDir == "" {
+		return
+	}
+	records := make(map[string]quarantineRecord)
+	for identity, status := range u.statuses {
+		if status.Failures == 0 && !status.Quarantined() && status.RejectedImageID == "" {
+			continue
+		}
+		records[identity] = quarantineRecord{
+			Name:           status.Name,
+			Image:          status.Image,
+			Failures:       status.Failures,
+			LastError:      status.LastError,
+			QuarantinedAt:  status.QuarantinedAt,
+			RejectedImage:  status.RejectedImageID,
+			RejectedDigest: status.RejectedDigest,
+		}
+	}
+	if err := writeQuarantine(filepath.Join(u.config.DataDir, quarantineFile), records); err != nil {
+		log.WithError(err).Warn("Failed to record quarantined containers")
+	}
+}
+
+// writeQuarantine writes records to path atomically, so a crash never
+// leaves a partial file
+func writeQuarantine(path string, records map[string]quarantineRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode quarantined containers: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".quarantine-*")
+	if err != nil {
+		return fmt.Errorf("failed to write quarantined containers: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write quarantined containers: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write quarantined containers: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write quarantined containers: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace quarantined containers: %w", err)
+	}
+	return nil
+}
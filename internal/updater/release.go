@@ -0,0 +1,89 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/meta"
+	"github.com/emon5122/dockwarden/internal/release"
+	log "github.com/sirupsen/logrus"
+)
+
+// releaseNotifiedFile in the data directory records the release a
+// notification was last sent for, so restarts don't repeat it
+const releaseNotifiedFile = "release-notified"
+
+// newReleaseChecker returns the release checker when the version check is
+// enabled
+func newReleaseChecker(enabled bool) *release.Checker {
+	if !enabled {
+		return nil
+	}
+	return release.New(meta.Version)
+}
+
+// LatestRelease returns the result of the last version check, nil when the
+// check is disabled or hasn't succeeded yet
+func (u *Updater) LatestRelease() *release.Status {
+	if u.release == nil {
+		return nil
+	}
+	return u.release.Status()
+}
+
+// CheckRelease looks up the latest DockWarden release. A newer release is
+// logged on every check and notified once.
+func (u *Updater) CheckRelease(ctx context.Context) {
+	if u.release == nil {
+		return
+	}
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "version_check")
+
+	status, err := u.release.Check(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to check for a newer DockWarden release")
+		return
+	}
+	if !status.UpdateAvailable {
+		logger.WithField("latest_version", status.Latest).Debug("DockWarden is up to date")
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"current_version": status.Current,
+		"latest_version":  status.Latest,
+		"release_url":     status.URL,
+	}).Info("A newer DockWarden release is available")
+
+	// Standbys run the same version, the leader notifies for all
+	if !u.IsLeader() || u.releaseNotified() == status.Latest {
+		return
+	}
+	u.notifier.NotifyReleaseAvailable(status.Current, status.Latest, status.URL)
+	if err := u.recordReleaseNotified(status.Latest); err != nil {
+		logger.WithError(err).Debug("Failed to record release notification")
+	}
+}
+
+// releaseNotified returns the release a notification was last sent for
+func (u *Updater) releaseNotified() string {
+	data, err := os.ReadFile(filepath.Join(u.config.DataDir, releaseNotifiedFile))
+	if err != nil {
+		return u.lastReleaseNotified
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// recordReleaseNotified remembers the release a notification was sent for,
+// in memory when the data directory isn't writable
+func (u *Updater) recordReleaseNotified(version string) error {
+	u.lastReleaseNotified = version
+	if u.config.DataDir == "" {
+		return errors.New("no data directory")
+	}
+	return os.WriteFile(filepath.Join(u.config.DataDir, releaseNotifiedFile), []byte(version+"\n"), 0o644)
+}
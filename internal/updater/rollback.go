@@ -0,0 +1,71 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// RollbackRepository is the local repository previous images are kept under,
+// as dockwarden-rollback/<container>:<timestamp>
+const RollbackRepository = "dockwarden-rollback"
+
+// rollbackTagFormat sorts lexically in chronological order
+const rollbackTagFormat = "20060102-150405"
+
+// RollbackRepo returns the rollback repository of a container. Container
+// names allow characters (upper case, consecutive separators) that image
+// repositories don't, so the name is normalized.
+func RollbackRepo(containerName string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimPrefix(containerName, "/")) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	name := strings.Trim(b.String(), "-")
+	if name == "" {
+		name = "container"
+	}
+	return RollbackRepository + "/" + name
+}
+
+// retainRollbackImage tags the image a container ran before its update into
+// its rollback repository instead of deleting it, then removes the oldest
+// rollback images beyond the configured number to keep
+func (u *Updater) retainRollbackImage(ctx context.Context, ctr docker.Container, oldImageID string) error {
+	repo := RollbackRepo(ctr.Name)
+	ref := repo + ":" + time.Now().UTC().Format(rollbackTagFormat)
+
+	logger := logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldAction: "rollback_cache",
+		"rollback_image":    ref,
+	})
+
+	if err := u.client.TagImage(ctx, oldImageID, ref); err != nil {
+		return fmt.Errorf("failed to keep previous image: %w", err)
+	}
+	logger.Debug("Kept previous image for rollback")
+
+	tags, err := u.client.ListImageTags(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("failed to list rollback images: %w", err)
+	}
+
+	// Tags are sorted oldest first
+	for len(tags) > u.config.RollbackKeep {
+		if err := u.client.RemoveImage(ctx, tags[0]); err != nil {
+			logger.WithError(err).WithField("expired_image", tags[0]).Debug("Failed to remove expired rollback image")
+		}
+		tags = tags[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package updater
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+)
+
+// scheduleBySize orders the containers of a cycle so that quick updates go
+// first: by how long the last pull of their image took, then by the size of
+// the image they run. The worker pool takes containers in order, so a few
+// multi-GB pulls occupy its workers only once the small updates are through
+// instead of holding dozens of them back.
+func (u *Updater) scheduleBySize(ctx context.Context, containers []docker.Container) []docker.Container {
+	pullTimes := u.lastPullTimes()
+	sizes := u.imageSizes(ctx)
+
+	ordered := slices.Clone(containers)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ti, tj := pullTimes[ordered[i].Image], pullTimes[ordered[j].Image]
+		if ti != tj {
+			return ti < tj
+		}
+		return sizes[ordered[i].ImageID] < sizes[ordered[j].ImageID]
+	})
+	return ordered
+}
+
+// imageSizes returns the size of each local image by ID, nil when the
+// images can't be listed
+func (u *Updater) imageSizes(ctx context.Context) map[string]int64 {
+	images, err := u.client.ListImages(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Debug("Failed to list images, not ordering containers by image size")
+		return nil
+	}
+	sizes := make(map[string]int64, len(images))
+	for _, img := range images {
+		sizes[img.ID] = img.Size
+	}
+	return sizes
+}
@@ -0,0 +1,126 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+)
+
+// DefaultRollbackTimeout is the grace period of containers whose
+// dockwarden.rollback.enable label enables rollbacks while
+// DOCKWARDEN_ROLLBACK_TIMEOUT is not set
+const DefaultRollbackTimeout = time.Minute
+
+// settlePollInterval is how often an updated container is inspected during
+// its grace period
+const settlePollInterval = 2 * time.Second
+
+// ErrUnhealthyUpdate is returned when an updated container exits, restarts or
+// turns unhealthy within its grace period
+var ErrUnhealthyUpdate = errors.New("updated container failed to start")
+
+// rollbackTimeout returns the grace period of ctr after an update, 0 when it
+// isn't rolled back automatically
+func (u *Updater) rollbackTimeout(ctr docker.Container) time.Duration {
+	if !ctr.RollbackEnabled(u.config.RollbackTimeout > 0) {
+		return 0
+	}
+	if u.config.RollbackTimeout > 0 {
+		return u.config.RollbackTimeout
+	}
+	return DefaultRollbackTimeout
+}
+
+// waitSettled watches the container id that replaced ctr during its grace
+// period. It fails as soon as the container stops running, restarts or
+// reports unhealthy. A container with a healthcheck passes once it is
+// healthy and fails if it isn't by the end of the grace period; one without
+// has to keep running for all of it.
+func (u *Updater) waitSettled(ctx context.Context, ctr docker.Container, id string) error {
+	timeout := u.rollbackTimeout(ctr)
+	if timeout == 0 {
+		return nil
+	}
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "settle")
+	logger.WithField("timeout", timeout.String()).Debug("Waiting for updated container to settle")
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(settlePollInterval)
+	defer ticker.Stop()
+
+	restarts := -1
+	expired := false
+	for {
+		current, err := u.client.GetContainer(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to inspect updated container: %w", err)
+		}
+
+		switch {
+		case !current.IsRunning():
+			return fmt.Errorf("%w: container is %s", ErrUnhealthyUpdate, current.State)
+		case restarts >= 0 && current.RestartCount > restarts:
+			return fmt.Errorf("%w: container restarted", ErrUnhealthyUpdate)
+		case current.IsUnhealthy():
+			return fmt.Errorf("%w: container is unhealthy", ErrUnhealthyUpdate)
+		case current.HealthStatus == "healthy":
+			logger.Debug("Updated container is healthy")
+			return nil
+		case expired && current.HealthStatus != "":
+			return fmt.Errorf("%w: container didn't become healthy within %s", ErrUnhealthyUpdate, timeout)
+		case expired:
+			logger.Debug("Updated container kept running")
+			return nil
+		}
+		restarts = current.RestartCount
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for updated container: %w", ctx.Err())
+		case <-deadline.C:
+			// Inspect once more before deciding
+			expired = true
+		case <-ticker.C:
+		}
+	}
+}
+
+// rejectImage remembers the image ctr was rolled back from, so later cycles
+// don't update it to the same image again
+func (u *Updater) rejectImage(ctr docker.Container, image docker.ImageInfo) {
+	u.statusesMu.Lock()
+	defer u.statusesMu.Unlock()
+
+	status := u.status(ctr.Identity(), ctr.Name)
+	status.RejectedImageID = image.ID
+	status.RejectedDigest = image.Digest
+	u.saveQuarantineLocked()
+}
+
+// rejectedImage returns the ID and registry digest of the image ctr was last
+// rolled back from, empty when it wasn't
+func (u *Updater) rejectedImage(ctr docker.Container) (string, string) {
+	status, ok := u.ContainerStatus(ctr.Identity())
+	if !ok {
+		return "", ""
+	}
+	return status.RejectedImageID, status.RejectedDigest
+}
+
+// rollBack moves the updated container id back to the image ctr ran before,
+// after cause failed the update. It runs even when ctx is done, so a
+// container that ran out of time isn't left on an image that failed.
+func (u *Updater) rollBack(ctx context.Context, ctr docker.Container, id string, cause error) error {
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "rollback")
+	if err := u.rollbackContainer(context.WithoutCancel(ctx), ctr, id); err != nil {
+		logger.WithError(err).Error("Failed to roll back container")
+		return fmt.Errorf("%w (rollback failed: %v)", cause, err)
+	}
+	logger.WithError(cause).Warn("Rolled back container after failed update")
+	return fmt.Errorf("%w (rolled back)", cause)
+}
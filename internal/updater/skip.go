@@ -0,0 +1,146 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/logging"
+)
+
+// Skip reason codes, stable for API consumers
+const (
+	SkipSelf           = "self"
+	SkipSocketProxy    = "socket_proxy"
+	SkipDisabled       = "disabled"
+	SkipLabel          = "label_disabled"
+	SkipScope          = "scope_mismatch"
+	SkipStopped        = "not_running"
+	SkipUpdateDisabled = "update_disabled"
+	SkipPinnedTag      = "pinned_tag"
+	SkipLocalImage     = "local_image"
+	SkipTagFiltered    = "tag_filtered"
+	SkipEphemeral      = "ephemeral"
+	SkipQuarantined    = "quarantined"
+	SkipRolledBack     = "rolled_back"
+)
+
+// SkipReason explains why the updater leaves a container alone
+type SkipReason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func skip(code, format string, args ...interface{}) *SkipReason {
+	return &SkipReason{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// SkipReason returns why ctr would not be updated by the next cycle, or nil
+// when it will be checked for updates. It combines the selection rules
+// (self-protection, exclusions, labels, scope, state) with image checks
+// (pinned tags, locally built images).
+func (u *Updater) SkipReason(ctx context.Context, ctr docker.Container) *SkipReason {
+	if reason := u.filterReason(ctr); reason != nil {
+		return reason
+	}
+	// A requested tag change bypasses the image checks of the current tag
+	if target := ctr.TargetImage(); target != "" {
+		return tagFilterReason(ctr, target)
+	}
+	return u.imageSkipReason(ctx, ctr)
+}
+
+// filterReason applies the container selection rules
+func (u *Updater) filterReason(ctr docker.Container) *SkipReason {
+	// Skip dockwarden's own container to prevent self-update suicide
+	if isSelfContainer(ctr) {
+		return skip(SkipSelf, "DockWarden's own container (self-update protection)")
+	}
+
+	// The socket proxy DockWarden reaches Docker through can't be recreated
+	// by DockWarden without losing the connection midway
+	if host := u.client.DaemonHost(); ctr.ProvidesDaemon(host) {
+		return skip(SkipSocketProxy, "serves DockWarden's Docker API at %s (label %s=false overrides)", host, docker.SocketProxyLabel)
+	}
+
+	// Short-lived containers of CI runners and one-shot jobs, unless enabled
+	// explicitly
+	if reason := ctr.EphemeralReason(u.config.EphemeralRules, u.config.EphemeralMinAge, time.Now()); reason != "" && !ctr.IsEnabled(u.config.LabelName, false) {
+		return skip(SkipEphemeral, "ephemeral container: %s (label %s=true manages it anyway)", reason, u.config.LabelName)
+	}
+
+	if status, ok := u.ContainerStatus(ctr.Identity()); ok && status.Quarantined() {
+		return skip(SkipQuarantined, "quarantined after %d failed updates in a row (last error: %s), reset through the API", status.Failures, status.LastError)
+	}
+
+	for _, disabled := range u.config.DisableContainers {
+		if ctr.Name == disabled {
+			return skip(SkipDisabled, "excluded by --disable-containers")
+		}
+	}
+
+	if u.config.LabelEnable && !ctr.IsEnabled(u.config.LabelName, false) {
+		return skip(SkipLabel, "label %s=true is required (label-enable mode)", u.config.LabelName)
+	}
+
+	if u.config.Scope != "" && ctr.GetScope() != u.config.Scope {
+		if ctr.GetScope() == "" {
+			return skip(SkipScope, "no dockwarden.scope label, this instance manages scope %q", u.config.Scope)
+		}
+		return skip(SkipScope, "scope %q does not match this instance's scope %q", ctr.GetScope(), u.config.Scope)
+	}
+
+	// Only running containers (unless configured otherwise)
+	if !ctr.IsRunning() && !u.config.IncludeStopped {
+		return skip(SkipStopped, "container is %s (enable --include-stopped to update it)", ctr.State)
+	}
+
+	if !ctr.UpdateEnabled() {
+		return skip(SkipUpdateDisabled, "updates disabled by label dockwarden.update.enable=false")
+	}
+
+	return nil
+}
+
+// tagFilterReason refuses a tag change the container's tag filter excludes
+func tagFilterReason(ctr docker.Container, targetImage string) *SkipReason {
+	filter, err := ctr.TagFilter()
+	if err != nil {
+		return skip(SkipTagFiltered, "%v", err)
+	}
+	if tag := extractTag(targetImage); !filter.Allows(tag) {
+		return skip(SkipTagFiltered, "target tag %q is excluded by the dockwarden.tags filters", tag)
+	}
+	return nil
+}
+
+// imageSkipReason applies the image checks done before pulling
+func (u *Updater) imageSkipReason(ctx context.Context, ctr docker.Container) *SkipReason {
+	// Docker reports the image ID instead of a name once the tag moved away
+	if strings.HasPrefix(ctr.Image, "sha256:") {
+		return skip(SkipLocalImage, "container references an untagged image ID")
+	}
+
+	// Skip pulling if image has a pinned tag (specific version that won't change)
+	if isPinnedTag(ctr.Image) {
+		return skip(SkipPinnedTag, "pinned tag %q won't change (use dockwarden.update.target-tag to move it)", extractTag(ctr.Image))
+	}
+
+	// Offline, every image is loaded rather than pulled
+	if u.config.Offline {
+		return nil
+	}
+
+	local, err := u.client.IsLocalImage(ctx, ctr.Image)
+	if err != nil {
+		logging.WithFields(ctx, containerFields(ctr)).WithError(err).Debug("Failed to inspect image for skip reason")
+		return nil
+	}
+	if local {
+		return skip(SkipLocalImage, "image was built or loaded locally and has no registry digest")
+	}
+
+	return nil
+}
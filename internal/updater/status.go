@@ -0,0 +1,158 @@
+package updater
+
+import (
+	"sort"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/eol"
+)
+
+// ContainerStatus is what the updater last observed about a container
+type ContainerStatus struct {
+	Name            string
+	Identity        string
+	Image           string
+	UpdateAvailable bool
+	// UpdateAvailableSince is when the available update was first seen
+	UpdateAvailableSince time.Time
+	LastUpdated          time.Time
+	// EOL is the end-of-life status of the container's tag, when checked
+	EOL *eol.Status
+	// Failures counts the failed updates in a row; the container is
+	// quarantined once it reaches QuarantineAfter
+	Failures      int
+	LastError     string
+	QuarantinedAt time.Time
+	// RejectedImageID and RejectedDigest identify the image the container
+	// was last rolled back from; it isn't updated to that image again
+	RejectedImageID string
+	RejectedDigest  string
+
+	// eolNotified remembers the release cycle an EOL warning was sent for
+	eolNotified string
+}
+
+// Staleness returns how long the container has been running an image older
+// than the one its registry serves, or zero when it is up to date
+func (s ContainerStatus) Staleness(now time.Time) time.Duration {
+	if !s.UpdateAvailable || s.UpdateAvailableSince.IsZero() {
+		return 0
+	}
+	return now.Sub(s.UpdateAvailableSince)
+}
+
+// trackResult records the outcome of processing a container. Containers are
+// tracked by identity since recreating a container changes its ID.
+func (u *Updater) trackResult(result UpdateResult) {
+	if result.Error != nil {
+		u.trackFailure(result)
+		return
+	}
+
+	u.statusesMu.Lock()
+	defer u.statusesMu.Unlock()
+
+	status := u.status(result.Identity, result.ContainerName)
+	status.Image = result.Image
+	changed := status.Failures > 0
+	status.Failures = 0
+	status.LastError = ""
+	status.setUpdateAvailable(result.UpdateAvailable)
+	if result.Updated {
+		status.LastUpdated = time.Now()
+		changed = changed || status.RejectedImageID != ""
+		status.RejectedImageID = ""
+		status.RejectedDigest = ""
+	}
+	if changed {
+		u.saveQuarantineLocked()
+	}
+}
+
+// Quarantined reports whether the container stopped being updated after
+// too many failed updates in a row
+func (s ContainerStatus) Quarantined() bool {
+	return !s.QuarantinedAt.IsZero()
+}
+
+// trackPlanItem records what a plan found out about a container's image
+func (u *Updater) trackPlanItem(item PlanItem) {
+	if item.Action != PlanUpdate && item.Action != PlanUpToDate {
+		return
+	}
+
+	u.statusesMu.Lock()
+	defer u.statusesMu.Unlock()
+
+	status := u.status(item.Identity, item.ContainerName)
+	status.Image = item.Image
+	status.setUpdateAvailable(item.Action == PlanUpdate)
+}
+
+// status returns the tracked status of a container identity, creating it
+// when needed. The name follows renames. Callers hold statusesMu.
+func (u *Updater) status(identity, name string) *ContainerStatus {
+	if identity == "" {
+		identity = name
+	}
+	status, ok := u.statuses[identity]
+	if !ok {
+		status = &ContainerStatus{Identity: identity}
+		u.statuses[identity] = status
+	}
+	status.Name = name
+	return status
+}
+
+// setUpdateAvailable updates the flag, keeping the time the update was first
+// seen while it stays available
+func (s *ContainerStatus) setUpdateAvailable(available bool) {
+	switch {
+	case !available:
+		s.UpdateAvailableSince = time.Time{}
+	case !s.UpdateAvailable || s.UpdateAvailableSince.IsZero():
+		s.UpdateAvailableSince = time.Now()
+	}
+	s.UpdateAvailable = available
+}
+
+// ContainerStatus returns the tracked status of a container by identity
+func (u *Updater) ContainerStatus(identity string) (ContainerStatus, bool) {
+	u.statusesMu.Lock()
+	defer u.statusesMu.Unlock()
+
+	status, ok := u.statuses[identity]
+	if !ok {
+		return ContainerStatus{}, false
+	}
+	return *status, true
+}
+
+// PendingUpdates returns the number of containers with an update that was
+// found but not applied, because of monitor only or no restart mode or a
+// deferred cycle
+func (u *Updater) PendingUpdates() int {
+	u.statusesMu.Lock()
+	defer u.statusesMu.Unlock()
+
+	pending := 0
+	for _, status := range u.statuses {
+		if status.UpdateAvailable {
+			pending++
+		}
+	}
+	return pending
+}
+
+// ContainerStatuses returns the tracked container statuses sorted by name
+func (u *Updater) ContainerStatuses() []ContainerStatus {
+	u.statusesMu.Lock()
+	defer u.statusesMu.Unlock()
+
+	statuses := make([]ContainerStatus, 0, len(u.statuses))
+	for _, status := range u.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
@@ -2,6 +2,8 @@ package updater
 
 import (
 	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -10,6 +12,18 @@ import (
 
 	"github.com/emon5122/dockwarden/internal/config"
 	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/eol"
+	"github.com/emon5122/dockwarden/internal/events"
+	"github.com/emon5122/dockwarden/internal/gitops"
+	"github.com/emon5122/dockwarden/internal/heartbeat"
+	"github.com/emon5122/dockwarden/internal/history"
+	"github.com/emon5122/dockwarden/internal/lock"
+	"github.com/emon5122/dockwarden/internal/lockfile"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/notify"
+	"github.com/emon5122/dockwarden/internal/pool"
+	"github.com/emon5122/dockwarden/internal/registry"
+	"github.com/emon5122/dockwarden/internal/release"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,38 +31,156 @@ import (
 type UpdateResult struct {
 	ContainerID   string
 	ContainerName string
+	Identity      string
+	Image         string
 	OldImageID    string
 	NewImageID    string
 	Updated       bool
-	Error         error
+	// UpdateAvailable is set when an update was found but not applied
+	UpdateAvailable bool
+	Error           error
+	// OldImage is the image the container ran before its update
+	OldImage docker.ImageInfo
+}
+
+// CycleSummary counts the outcome of an update cycle
+type CycleSummary struct {
+	Checked int
+	Updated int
+	Failed  int
 }
 
 // Updater handles container image updates using Go's native concurrency
 type Updater struct {
-	client docker.Client
-	config *config.Config
+	client    docker.Client
+	registry  *registry.Client
+	heartbeat *heartbeat.Pinger
+	events    *events.Broker
+	config    *config.Config
 
 	// Statistics
 	totalUpdated atomic.Int64
 	totalFailed  atomic.Int64
 	lastRun      time.Time
+	lastCycle    CycleSummary
 	lastRunMu    sync.RWMutex
+
+	// Per-container status, keyed by container name
+	statuses   map[string]*ContainerStatus
+	statusesMu sync.Mutex
+
+	// Pull statistics, keyed by registry domain, and the duration of the
+	// last pull of each image
+	pulls     map[string]*PullStats
+	pullTimes map[string]time.Duration
+	pullsMu   sync.Mutex
+
+	eol      *eol.Checker
+	notifier *notify.Notifier
+	history  *history.Store
+	lockfile *lockfile.Lockfile
+	gitops   *gitops.Repo
+	lock     *lock.Lock
+	elector  *lock.Elector
+
+	// signatureRoots are the CAs image signatures are verified against
+	signatureRoots *x509.CertPool
+
+	// cycle is held by update cycles, plan applies and forced recreates, so
+	// only one of them handles containers at a time
+	cycle chan struct{}
+
+	// Latest DockWarden release, and the release last notified when the
+	// data directory can't keep it
+	release             *release.Checker
+	lastReleaseNotified string
+
+	poolMetrics *pool.Metrics
 }
 
+// Filter restricts an operation to the containers it accepts; a nil Filter
+// accepts every container
+type Filter func(docker.Container) bool
+
 // New creates a new Updater
 func New(client docker.Client, cfg *config.Config) *Updater {
-	return &Updater{
-		client: client,
-		config: cfg,
-	}
+	reg := registry.NewClient(nil)
+	reg.SetTagCacheTTL(cfg.TagCacheTTL)
+
+	u := &Updater{
+		client:    client,
+		registry:  reg,
+		heartbeat: heartbeat.New(cfg.HeartbeatURL),
+		events:    events.NewBroker(),
+		config:    cfg,
+		statuses:  make(map[string]*ContainerStatus),
+		pulls:     make(map[string]*PullStats),
+		pullTimes: make(map[string]time.Duration),
+		eol:       newEOLChecker(cfg.EOLRules, cfg.EOLCheck),
+		release:   newReleaseChecker(cfg.VersionCheck),
+		notifier:  notify.FromConfig(cfg),
+		history:   openHistory(cfg),
+		lockfile:  openLockfile(cfg.Lockfile),
+		gitops:    newGitOps(cfg),
+		lock:      newCycleLock(cfg),
+		cycle:     make(chan struct{}, 1),
+
+		signatureRoots: loadSignatureRoots(cfg),
+
+		poolMetrics: &pool.Metrics{},
+	}
+	u.loadQuarantine()
+	return u
+}
+
+// Events returns the broker the updater publishes cycle and container events to
+func (u *Updater) Events() *events.Broker {
+	return u.events
 }
 
 // Run executes an update cycle with concurrent container processing
 func (u *Updater) Run() error {
-	ctx := context.Background()
+	return u.RunMatching(nil)
+}
+
+// RunMatching executes an update cycle limited to the containers accepted by
+// match, on top of the usual selection rules
+func (u *Updater) RunMatching(match Filter) error {
+	cycleID := logging.NewCycleID()
+	logger := log.WithField(logging.FieldCycleID, cycleID)
+	ctx := logging.WithLogger(context.Background(), logger)
 	startTime := time.Now()
 
-	log.Info("Starting update check...")
+	if !u.IsLeader() {
+		logger.Debug("Standby instance, skipping update cycle")
+		return nil
+	}
+
+	if reason := u.checkGate(ctx); reason != "" {
+		logger.WithFields(log.Fields{
+			logging.FieldAction: "cycle_deferred",
+			"reason":            reason,
+		}).Info("Update cycle deferred by gate")
+		u.heartbeat.Success(ctx, "deferred: "+reason)
+		u.events.Publish(events.Event{Type: events.TypeCycleDeferred, CycleID: cycleID, Message: reason})
+		return nil
+	}
+
+	unlock, err := u.lockCycle(ctx)
+	switch {
+	case errors.Is(err, ErrCycleLocked):
+		logger.WithField(logging.FieldAction, "cycle_deferred").Info("Another instance is running an update cycle, skipping")
+		u.events.Publish(events.Event{Type: events.TypeCycleDeferred, CycleID: cycleID, Message: "cycle lock held by another instance"})
+		return nil
+	case err != nil:
+		u.heartbeat.Fail(ctx, err.Error())
+		return err
+	}
+	defer unlock()
+
+	logger.WithField(logging.FieldAction, "cycle_start").Info("Starting update check...")
+	u.heartbeat.Start(ctx)
+	u.events.Publish(events.Event{Type: events.TypeCycleStart, CycleID: cycleID, Message: "update cycle started"})
 
 	// List containers
 	containers, err := u.client.ListContainers(ctx, docker.ListOptions{
@@ -56,127 +188,168 @@ func (u *Updater) Run() error {
 		IncludeHealth: true,
 	})
 	if err != nil {
+		u.heartbeat.Fail(ctx, err.Error())
 		return fmt.Errorf("failed to list containers: %w", err)
 	}
 
 	// Filter containers
-	filtered := u.filterContainers(containers)
-	log.Debugf("Found %d containers to check (%d total)", len(filtered), len(containers))
+	filtered := u.filterContainers(ctx, containers, match)
+	logger.WithFields(log.Fields{
+		"checked": len(filtered),
+		"total":   len(containers),
+	}).Debug("Found containers to check")
 
 	if len(filtered) == 0 {
-		log.Info("No containers to update")
+		logger.Info("No containers to update")
 		u.recordRun(startTime)
+		u.recordCycle(CycleSummary{})
+		u.heartbeat.Success(ctx, "no containers to update")
+		u.events.Publish(events.Event{Type: events.TypeCycleEnd, CycleID: cycleID, Message: "no containers to update"})
 		return nil
 	}
 
-	// Process containers concurrently using goroutines
-	results := u.processContainersConcurrently(ctx, filtered)
+	// Pull all images first, then recreate what changed; grouped containers
+	// are updated together afterwards
+	ungrouped, groups := splitGroups(filtered)
+	results := u.processContainersConcurrently(ctx, ungrouped)
+	results = append(results, u.processGroups(ctx, groups)...)
 
 	// Summarize results
 	var updated, failed int
+	var updates []UpdateResult
 	for _, result := range results {
+		u.trackResult(result)
+		clog := logger.WithField(logging.FieldContainer, result.ContainerName)
 		if result.Error != nil {
-			log.Errorf("Failed to process %s: %v", result.ContainerName, result.Error)
+			clog.WithError(result.Error).Error("Failed to process container")
 			failed++
+			u.events.Publish(events.Event{
+				Type:      events.TypeContainerFailed,
+				CycleID:   cycleID,
+				Container: result.ContainerName,
+				Image:     result.Image,
+				Message:   result.Error.Error(),
+			})
 		} else if result.Updated {
-			log.Infof("Updated container %s", result.ContainerName)
+			clog.WithField(logging.FieldAction, "update").Info("Updated container")
 			updated++
+			updates = append(updates, result)
+			u.recordHistory(ctx, result)
+			u.recordLockfile(ctx, result)
+			u.notifyUpdated(ctx, result)
+			u.events.Publish(events.Event{
+				Type:      events.TypeContainerUpdate,
+				CycleID:   cycleID,
+				Container: result.ContainerName,
+				Image:     result.Image,
+				Message:   "container updated",
+			})
 		}
 	}
 
+	u.publishGitOps(ctx, updates)
+	u.checkEOL(ctx, filtered)
+	u.pruneHistory(ctx)
+
 	// Update stats
 	u.totalUpdated.Add(int64(updated))
 	u.totalFailed.Add(int64(failed))
 	u.recordRun(startTime)
+	u.recordCycle(CycleSummary{Checked: len(filtered), Updated: updated, Failed: failed})
 
 	duration := time.Since(startTime)
-	log.Infof("Update check complete: %d updated, %d failed, took %s", updated, failed, duration.Round(time.Millisecond))
-
-	return nil
-}
-
-// processContainersConcurrently processes all containers using goroutines
-func (u *Updater) processContainersConcurrently(ctx context.Context, containers []docker.Container) []UpdateResult {
-	resultsChan := make(chan UpdateResult, len(containers))
-	var wg sync.WaitGroup
-
-	// Determine concurrency limit - higher for faster checks
-	maxConcurrency := 10
-	if u.config.RollingRestart {
-		maxConcurrency = 1 // Sequential for rolling restart
-	}
-	semaphore := make(chan struct{}, maxConcurrency)
+	logger.WithFields(log.Fields{
+		logging.FieldAction: "cycle_end",
+		"updated":           updated,
+		"failed":            failed,
+		"duration":          duration.Round(time.Millisecond).String(),
+	}).Info("Update check complete")
 
-	for _, ctr := range containers {
-		if !ctr.UpdateEnabled() {
-			log.Debugf("Skipping %s: updates disabled", ctr.Name)
-			continue
-		}
-
-		wg.Add(1)
-		go func(container docker.Container) {
-			defer wg.Done()
-
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			result := u.processContainer(ctx, container)
-			resultsChan <- result
-		}(ctr)
+	summary := fmt.Sprintf("%d checked, %d updated, %d failed", len(filtered), updated, failed)
+	u.events.Publish(events.Event{Type: events.TypeCycleEnd, CycleID: cycleID, Message: summary})
+	if failed > 0 {
+		u.heartbeat.Fail(ctx, summary)
+	} else {
+		u.heartbeat.Success(ctx, summary)
 	}
 
-	// Wait for all goroutines and close channel
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
-
-	// Collect results
-	var results []UpdateResult
-	for result := range resultsChan {
-		results = append(results, result)
-	}
+	return nil
+}
 
-	return results
+// PoolStats returns statistics of the worker pools used by update cycles and
+// plans
+func (u *Updater) PoolStats() pool.Snapshot {
+	return u.poolMetrics.Snapshot()
 }
 
-// processContainer processes a single container
-func (u *Updater) processContainer(ctx context.Context, ctr docker.Container) UpdateResult {
-	result := UpdateResult{
+// checkPhase pulls what a container needs and decides whether it has to be
+// recreated. The returned result is final unless recreate is true, in which
+// case applyUpdate completes it.
+func (u *Updater) checkPhase(ctx context.Context, ctr docker.Container) (result UpdateResult, targetImage string, recreate bool) {
+	logger := logging.FromContext(ctx)
+	result = UpdateResult{
 		ContainerID:   ctr.ID,
 		ContainerName: ctr.Name,
+		Identity:      ctr.Identity(),
+		Image:         ctr.Image,
 		OldImageID:    ctr.ImageID,
 	}
 
-	// Check for update
-	needsUpdate, err := u.checkForUpdate(ctx, ctr)
+	targetImage, needsUpdate, err := u.checkContainer(ctx, ctr)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to check for updates: %w", err)
-		return result
+		result.Error = err
+		return result, "", false
 	}
 
 	if !needsUpdate {
-		log.Debugf("Container %s is up to date", ctr.Name)
-		return result
+		logger.WithField(logging.FieldAction, "check").Debug("Container is up to date")
+		return result, "", false
+	}
+
+	newImage := ctr.Image
+	if targetImage != "" {
+		newImage = targetImage
+	}
+	if err := u.checkArch(ctx, ctr, newImage); err != nil {
+		result.Error = err
+		return result, "", false
 	}
 
 	// Monitor only mode
 	if u.config.MonitorOnly {
-		log.Infof("Update available for %s (monitor only mode)", ctr.Name)
-		return result
+		logger.WithField(logging.FieldAction, "check").Info("Update available (monitor only mode)")
+		result.UpdateAvailable = true
+		return result, "", false
 	}
 
-	// Perform update
-	if err := u.updateContainer(ctx, ctr); err != nil {
+	// No restart mode: the new image was pulled by the check, the container
+	// keeps running the old one
+	if u.config.NoRestart {
+		logger.WithField(logging.FieldAction, "check").Info("Update pulled (no restart mode)")
+		result.UpdateAvailable = true
+		return result, "", false
+	}
+
+	return result, targetImage, true
+}
+
+// applyUpdate recreates a container whose new image was pulled by
+// checkPhase and completes its result
+func (u *Updater) applyUpdate(ctx context.Context, ctr docker.Container, targetImage string, result UpdateResult) UpdateResult {
+	result.OldImage = u.imageInfo(ctx, ctr.ImageID)
+	newID, err := u.updateContainer(ctx, ctr, targetImage)
+	if err != nil {
 		result.Error = fmt.Errorf("failed to update: %w", err)
 		return result
 	}
 
 	result.Updated = true
+	if targetImage != "" {
+		result.Image = targetImage
+	}
 
 	// Get new image ID
-	newCtr, err := u.client.GetContainer(ctx, ctr.ID)
+	newCtr, err := u.client.GetContainer(ctx, newID)
 	if err == nil {
 		result.NewImageID = newCtr.ImageID
 	}
@@ -184,41 +357,37 @@ func (u *Updater) processContainer(ctx context.Context, ctr docker.Container) Up
 	return result
 }
 
+// checkContainer pulls what the container needs and reports whether it has
+// to be recreated. A requested tag change takes priority over a digest check
+// of the current tag; targetImage is set in that case.
+func (u *Updater) checkContainer(ctx context.Context, ctr docker.Container) (targetImage string, needsUpdate bool, err error) {
+	targetImage = ctr.TargetImage()
+	if targetImage != "" {
+		needsUpdate, err = u.checkForRetag(ctx, ctr, targetImage)
+		if err != nil {
+			return targetImage, false, fmt.Errorf("failed to prepare tag change: %w", err)
+		}
+		return targetImage, needsUpdate, nil
+	}
+
+	needsUpdate, err = u.checkForUpdate(ctx, ctr)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	return "", needsUpdate, nil
+}
+
 // filterContainers returns containers that should be managed
-func (u *Updater) filterContainers(containers []docker.Container) []docker.Container {
+func (u *Updater) filterContainers(ctx context.Context, containers []docker.Container, match Filter) []docker.Container {
 	filtered := make([]docker.Container, 0, len(containers))
 
-containerLoop:
 	for _, ctr := range containers {
-		// Skip dockwarden's own container to prevent self-update suicide
-		if isSelfContainer(ctr) {
-			log.Debugf("Skipping %s: self-update protection (dockwarden container)", ctr.Name)
+		if match != nil && !match(ctr) {
 			continue
 		}
 
-		// Skip disabled containers
-		for _, disabled := range u.config.DisableContainers {
-			if ctr.Name == disabled {
-				continue containerLoop
-			}
-		}
-
-		// Check label filter
-		if u.config.LabelEnable {
-			if !ctr.IsEnabled(u.config.LabelName, false) {
-				continue
-			}
-		}
-
-		// Check scope
-		if u.config.Scope != "" {
-			if ctr.GetScope() != u.config.Scope {
-				continue
-			}
-		}
-
-		// Only running containers (unless configured otherwise)
-		if !ctr.IsRunning() && !u.config.IncludeStopped {
+		if reason := u.filterReason(ctr); reason != nil {
+			logging.WithFields(ctx, containerFields(ctr)).WithField("reason", reason.Code).Debugf("Skipping container: %s", reason.Message)
 			continue
 		}
 
@@ -230,24 +399,54 @@ containerLoop:
 
 // checkForUpdate checks if a container has an available update
 func (u *Updater) checkForUpdate(ctx context.Context, ctr docker.Container) (bool, error) {
+	logger := logging.FromContext(ctx).WithField(logging.FieldAction, "check")
+
 	if u.config.NoPull {
 		return false, nil
 	}
 
-	// Skip pulling if image has a pinned tag (specific version that won't change)
-	if isPinnedTag(ctr.Image) {
-		log.Debugf("Skipping pull for %s: image has pinned tag", ctr.Name)
+	// Skip pinned tags and images that don't come from a registry
+	if reason := u.imageSkipReason(ctx, ctr); reason != nil {
+		logger.WithField("reason", reason.Code).Debugf("Skipping pull: %s", reason.Message)
 		return false, nil
 	}
 
+	if u.config.Offline {
+		return u.checkLoadedImage(ctx, ctr)
+	}
+
 	// Get current image digest
 	currentDigest, err := u.client.GetImageDigest(ctx, ctr.Image)
 	if err != nil {
 		return false, fmt.Errorf("failed to get current digest: %w", err)
 	}
 
+	// Ask the registry for the current digest first, so unchanged images
+	// aren't pulled. Registries the anonymous check can't read, e.g. private
+	// ones the daemon holds credentials for, fall back to a pull.
+	_, rejectedDigest := u.rejectedImage(ctr)
+	remoteDigest, err := u.registry.Digest(ctx, ctr.Image)
+	switch {
+	case err != nil:
+		logger.WithError(err).Debug("Manifest check failed, pulling image")
+	case remoteDigest == currentDigest:
+		u.recordSkippedPull(registry.Domain(ctr.Image))
+		logger.WithField("digest", truncateID(currentDigest)).Debug("Registry digest unchanged, skipping pull")
+		// An image pulled by an earlier cycle that didn't apply it is
+		// still pending; it may not have been pulled by DockWarden
+		pending, err := u.checkLoadedImage(ctx, ctr)
+		if pending && err == nil {
+			err = u.verifySignature(ctx, ctr.Image, currentDigest)
+		}
+		return pending && err == nil, err
+	case rejectedDigest != "" && remoteDigest == rejectedDigest:
+		u.recordSkippedPull(registry.Domain(ctr.Image))
+		logger.WithField("digest", truncateID(remoteDigest)).Debug("Registry still serves the image the container was rolled back from, skipping pull")
+		return false, nil
+	}
+
 	// Pull latest image
-	if err := u.client.PullImage(ctx, ctr.Image); err != nil {
+	if err := u.pullExpecting(ctx, ctr.Image, remoteDigest, ctr.ImageID); err != nil {
 		return false, fmt.Errorf("failed to pull image: %w", err)
 	}
 
@@ -257,40 +456,191 @@ func (u *Updater) checkForUpdate(ctx context.Context, ctr docker.Container) (boo
 		return false, fmt.Errorf("failed to get new digest: %w", err)
 	}
 
+	// The pull fetched the image the container was rolled back from, point
+	// the reference back at the image it runs
+	if rejectedDigest != "" && newDigest == rejectedDigest {
+		logger.WithField("digest", truncateID(newDigest)).Debug("Pulled the image the container was rolled back from, skipping update")
+		if err := u.client.TagImage(ctx, ctr.ImageID, ctr.Image); err != nil {
+			return false, fmt.Errorf("failed to restore current image: %w", err)
+		}
+		return false, nil
+	}
+
 	// Compare digests
 	if currentDigest != newDigest {
-		log.Debugf("Container %s has update: %s -> %s", ctr.Name, truncateID(currentDigest), truncateID(newDigest))
+		logger.WithFields(log.Fields{
+			"old_digest": truncateID(currentDigest),
+			"new_digest": truncateID(newDigest),
+		}).Debug("Container has update")
 		return true, nil
 	}
 
-	return false, nil
+	// An image pulled by an earlier cycle that didn't apply it, e.g. in
+	// monitor only or no restart mode, is still pending
+	return u.checkLoadedImage(ctx, ctr)
 }
 
-// updateContainer updates a container to the new image
-func (u *Updater) updateContainer(ctx context.Context, ctr docker.Container) error {
-	oldImageID := ctr.ImageID
-	timeout := ctr.GetStopTimeout(u.config.StopTimeout)
+// checkForRetag pulls the image requested by the dockwarden.update.target-tag
+// label so the container can be moved onto it
+func (u *Updater) checkForRetag(ctx context.Context, ctr docker.Container, targetImage string) (bool, error) {
+	logger := logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldAction: "retag",
+		"target_image":      targetImage,
+	})
+
+	if u.config.NoPull {
+		logger.Debug("Skipping tag change: pulling disabled")
+		return false, nil
+	}
 
-	log.Infof("Updating container %s", ctr.Name)
+	if u.config.Offline {
+		if !u.isLoaded(ctx, targetImage) {
+			logger.Debug("Skipping tag change: target image has not been loaded")
+			return false, nil
+		}
+	} else if err := u.pullVerified(ctx, targetImage, ""); err != nil {
+		return false, fmt.Errorf("failed to pull target image: %w", err)
+	}
 
-	// Recreate container with new image
-	_, err := u.client.RecreateContainer(ctx, ctr.ID, timeout)
+	logger.Info("Tag change requested by label")
+	return true, nil
+}
+
+// updateContainer updates a container to the new image and returns the ID of
+// the new container. When targetImage is set the container is recreated from
+// that image reference instead of its current one.
+func (u *Updater) updateContainer(ctx context.Context, ctr docker.Container, targetImage string) (string, error) {
+	u.warnDrift(ctx, ctr)
+
+	newID, err := u.recreate(ctx, ctr, targetImage)
 	if err != nil {
-		return fmt.Errorf("failed to recreate container: %w", err)
+		return "", err
+	}
+
+	if err := u.runPostJob(ctx, ctr, newID); err != nil {
+		return "", u.rollBack(ctx, ctr, newID, err)
+	}
+	if err := u.waitSettled(ctx, ctr, newID); err != nil {
+		return "", u.rollBack(ctx, ctr, newID, err)
+	}
+
+	u.cleanupOldImage(ctx, ctr)
+	return newID, nil
+}
+
+// runPostJob runs the container's post-update job, if any, next to the new
+// container id and fails when the job exits with a non-zero code
+func (u *Updater) runPostJob(ctx context.Context, ctr docker.Container, id string) error {
+	job := ctr.PostJob()
+	if job == nil {
+		return nil
 	}
 
-	// Cleanup old image if enabled
-	if u.config.Cleanup && oldImageID != "" {
-		log.Debugf("Cleaning up old image %s", truncateID(oldImageID))
-		if err := u.client.RemoveImage(ctx, oldImageID); err != nil {
-			// Not a fatal error, just log it
-			log.Debugf("Failed to remove old image %s: %v", truncateID(oldImageID), err)
+	result, err := u.client.RunJob(ctx, id, *job)
+	if err != nil {
+		return fmt.Errorf("post-update job failed: %w", err)
+	}
+	if result.ExitCode != 0 {
+		if result.Output != "" {
+			return fmt.Errorf("post-update job exited with code %d: %s", result.ExitCode, result.Output)
 		}
+		return fmt.Errorf("post-update job exited with code %d", result.ExitCode)
 	}
 
+	logging.FromContext(ctx).WithField(logging.FieldAction, "job").Info("Post-update job succeeded")
 	return nil
 }
 
+// rollbackContainer moves the updated container id back to the image ctr ran
+// before the update
+func (u *Updater) rollbackContainer(ctx context.Context, ctr docker.Container, id string) error {
+	_, err := u.restoreContainer(ctx, ctr, id, "")
+	return err
+}
+
+// restoreContainer moves the container id back to the image ctr ran before
+// and returns the ID of the restored container, which joins the network
+// stack of networkContainer when set. A container that wasn't running
+// before is left stopped.
+func (u *Updater) restoreContainer(ctx context.Context, ctr docker.Container, id, networkContainer string) (string, error) {
+	// Remember the image the reference points at now, so it isn't applied
+	// again until the registry serves a different one
+	if info, err := u.client.InspectImage(ctx, ctr.Image); err == nil && info.ID != ctr.ImageID {
+		u.rejectImage(ctr, info)
+	}
+
+	// Point the original reference back at the previous image, so the
+	// container is recreated from it
+	if err := u.client.TagImage(ctx, ctr.ImageID, ctr.Image); err != nil {
+		return "", fmt.Errorf("failed to restore previous image: %w", err)
+	}
+	newID, err := u.client.RecreateContainer(ctx, id, docker.RecreateOptions{
+		StopTimeout:      ctr.GetStopTimeout(u.config.StopTimeout),
+		Image:            ctr.Image,
+		NetworkContainer: networkContainer,
+		NoStart:          !ctr.IsRunning(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to recreate container: %w", err)
+	}
+	return newID, nil
+}
+
+// recreate recreates a container with the new image. With the compose
+// strategy, compose-managed containers are recreated from their compose
+// file unless a tag change was requested, which only the runtime
+// configuration can express. The recreate isn't cancelled with ctx, so the
+// container is never left removed.
+func (u *Updater) recreate(ctx context.Context, ctr docker.Container, targetImage string) (string, error) {
+	return u.recreateWith(ctx, ctr, docker.RecreateOptions{Image: targetImage})
+}
+
+// recreateWith recreates a container like recreate with further options.
+// Compose only recreates containers it starts with their compose network
+// configuration, so options beyond a tag change skip it.
+func (u *Updater) recreateWith(ctx context.Context, ctr docker.Container, opts docker.RecreateOptions) (string, error) {
+	logging.FromContext(ctx).WithField(logging.FieldAction, "update").Info("Updating container")
+	ctx = context.WithoutCancel(ctx)
+
+	if u.config.RecreateStrategy == RecreateCompose && opts.Image == "" && opts.NetworkContainer == "" && !opts.NoStart {
+		if service, ok := u.composeService(ctx, ctr); ok {
+			return u.composeUp(ctx, ctr, service)
+		}
+	}
+
+	opts.StopTimeout = ctr.GetStopTimeout(u.config.StopTimeout)
+	newID, err := u.client.RecreateContainer(ctx, ctr.ID, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to recreate container: %w", err)
+	}
+	return newID, nil
+}
+
+// cleanupOldImage removes the image the container ran before its update if
+// cleanup is enabled, unless the container's label keeps it
+func (u *Updater) cleanupOldImage(ctx context.Context, ctr docker.Container) {
+	logger := logging.FromContext(ctx)
+	oldImageID := ctr.ImageID
+
+	if ctr.CleanupEnabled(u.config.Cleanup) && oldImageID != "" {
+		clog := logger.WithFields(log.Fields{
+			logging.FieldAction: "cleanup",
+			"old_image_id":      truncateID(oldImageID),
+		})
+		if u.config.RollbackKeep > 0 {
+			if err := u.retainRollbackImage(ctx, ctr, oldImageID); err != nil {
+				clog.WithError(err).Warn("Failed to keep old image for rollback")
+			}
+		} else {
+			clog.Debug("Cleaning up old image")
+			if err := u.client.RemoveImage(ctx, oldImageID); err != nil {
+				// Not a fatal error, just log it
+				clog.WithError(err).Debug("Failed to remove old image")
+			}
+		}
+	}
+}
+
 // recordRun records the time of the last run
 func (u *Updater) recordRun(t time.Time) {
 	u.lastRunMu.Lock()
@@ -298,6 +648,20 @@ func (u *Updater) recordRun(t time.Time) {
 	u.lastRunMu.Unlock()
 }
 
+// recordCycle records the outcome of the last completed cycle
+func (u *Updater) recordCycle(summary CycleSummary) {
+	u.lastRunMu.Lock()
+	u.lastCycle = summary
+	u.lastRunMu.Unlock()
+}
+
+// LastCycle returns the outcome of the last completed update cycle
+func (u *Updater) LastCycle() CycleSummary {
+	u.lastRunMu.RLock()
+	defer u.lastRunMu.RUnlock()
+	return u.lastCycle
+}
+
 // GetStats returns update statistics
 func (u *Updater) GetStats() map[string]interface{} {
 	u.lastRunMu.RLock()
@@ -311,6 +675,15 @@ func (u *Updater) GetStats() map[string]interface{} {
 	}
 }
 
+// containerFields returns the standard log fields identifying a container
+func containerFields(ctr docker.Container) log.Fields {
+	return log.Fields{
+		logging.FieldContainer:   ctr.Name,
+		logging.FieldContainerID: truncateID(ctr.ID),
+		logging.FieldImage:       ctr.Image,
+	}
+}
+
 // truncateID truncates an ID to 12 characters
 func truncateID(id string) string {
 	if len(id) > 12 {
@@ -0,0 +1,113 @@
+package updater
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/emon5122/dockwarden/internal/config"
+	"github.com/emon5122/dockwarden/internal/logging"
+	"github.com/emon5122/dockwarden/internal/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrDigestMismatch is returned when the pulled image isn't the one the
+// registry advertised before the pull
+var ErrDigestMismatch = errors.New("pulled digest differs from the digest advertised at check time")
+
+// pullVerified pulls imageName. With digest verification enabled, the digest
+// the registry advertises is resolved before pulling and the pulled image is
+// refused unless it has that digest, so content swapped between check and
+// pull is never deployed. On a mismatch the tag is pointed back at
+// previousImageID when set. With signature verification enabled, the
+// advertised digest also has to carry a trusted signature before it is
+// pulled. Offline nothing is pulled: the image is used as loaded.
+func (u *Updater) pullVerified(ctx context.Context, imageName, previousImageID string) error {
+	return u.pullExpecting(ctx, imageName, "", previousImageID)
+}
+
+// pullExpecting is pullVerified with the digest the registry advertised
+// already resolved. An empty advertised digest is resolved here when
+// verification is enabled.
+func (u *Updater) pullExpecting(ctx context.Context, imageName, advertised, previousImageID string) error {
+	if u.config.Offline {
+		return nil
+	}
+	if !u.config.VerifyDigest && !u.config.VerifySignatures {
+		return u.pullImage(ctx, imageName)
+	}
+
+	if advertised == "" {
+		var err error
+		if advertised, err = u.registry.Digest(ctx, imageName); err != nil {
+			return fmt.Errorf("failed to resolve digest for verification: %w", err)
+		}
+	}
+	if err := u.verifySignature(ctx, imageName, advertised); err != nil {
+		return err
+	}
+
+	if err := u.pullImage(ctx, imageName); err != nil {
+		return err
+	}
+
+	pulled, err := u.client.GetImageDigest(ctx, imageName)
+	if err != nil {
+		return fmt.Errorf("failed to get pulled digest: %w", err)
+	}
+	if pulled == advertised {
+		return nil
+	}
+
+	logger := logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldAction: "verify",
+		"advertised_digest": truncateID(advertised),
+		"pulled_digest":     truncateID(pulled),
+	})
+	logger.Error("Refusing image: digest changed between check and pull")
+
+	if previousImageID != "" {
+		if err := u.client.TagImage(ctx, previousImageID, imageName); err != nil {
+			logger.WithError(err).Warn("Failed to restore previous image tag")
+		}
+	}
+	return fmt.Errorf("%w: advertised %s, pulled %s", ErrDigestMismatch, advertised, pulled)
+}
+
+// verifySignature checks that the manifest digest of imageName carries a
+// Notary v2 signature trusted by the signature trust store, when signature
+// verification is enabled
+func (u *Updater) verifySignature(ctx context.Context, imageName, digest string) error {
+	if !u.config.VerifySignatures {
+		return nil
+	}
+	logger := logging.FromContext(ctx).WithFields(log.Fields{
+		logging.FieldAction: "verify",
+		"digest":            truncateID(digest),
+	})
+	if u.signatureRoots == nil {
+		return fmt.Errorf("%w: the signature trust store couldn't be loaded", registry.ErrUnsigned)
+	}
+
+	signer, err := u.registry.VerifySignature(ctx, imageName, digest, u.signatureRoots)
+	if err != nil {
+		logger.WithError(err).Error("Refusing image: signature verification failed")
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+	logger.WithField("signer", signer).Debug("Verified image signature")
+	return nil
+}
+
+// loadSignatureRoots loads the signature trust store when signature
+// verification is enabled. Without it every image is refused.
+func loadSignatureRoots(cfg *config.Config) *x509.CertPool {
+	if !cfg.VerifySignatures {
+		return nil
+	}
+	roots, err := registry.LoadTrustStore(cfg.SignatureTrustStore)
+	if err != nil {
+		log.WithError(err).Error("Failed to load signature trust store, refusing every image")
+	}
+	return roots
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed templates static
+var embeddedAssets embed.FS
+
+// uiTemplates are the templates rendered by the web UI, by name
+var uiTemplates = []string{"dashboard", "containers", "stats", "system", "plan", "audit", "images", "imagestats", "labels"}
+
+// assetFS serves the UI templates and static files. Files in the override
+// directory (DOCKWARDEN_UI_DIR) take precedence over the embedded ones, so
+// operators can replace single templates without rebuilding the binary.
+type assetFS struct {
+	override fs.FS
+}
+
+func newAssetFS(dir string) assetFS {
+	if dir == "" {
+		return assetFS{}
+	}
+	return assetFS{override: os.DirFS(dir)}
+}
+
+// Open implements fs.FS
+func (a assetFS) Open(name string) (fs.File, error) {
+	if a.override != nil {
+		f, err := a.override.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return embeddedAssets.Open(name)
+}
+
+// static returns the static/ subtree
+func (a assetFS) static() http.FileSystem {
+	sub, _ := fs.Sub(a, "static")
+	return http.FS(sub)
+}
+
+// loadTemplates parses the UI templates. A replacement template that fails
+// to parse is reported and the embedded one is used instead.
+func (a assetFS) loadTemplates(funcs template.FuncMap) map[string]*template.Template {
+	templates := make(map[string]*template.Template, len(uiTemplates))
+	for _, name := range uiTemplates {
+		tmpl, err := parseTemplate(a, name, funcs)
+		if err != nil {
+			log.WithError(err).WithField("template", name).Error("Failed to parse UI template, using the built-in one")
+			tmpl = template.Must(parseTemplate(embeddedAssets, name, funcs))
+		}
+		templates[name] = tmpl
+	}
+	return templates
+}
+
+func parseTemplate(fsys fs.FS, name string, funcs template.FuncMap) (*template.Template, error) {
+	data, err := fs.ReadFile(fsys, "templates/"+name+".html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	return template.New(name).Funcs(funcs).Parse(string(data))
+}
+
+// templateFuncs returns the shared template helpers plus "t", which
+// translates a message key into the configured locale, and "lang"
+func (s *Server) templateFuncs() template.FuncMap {
+	funcs := maps.Clone(templateFuncs)
+	funcs["t"] = s.tr.T
+	funcs["lang"] = s.tr.Locale
+	return funcs
+}
+
+// render executes a UI template into the response
+func (s *Server) render(c *gin.Context, name string, data interface{}) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := s.templates[name].Execute(c.Writer, data); err != nil {
+		log.WithError(err).WithField("template", name).Error("Failed to render UI template")
+	}
+}
+
+// brand is the dashboard branding configured by the operator
+type brand struct {
+	Title  string
+	Logo   string
+	Footer string
+}
+
+func (s *Server) brand() brand {
+	b := brand{
+		Title:  s.config.UITitle,
+		Logo:   s.config.UILogo,
+		Footer: s.config.UIFooter,
+	}
+	if b.Title == "" {
+		b.Title = "DockWarden"
+	}
+	return b
+}
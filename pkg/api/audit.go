@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/audit"
+	"github.com/emon5122/dockwarden/internal/events"
+	"github.com/emon5122/dockwarden/internal/updater"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// recordAction audits an action triggered through the API or the dashboard,
+// including who triggered it, and sends a notification naming them
+func (s *Server) recordAction(c *gin.Context, action, target string, err error) {
+	entry := audit.Entry{
+		Time:      time.Now(),
+		Action:    action,
+		Target:    target,
+		Token:     "anonymous",
+		RemoteIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Result:    "ok",
+	}
+	if token := requestToken(c); token != nil {
+		entry.Token = token.Name
+	} else if strings.HasPrefix(c.FullPath(), "/ui/") {
+		entry.Token = "dashboard"
+	}
+	s.recordEntry(entry, err)
+}
+
+// recordEntry completes, stores, logs and notifies an audit entry
+func (s *Server) recordEntry(entry audit.Entry, err error) {
+	if err != nil {
+		entry.Result = "error"
+		entry.Error = err.Error()
+	}
+
+	s.audit.Record(entry)
+
+	log.WithFields(log.Fields{
+		"action":     entry.Action,
+		"target":     entry.Target,
+		"token":      entry.Token,
+		"remote_ip":  entry.RemoteIP,
+		"user_agent": entry.UserAgent,
+		"result":     entry.Result,
+	}).Info("API action")
+
+	if s.updater != nil {
+		msg := entry.Action + " triggered by " + entry.Identity()
+		if entry.Target != "" {
+			msg = entry.Action + " of " + entry.Target + " triggered by " + entry.Identity()
+		}
+		if entry.Error != "" {
+			msg += ", failed: " + entry.Error
+		}
+		s.updater.Events().Publish(events.Event{
+			Type:      events.TypeAPIAction,
+			Time:      entry.Time,
+			Container: entry.Target,
+			Message:   msg,
+		})
+	}
+
+	if s.notifier != nil {
+		go s.notifier.NotifyAPIAction(entry.Action, entry.Target, entry.Identity(), entry.Error)
+	}
+}
+
+// applyError summarizes the outcome of a plan apply for the audit log
+func applyError(results []updater.ApplyResult, err error) error {
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range results {
+		if !r.Applied {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d not applied", failed, len(results))
+	}
+	return nil
+}
+
+// auditFilter limits restricted tokens to the entries they triggered
+func auditFilter(token *apiToken) func(audit.Entry) bool {
+	if !token.Restricted() {
+		return nil
+	}
+	return func(e audit.Entry) bool { return e.Token == token.Name }
+}
+
+// handleAudit returns the most recent API-triggered actions
+func (s *Server) handleAudit(c *gin.Context) {
+	entries := s.audit.Recent(s.audit.Max(), auditFilter(requestToken(c)))
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// handleUIAudit returns HTMX fragment for recent API actions
+func (s *Server) handleUIAudit(c *gin.Context) {
+	s.render(c, "audit", s.audit.Recent(20, nil))
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control values of the web UI. Pages and fragments hold live
+// container state and are always revalidated; static files can only change
+// with a new version or a new DOCKWARDEN_UI_DIR file, so browsers keep them
+// for a day.
+const (
+	cacheDynamic = "no-cache"
+	cacheStatic  = "public, max-age=86400"
+)
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
+// gzipWriter compresses everything written to the response
+type gzipWriter struct {
+	gin.ResponseWriter
+	gz *gzip.Writer
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	// The length of the uncompressed body no longer applies
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	w.Header().Del("Content-Length")
+	return w.gz.Write(data)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// close flushes the compressed body. Responses that must not have a body
+// get none, not even an empty gzip stream.
+func (w *gzipWriter) close() {
+	if w.Status() == http.StatusNotModified || w.Status() == http.StatusNoContent {
+		w.gz.Reset(io.Discard)
+	}
+	_ = w.gz.Close()
+}
+
+// compress gzips responses for clients accepting it, which shrinks the
+// dashboard and its fragments to a fraction over slow links
+func compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Encoding")
+		if c.Request.Method == http.MethodHead || !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+
+		gz := gzipWriters.Get().(*gzip.Writer)
+		gz.Reset(c.Writer)
+		w := &gzipWriter{ResponseWriter: c.Writer, gz: gz}
+		c.Header("Content-Encoding", "gzip")
+		c.Writer = w
+		defer func() {
+			w.close()
+			gzipWriters.Put(gz)
+		}()
+		c.Next()
+	}
+}
+
+// cacheControl sets the Cache-Control header of the response
+func cacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", value)
+		c.Next()
+	}
+}
+
+// cacheFiles lets browsers cache the static files that exist; a missing
+// file stays uncached, so it shows up once it is added to DOCKWARDEN_UI_DIR
+func cacheFiles(files http.FileSystem) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if f, err := files.Open(c.Param("filepath")); err == nil {
+			f.Close()
+			c.Header("Cache-Control", cacheStatic)
+		}
+		c.Next()
+	}
+}
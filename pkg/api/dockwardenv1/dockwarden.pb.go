@@ -0,0 +1,819 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: dockwarden/v1/dockwarden.proto
+
+package dockwardenv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListContainersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContainersRequest) Reset() {
+	*x = ListContainersRequest{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContainersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContainersRequest) ProtoMessage() {}
+
+func (x *ListContainersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContainersRequest.ProtoReflect.Descriptor instead.
+func (*ListContainersRequest) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{0}
+}
+
+type ListContainersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Containers    []*Container           `protobuf:"bytes,1,rep,name=containers,proto3" json:"containers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContainersResponse) Reset() {
+	*x = ListContainersResponse{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContainersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContainersResponse) ProtoMessage() {}
+
+func (x *ListContainersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContainersResponse.ProtoReflect.Descriptor instead.
+func (*ListContainersResponse) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListContainersResponse) GetContainers() []*Container {
+	if x != nil {
+		return x.Containers
+	}
+	return nil
+}
+
+type Container struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name         string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Image        string                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	ImageId      string                 `protobuf:"bytes,4,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	State        string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Status       string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	HealthStatus string                 `protobuf:"bytes,7,opt,name=health_status,json=healthStatus,proto3" json:"health_status,omitempty"`
+	Labels       map[string]string      `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Created      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created,proto3" json:"created,omitempty"`
+	// Set when the updater leaves the container alone
+	SkipReason    *SkipReason `protobuf:"bytes,10,opt,name=skip_reason,json=skipReason,proto3" json:"skip_reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Container) Reset() {
+	*x = Container{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Container) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Container) ProtoMessage() {}
+
+func (x *Container) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Container.ProtoReflect.Descriptor instead.
+func (*Container) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Container) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Container) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Container) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Container) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *Container) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Container) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Container) GetHealthStatus() string {
+	if x != nil {
+		return x.HealthStatus
+	}
+	return ""
+}
+
+func (x *Container) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Container) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *Container) GetSkipReason() *SkipReason {
+	if x != nil {
+		return x.SkipReason
+	}
+	return nil
+}
+
+type SkipReason struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkipReason) Reset() {
+	*x = SkipReason{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkipReason) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkipReason) ProtoMessage() {}
+
+func (x *SkipReason) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkipReason.ProtoReflect.Descriptor instead.
+func (*SkipReason) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SkipReason) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *SkipReason) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetPlanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPlanRequest) Reset() {
+	*x = GetPlanRequest{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPlanRequest) ProtoMessage() {}
+
+func (x *GetPlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPlanRequest.ProtoReflect.Descriptor instead.
+func (*GetPlanRequest) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{4}
+}
+
+type Plan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GeneratedAt   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+	MonitorOnly   bool                   `protobuf:"varint,2,opt,name=monitor_only,json=monitorOnly,proto3" json:"monitor_only,omitempty"`
+	Items         []*PlanItem            `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Plan) Reset() {
+	*x = Plan{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Plan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Plan) ProtoMessage() {}
+
+func (x *Plan) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Plan.ProtoReflect.Descriptor instead.
+func (*Plan) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Plan) GetGeneratedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return nil
+}
+
+func (x *Plan) GetMonitorOnly() bool {
+	if x != nil {
+		return x.MonitorOnly
+	}
+	return false
+}
+
+func (x *Plan) GetItems() []*PlanItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type PlanItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContainerId   string                 `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	ContainerName string                 `protobuf:"bytes,2,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	Image         string                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	// update, retag, up_to_date, skip or unknown
+	Action        string      `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	TargetImage   string      `protobuf:"bytes,5,opt,name=target_image,json=targetImage,proto3" json:"target_image,omitempty"`
+	LocalDigest   string      `protobuf:"bytes,6,opt,name=local_digest,json=localDigest,proto3" json:"local_digest,omitempty"`
+	RemoteDigest  string      `protobuf:"bytes,7,opt,name=remote_digest,json=remoteDigest,proto3" json:"remote_digest,omitempty"`
+	SkipReason    *SkipReason `protobuf:"bytes,8,opt,name=skip_reason,json=skipReason,proto3" json:"skip_reason,omitempty"`
+	Error         string      `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanItem) Reset() {
+	*x = PlanItem{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanItem) ProtoMessage() {}
+
+func (x *PlanItem) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanItem.ProtoReflect.Descriptor instead.
+func (*PlanItem) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PlanItem) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *PlanItem) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *PlanItem) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *PlanItem) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *PlanItem) GetTargetImage() string {
+	if x != nil {
+		return x.TargetImage
+	}
+	return ""
+}
+
+func (x *PlanItem) GetLocalDigest() string {
+	if x != nil {
+		return x.LocalDigest
+	}
+	return ""
+}
+
+func (x *PlanItem) GetRemoteDigest() string {
+	if x != nil {
+		return x.RemoteDigest
+	}
+	return ""
+}
+
+func (x *PlanItem) GetSkipReason() *SkipReason {
+	if x != nil {
+		return x.SkipReason
+	}
+	return nil
+}
+
+func (x *PlanItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type TriggerUpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerUpdateRequest) Reset() {
+	*x = TriggerUpdateRequest{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerUpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerUpdateRequest) ProtoMessage() {}
+
+func (x *TriggerUpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerUpdateRequest.ProtoReflect.Descriptor instead.
+func (*TriggerUpdateRequest) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{7}
+}
+
+type TriggerUpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerUpdateResponse) Reset() {
+	*x = TriggerUpdateResponse{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerUpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerUpdateResponse) ProtoMessage() {}
+
+func (x *TriggerUpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerUpdateResponse.ProtoReflect.Descriptor instead.
+func (*TriggerUpdateResponse) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TriggerUpdateResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type StreamEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{9}
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// cycle_start, cycle_end, cycle_deferred, container_updated,
+	// container_update_failed or api_action
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	CycleId       string                 `protobuf:"bytes,3,opt,name=cycle_id,json=cycleId,proto3" json:"cycle_id,omitempty"`
+	Container     string                 `protobuf:"bytes,4,opt,name=container,proto3" json:"container,omitempty"`
+	Image         string                 `protobuf:"bytes,5,opt,name=image,proto3" json:"image,omitempty"`
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_dockwarden_v1_dockwarden_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_dockwarden_v1_dockwarden_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Event) GetCycleId() string {
+	if x != nil {
+		return x.CycleId
+	}
+	return ""
+}
+
+func (x *Event) GetContainer() string {
+	if x != nil {
+		return x.Container
+	}
+	return ""
+}
+
+func (x *Event) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Event) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_dockwarden_v1_dockwarden_proto protoreflect.FileDescriptor
+
+const file_dockwarden_v1_dockwarden_proto_rawDesc = "" +
+	"\n" +
+	"\x1edockwarden/v1/dockwarden.proto\x12\rdockwarden.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x17\n" +
+	"\x15ListContainersRequest\"R\n" +
+	"\x16ListContainersResponse\x128\n" +
+	"\n" +
+	"containers\x18\x01 \x03(\v2\x18.dockwarden.v1.ContainerR\n" +
+	"containers\"\x9e\x03\n" +
+	"\tContainer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05image\x18\x03 \x01(\tR\x05image\x12\x19\n" +
+	"\bimage_id\x18\x04 \x01(\tR\aimageId\x12\x14\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12#\n" +
+	"\rhealth_status\x18\a \x01(\tR\fhealthStatus\x12<\n" +
+	"\x06labels\x18\b \x03(\v2$.dockwarden.v1.Container.LabelsEntryR\x06labels\x124\n" +
+	"\acreated\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x12:\n" +
+	"\vskip_reason\x18\n" +
+	" \x01(\v2\x19.dockwarden.v1.SkipReasonR\n" +
+	"skipReason\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\":\n" +
+	"\n" +
+	"SkipReason\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x10\n" +
+	"\x0eGetPlanRequest\"\x97\x01\n" +
+	"\x04Plan\x12=\n" +
+	"\fgenerated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vgeneratedAt\x12!\n" +
+	"\fmonitor_only\x18\x02 \x01(\bR\vmonitorOnly\x12-\n" +
+	"\x05items\x18\x03 \x03(\v2\x17.dockwarden.v1.PlanItemR\x05items\"\xbf\x02\n" +
+	"\bPlanItem\x12!\n" +
+	"\fcontainer_id\x18\x01 \x01(\tR\vcontainerId\x12%\n" +
+	"\x0econtainer_name\x18\x02 \x01(\tR\rcontainerName\x12\x14\n" +
+	"\x05image\x18\x03 \x01(\tR\x05image\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12!\n" +
+	"\ftarget_image\x18\x05 \x01(\tR\vtargetImage\x12!\n" +
+	"\flocal_digest\x18\x06 \x01(\tR\vlocalDigest\x12#\n" +
+	"\rremote_digest\x18\a \x01(\tR\fremoteDigest\x12:\n" +
+	"\vskip_reason\x18\b \x01(\v2\x19.dockwarden.v1.SkipReasonR\n" +
+	"skipReason\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\"\x16\n" +
+	"\x14TriggerUpdateRequest\"1\n" +
+	"\x15TriggerUpdateResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x15\n" +
+	"\x13StreamEventsRequest\"\xb4\x01\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x19\n" +
+	"\bcycle_id\x18\x03 \x01(\tR\acycleId\x12\x1c\n" +
+	"\tcontainer\x18\x04 \x01(\tR\tcontainer\x12\x14\n" +
+	"\x05image\x18\x05 \x01(\tR\x05image\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage2\xd2\x02\n" +
+	"\n" +
+	"DockWarden\x12]\n" +
+	"\x0eListContainers\x12$.dockwarden.v1.ListContainersRequest\x1a%.dockwarden.v1.ListContainersResponse\x12=\n" +
+	"\aGetPlan\x12\x1d.dockwarden.v1.GetPlanRequest\x1a\x13.dockwarden.v1.Plan\x12Z\n" +
+	"\rTriggerUpdate\x12#.dockwarden.v1.TriggerUpdateRequest\x1a$.dockwarden.v1.TriggerUpdateResponse\x12J\n" +
+	"\fStreamEvents\x12\".dockwarden.v1.StreamEventsRequest\x1a\x14.dockwarden.v1.Event0\x01BBZ@github.com/emon5122/dockwarden/pkg/api/dockwardenv1;dockwardenv1b\x06proto3"
+
+var (
+	file_dockwarden_v1_dockwarden_proto_rawDescOnce sync.Once
+	file_dockwarden_v1_dockwarden_proto_rawDescData []byte
+)
+
+func file_dockwarden_v1_dockwarden_proto_rawDescGZIP() []byte {
+	file_dockwarden_v1_dockwarden_proto_rawDescOnce.Do(func() {
+		file_dockwarden_v1_dockwarden_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dockwarden_v1_dockwarden_proto_rawDesc), len(file_dockwarden_v1_dockwarden_proto_rawDesc)))
+	})
+	return file_dockwarden_v1_dockwarden_proto_rawDescData
+}
+
+var file_dockwarden_v1_dockwarden_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_dockwarden_v1_dockwarden_proto_goTypes = []any{
+	(*ListContainersRequest)(nil),  // 0: dockwarden.v1.ListContainersRequest
+	(*ListContainersResponse)(nil), // 1: dockwarden.v1.ListContainersResponse
+	(*Container)(nil),              // 2: dockwarden.v1.Container
+	(*SkipReason)(nil),             // 3: dockwarden.v1.SkipReason
+	(*GetPlanRequest)(nil),         // 4: dockwarden.v1.GetPlanRequest
+	(*Plan)(nil),                   // 5: dockwarden.v1.Plan
+	(*PlanItem)(nil),               // 6: dockwarden.v1.PlanItem
+	(*TriggerUpdateRequest)(nil),   // 7: dockwarden.v1.TriggerUpdateRequest
+	(*TriggerUpdateResponse)(nil),  // 8: dockwarden.v1.TriggerUpdateResponse
+	(*StreamEventsRequest)(nil),    // 9: dockwarden.v1.StreamEventsRequest
+	(*Event)(nil),                  // 10: dockwarden.v1.Event
+	nil,                            // 11: dockwarden.v1.Container.LabelsEntry
+	(*timestamppb.Timestamp)(nil),  // 12: google.protobuf.Timestamp
+}
+var file_dockwarden_v1_dockwarden_proto_depIdxs = []int32{
+	2,  // 0: dockwarden.v1.ListContainersResponse.containers:type_name -> dockwarden.v1.Container
+	11, // 1: dockwarden.v1.Container.labels:type_name -> dockwarden.v1.Container.LabelsEntry
+	12, // 2: dockwarden.v1.Container.created:type_name -> google.protobuf.Timestamp
+	3,  // 3: dockwarden.v1.Container.skip_reason:type_name -> dockwarden.v1.SkipReason
+	12, // 4: dockwarden.v1.Plan.generated_at:type_name -> google.protobuf.Timestamp
+	6,  // 5: dockwarden.v1.Plan.items:type_name -> dockwarden.v1.PlanItem
+	3,  // 6: dockwarden.v1.PlanItem.skip_reason:type_name -> dockwarden.v1.SkipReason
+	12, // 7: dockwarden.v1.Event.time:type_name -> google.protobuf.Timestamp
+	0,  // 8: dockwarden.v1.DockWarden.ListContainers:input_type -> dockwarden.v1.ListContainersRequest
+	4,  // 9: dockwarden.v1.DockWarden.GetPlan:input_type -> dockwarden.v1.GetPlanRequest
+	7,  // 10: dockwarden.v1.DockWarden.TriggerUpdate:input_type -> dockwarden.v1.TriggerUpdateRequest
+	9,  // 11: dockwarden.v1.DockWarden.StreamEvents:input_type -> dockwarden.v1.StreamEventsRequest
+	1,  // 12: dockwarden.v1.DockWarden.ListContainers:output_type -> dockwarden.v1.ListContainersResponse
+	5,  // 13: dockwarden.v1.DockWarden.GetPlan:output_type -> dockwarden.v1.Plan
+	8,  // 14: dockwarden.v1.DockWarden.TriggerUpdate:output_type -> dockwarden.v1.TriggerUpdateResponse
+	10, // 15: dockwarden.v1.DockWarden.StreamEvents:output_type -> dockwarden.v1.Event
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_dockwarden_v1_dockwarden_proto_init() }
+func file_dockwarden_v1_dockwarden_proto_init() {
+	if File_dockwarden_v1_dockwarden_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dockwarden_v1_dockwarden_proto_rawDesc), len(file_dockwarden_v1_dockwarden_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_dockwarden_v1_dockwarden_proto_goTypes,
+		DependencyIndexes: file_dockwarden_v1_dockwarden_proto_depIdxs,
+		MessageInfos:      file_dockwarden_v1_dockwarden_proto_msgTypes,
+	}.Build()
+	File_dockwarden_v1_dockwarden_proto = out.File
+	file_dockwarden_v1_dockwarden_proto_goTypes = nil
+	file_dockwarden_v1_dockwarden_proto_depIdxs = nil
+}
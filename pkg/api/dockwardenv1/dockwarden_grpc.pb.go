@@ -0,0 +1,255 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: dockwarden/v1/dockwarden.proto
+
+package dockwardenv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DockWarden_ListContainers_FullMethodName = "/dockwarden.v1.DockWarden/ListContainers"
+	DockWarden_GetPlan_FullMethodName        = "/dockwarden.v1.DockWarden/GetPlan"
+	DockWarden_TriggerUpdate_FullMethodName  = "/dockwarden.v1.DockWarden/TriggerUpdate"
+	DockWarden_StreamEvents_FullMethodName   = "/dockwarden.v1.DockWarden/StreamEvents"
+)
+
+// DockWardenClient is the client API for DockWarden service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DockWarden exposes the core operations of the REST API for programmatic
+// integrations. Authentication uses the same tokens as the REST API, sent as
+// "authorization: Bearer <token>" metadata.
+type DockWardenClient interface {
+	// ListContainers returns the containers on the host with their skip reasons
+	ListContainers(ctx context.Context, in *ListContainersRequest, opts ...grpc.CallOption) (*ListContainersResponse, error)
+	// GetPlan returns what the next update cycle would do, without pulling
+	GetPlan(ctx context.Context, in *GetPlanRequest, opts ...grpc.CallOption) (*Plan, error)
+	// TriggerUpdate starts an update cycle in the background
+	TriggerUpdate(ctx context.Context, in *TriggerUpdateRequest, opts ...grpc.CallOption) (*TriggerUpdateResponse, error)
+	// StreamEvents streams update cycle and container events as they happen
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type dockWardenClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDockWardenClient(cc grpc.ClientConnInterface) DockWardenClient {
+	return &dockWardenClient{cc}
+}
+
+func (c *dockWardenClient) ListContainers(ctx context.Context, in *ListContainersRequest, opts ...grpc.CallOption) (*ListContainersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListContainersResponse)
+	err := c.cc.Invoke(ctx, DockWarden_ListContainers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dockWardenClient) GetPlan(ctx context.Context, in *GetPlanRequest, opts ...grpc.CallOption) (*Plan, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Plan)
+	err := c.cc.Invoke(ctx, DockWarden_GetPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dockWardenClient) TriggerUpdate(ctx context.Context, in *TriggerUpdateRequest, opts ...grpc.CallOption) (*TriggerUpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerUpdateResponse)
+	err := c.cc.Invoke(ctx, DockWarden_TriggerUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dockWardenClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DockWarden_ServiceDesc.Streams[0], DockWarden_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DockWarden_StreamEventsClient = grpc.ServerStreamingClient[Event]
+
+// DockWardenServer is the server API for DockWarden service.
+// All implementations must embed UnimplementedDockWardenServer
+// for forward compatibility.
+//
+// DockWarden exposes the core operations of the REST API for programmatic
+// integrations. Authentication uses the same tokens as the REST API, sent as
+// "authorization: Bearer <token>" metadata.
+type DockWardenServer interface {
+	// ListContainers returns the containers on the host with their skip reasons
+	ListContainers(context.Context, *ListContainersRequest) (*ListContainersResponse, error)
+	// GetPlan returns what the next update cycle would do, without pulling
+	GetPlan(context.Context, *GetPlanRequest) (*Plan, error)
+	// TriggerUpdate starts an update cycle in the background
+	TriggerUpdate(context.Context, *TriggerUpdateRequest) (*TriggerUpdateResponse, error)
+	// StreamEvents streams update cycle and container events as they happen
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedDockWardenServer()
+}
+
+// UnimplementedDockWardenServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDockWardenServer struct{}
+
+func (UnimplementedDockWardenServer) ListContainers(context.Context, *ListContainersRequest) (*ListContainersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListContainers not implemented")
+}
+func (UnimplementedDockWardenServer) GetPlan(context.Context, *GetPlanRequest) (*Plan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlan not implemented")
+}
+func (UnimplementedDockWardenServer) TriggerUpdate(context.Context, *TriggerUpdateRequest) (*TriggerUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerUpdate not implemented")
+}
+func (UnimplementedDockWardenServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedDockWardenServer) mustEmbedUnimplementedDockWardenServer() {}
+func (UnimplementedDockWardenServer) testEmbeddedByValue()                    {}
+
+// UnsafeDockWardenServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DockWardenServer will
+// result in compilation errors.
+type UnsafeDockWardenServer interface {
+	mustEmbedUnimplementedDockWardenServer()
+}
+
+func RegisterDockWardenServer(s grpc.ServiceRegistrar, srv DockWardenServer) {
+	// If the following call pancis, it indicates UnimplementedDockWardenServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DockWarden_ServiceDesc, srv)
+}
+
+func _DockWarden_ListContainers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListContainersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DockWardenServer).ListContainers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DockWarden_ListContainers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DockWardenServer).ListContainers(ctx, req.(*ListContainersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DockWarden_GetPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DockWardenServer).GetPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DockWarden_GetPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DockWardenServer).GetPlan(ctx, req.(*GetPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DockWarden_TriggerUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DockWardenServer).TriggerUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DockWarden_TriggerUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DockWardenServer).TriggerUpdate(ctx, req.(*TriggerUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DockWarden_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DockWardenServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DockWarden_StreamEventsServer = grpc.ServerStreamingServer[Event]
+
+// DockWarden_ServiceDesc is the grpc.ServiceDesc for DockWarden service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DockWarden_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dockwarden.v1.DockWarden",
+	HandlerType: (*DockWardenServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListContainers",
+			Handler:    _DockWarden_ListContainers_Handler,
+		},
+		{
+			MethodName: "GetPlan",
+			Handler:    _DockWarden_GetPlan_Handler,
+		},
+		{
+			MethodName: "TriggerUpdate",
+			Handler:    _DockWarden_TriggerUpdate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _DockWarden_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dockwarden/v1/dockwarden.proto",
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/history"
+	"github.com/gin-gonic/gin"
+)
+
+// Export formats selected with the format query parameter
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// exportFormat returns the requested format, answering 400 and returning
+// false for unknown ones
+func exportFormat(c *gin.Context) (string, bool) {
+	switch format := c.DefaultQuery("format", formatJSON); format {
+	case formatJSON, formatCSV:
+		return format, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown format %q, expected json or csv", format)})
+		return "", false
+	}
+}
+
+// containerColumns are the CSV columns of the container inventory
+var containerColumns = []string{
+	"name", "id", "image", "image_id", "state", "status", "health", "created",
+	"ports", "skip_reason", "update_available_since", "eol", "owner", "notes",
+}
+
+// containerRows renders container views as CSV rows
+func containerRows(views []containerView) [][]string {
+	rows := make([][]string, 0, len(views))
+	for _, v := range views {
+		skipReason := ""
+		if v.SkipReason != nil {
+			skipReason = v.SkipReason.Code
+		}
+		eol := ""
+		if v.EOL != nil {
+			eol = v.EOL.Product + " " + v.EOL.Cycle
+		}
+		rows = append(rows, []string{
+			v.Name,
+			v.ID,
+			v.Image,
+			v.ImageID,
+			v.State,
+			v.Status,
+			v.HealthStatus,
+			csvTime(v.Created),
+			strings.Join(portList(v.Ports), " "),
+			skipReason,
+			csvTimePtr(v.UpdateAvailableSince),
+			eol,
+			v.Owner,
+			v.Notes,
+		})
+	}
+	return rows
+}
+
+// historyColumns are the CSV columns of the update history
+var historyColumns = []string{
+	"id", "time", "container", "identity", "image", "old_image_id", "new_image_id", "digest", "sbom", "image_created",
+}
+
+// historyRows renders history records as CSV rows
+func historyRows(records []history.Record) [][]string {
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, []string{
+			r.ID,
+			csvTime(r.Time),
+			r.Container,
+			r.Identity,
+			r.Image,
+			r.OldImageID,
+			r.NewImageID,
+			r.Digest,
+			r.SBOM,
+			csvTime(r.ImageCreated),
+		})
+	}
+	return rows
+}
+
+// writeCSV writes a CSV attachment with a header row
+func writeCSV(c *gin.Context, name string, columns []string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", exportDisposition(name, formatCSV))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(columns)
+	_ = w.WriteAll(rows)
+}
+
+// exportDisposition names a download after the export and the current date,
+// e.g. dockwarden-containers-2026-10-17.csv
+func exportDisposition(name, format string) string {
+	return fmt.Sprintf(`attachment; filename="dockwarden-%s-%s.%s"`, name, time.Now().Format("2006-01-02"), format)
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return csvTime(*t)
+}
+
+// handleUIExportContainers downloads the container inventory shown on the
+// dashboard as CSV or JSON
+func (s *Server) handleUIExportContainers(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	containers, err := s.client.ListContainers(ctx, docker.ListOptions{
+		All:           true,
+		IncludeHealth: true,
+	})
+	if err != nil {
+		c.String(http.StatusInternalServerError, "failed to list containers: %v", err)
+		return
+	}
+
+	views := s.containerViews(ctx, containers)
+	if format == formatCSV {
+		writeCSV(c, "containers", containerColumns, containerRows(views))
+		return
+	}
+	c.Header("Content-Disposition", exportDisposition("containers", formatJSON))
+	c.JSON(http.StatusOK, gin.H{
+		"containers": views,
+		"count":      len(views),
+	})
+}
+
+// handleUIExportHistory downloads the most recent deployed updates as CSV
+// or JSON, like /v1/history
+func (s *Server) handleUIExportHistory(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if s.updater == nil {
+		c.String(http.StatusServiceUnavailable, "updater not available")
+		return
+	}
+
+	limit := historyLimit
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	records := s.updater.History().Recent(limit, nil)
+	if format == formatCSV {
+		writeCSV(c, "history", historyColumns, historyRows(records))
+		return
+	}
+	c.Header("Content-Disposition", exportDisposition("history", formatJSON))
+	c.JSON(http.StatusOK, gin.H{
+		"records": records,
+		"count":   len(records),
+	})
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/audit"
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/events"
+	"github.com/emon5122/dockwarden/internal/updater"
+	pb "github.com/emon5122/dockwarden/pkg/api/dockwardenv1"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcService implements the DockWarden gRPC service on top of the server
+type grpcService struct {
+	pb.UnimplementedDockWardenServer
+	s *Server
+}
+
+type grpcTokenKey struct{}
+
+// StartGRPC serves the gRPC API on the configured port
+func (s *Server) StartGRPC() error {
+	addr := fmt.Sprintf(":%d", s.config.GRPCPort)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(s.grpcUnaryAuth),
+		grpc.StreamInterceptor(s.grpcStreamAuth),
+	)
+	pb.RegisterDockWardenServer(server, &grpcService{s: s})
+
+	log.WithField("addr", addr).Info("Starting gRPC server")
+	return server.Serve(listener)
+}
+
+// grpcAuthenticate checks the bearer token in the request metadata, the same
+// way the REST middleware does, and stores it in the context
+func (s *Server) grpcAuthenticate(ctx context.Context) (context.Context, error) {
+	if len(s.tokens) == 0 {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		secret, ok := strings.CutPrefix(value, "Bearer ")
+		if !ok {
+			continue
+		}
+		if token := lookupToken(s.tokens, secret); token != nil {
+			return context.WithValue(ctx, grpcTokenKey{}, token), nil
+		}
+	}
+	return nil, status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+func (s *Server) grpcUnaryAuth(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.grpcAuthenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) grpcStreamAuth(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.grpcAuthenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream carries the authenticated token in the stream context
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authenticatedStream) Context() context.Context {
+	return a.ctx
+}
+
+// grpcToken returns the token that authenticated the call, if any
+func grpcToken(ctx context.Context) *apiToken {
+	token, _ := ctx.Value(grpcTokenKey{}).(*apiToken)
+	return token
+}
+
+// ListContainers implements pb.DockWardenServer
+func (g *grpcService) ListContainers(ctx context.Context, _ *pb.ListContainersRequest) (*pb.ListContainersResponse, error) {
+	containers, err := g.s.client.ListContainers(ctx, docker.ListOptions{
+		All:           g.s.config.IncludeStopped,
+		IncludeHealth: true,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if token := grpcToken(ctx); token.Restricted() {
+		containers = slices.DeleteFunc(containers, func(ctr docker.Container) bool { return !token.Allows(ctr) })
+	}
+
+	resp := &pb.ListContainersResponse{}
+	for _, view := range g.s.containerViews(ctx, containers) {
+		resp.Containers = append(resp.Containers, &pb.Container{
+			Id:           view.ID,
+			Name:         view.Name,
+			Image:        view.Image,
+			ImageId:      view.ImageID,
+			State:        view.State,
+			Status:       view.Status,
+			HealthStatus: view.HealthStatus,
+			Labels:       view.Labels,
+			Created:      timestamppb.New(view.Created),
+			SkipReason:   skipReasonProto(view.SkipReason),
+		})
+	}
+	return resp, nil
+}
+
+// GetPlan implements pb.DockWardenServer
+func (g *grpcService) GetPlan(ctx context.Context, _ *pb.GetPlanRequest) (*pb.Plan, error) {
+	if g.s.updater == nil {
+		return nil, status.Error(codes.Unavailable, "updater not available")
+	}
+
+	plan, err := g.s.updater.Plan(ctx, grpcToken(ctx).Filter())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.Plan{
+		GeneratedAt: timestamppb.New(plan.GeneratedAt),
+		MonitorOnly: plan.MonitorOnly,
+	}
+	for _, item := range plan.Items {
+		resp.Items = append(resp.Items, &pb.PlanItem{
+			ContainerId:   item.ContainerID,
+			ContainerName: item.ContainerName,
+			Image:         item.Image,
+			Action:        item.Action,
+			TargetImage:   item.TargetImage,
+			LocalDigest:   item.LocalDigest,
+			RemoteDigest:  item.RemoteDigest,
+			SkipReason:    skipReasonProto(item.SkipReason),
+			Error:         item.Error,
+		})
+	}
+	return resp, nil
+}
+
+// TriggerUpdate implements pb.DockWardenServer
+func (g *grpcService) TriggerUpdate(ctx context.Context, _ *pb.TriggerUpdateRequest) (*pb.TriggerUpdateResponse, error) {
+	if g.s.updater == nil {
+		return nil, status.Error(codes.Unavailable, "updater not available")
+	}
+	if !g.s.updater.IsLeader() {
+		return nil, status.Error(codes.Unavailable, updater.ErrStandby.Error())
+	}
+
+	token := grpcToken(ctx)
+	match := token.Filter()
+	go func() {
+		if err := g.s.updater.RunMatching(match); err != nil {
+			log.WithError(err).Error("Manual update failed")
+		}
+	}()
+
+	g.s.recordEntry(grpcAuditEntry(ctx, token, "update"), nil)
+	return &pb.TriggerUpdateResponse{Message: "update triggered"}, nil
+}
+
+// StreamEvents implements pb.DockWardenServer
+func (g *grpcService) StreamEvents(_ *pb.StreamEventsRequest, stream grpc.ServerStreamingServer[pb.Event]) error {
+	if g.s.updater == nil {
+		return status.Error(codes.Unavailable, "updater not available")
+	}
+
+	ch, cancel := g.s.updater.Events().Subscribe(64)
+	defer cancel()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-ch:
+			if err := stream.Send(eventProto(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// grpcAuditEntry builds the audit entry for a gRPC call
+func grpcAuditEntry(ctx context.Context, token *apiToken, action string) audit.Entry {
+	entry := audit.Entry{
+		Time:   time.Now(),
+		Action: action,
+		Token:  "anonymous",
+		Result: "ok",
+	}
+	if token != nil {
+		entry.Token = token.Name
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			entry.RemoteIP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			entry.UserAgent = ua[0]
+		}
+	}
+	return entry
+}
+
+func skipReasonProto(reason *updater.SkipReason) *pb.SkipReason {
+	if reason == nil {
+		return nil
+	}
+	return &pb.SkipReason{Code: reason.Code, Message: reason.Message}
+}
+
+func eventProto(e events.Event) *pb.Event {
+	return &pb.Event{
+		Type:      e.Type,
+		Time:      timestamppb.New(e.Time),
+		CycleId:   e.CycleID,
+		Container: e.Container,
+		Image:     e.Image,
+		Message:   e.Message,
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/history"
+	"github.com/gin-gonic/gin"
+)
+
+// historyLimit is the default number of records returned by /v1/history
+const historyLimit = 100
+
+// handleHistory returns the most recent deployed updates
+func (s *Server) handleHistory(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	limit := historyLimit
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		limit = v
+	}
+
+	match, err := s.historyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if container := c.Query("container"); container != "" {
+		allowed := match
+		match = func(r history.Record) bool {
+			return (r.Key() == container || r.Container == container) && (allowed == nil || allowed(r))
+		}
+	}
+
+	records := s.updater.History().Recent(limit, match)
+	if format == formatCSV {
+		writeCSV(c, "history", historyColumns, historyRows(records))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"records": records,
+		"count":   len(records),
+	})
+}
+
+// handleHistorySBOM returns the SBOM stored with a history record
+func (s *Server) handleHistorySBOM(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+
+	store := s.updater.History()
+	record, err := store.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "history record not found"})
+		return
+	}
+
+	match, err := s.historyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if match != nil && !match(record) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "history record not found"})
+		return
+	}
+
+	sbom, err := store.SBOM(record.ID)
+	if errors.Is(err, history.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no SBOM stored for this update"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("X-SBOM-Format", record.SBOM)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", sbom)
+}
+
+// historyFilter limits restricted tokens to the history of the containers
+// they may see
+func (s *Server) historyFilter(c *gin.Context) (func(history.Record) bool, error) {
+	token := requestToken(c)
+	if !token.Restricted() {
+		return nil, nil
+	}
+
+	containers, err := s.client.ListContainers(context.Background(), docker.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool)
+	for _, ctr := range containers {
+		// Records written before identities were kept only carry the name
+		if token.Allows(ctr) {
+			allowed[ctr.Identity()] = true
+			allowed[ctr.Name] = true
+		}
+	}
+	return func(r history.Record) bool { return allowed[r.Key()] }, nil
+}
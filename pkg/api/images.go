@@ -0,0 +1,175 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/updater"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// loadRequest names an archive in the image load directory
+type loadRequest struct {
+	Path string `json:"path"`
+}
+
+// handleLoadImages loads a docker-save archive, sent as the request body or
+// named by a path in the image load directory, and triggers an update cycle
+// for the containers using the loaded images. Loading affects every
+// container on the host, so it requires an unrestricted token and offline
+// mode.
+func (s *Server) handleLoadImages(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+	if token := requestToken(c); token == nil || token.Restricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "loading images requires an unrestricted API token"})
+		return
+	}
+	if !s.config.Offline {
+		c.JSON(http.StatusConflict, gin.H{"error": "loading images requires offline mode (DOCKWARDEN_OFFLINE=true)"})
+		return
+	}
+
+	archive, source, err := s.loadArchive(c)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, os.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer archive.Close()
+
+	images, err := s.updater.LoadImages(c.Request.Context(), archive)
+	s.recordAction(c, "load_images", source, err)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	go func() {
+		if err := s.updater.RunMatching(updater.UsesImages(images)); err != nil {
+			log.WithError(err).Error("Update cycle for loaded images failed")
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "images loaded, update triggered",
+		"images":  images,
+	})
+}
+
+// loadArchive opens the archive of a load request and describes where it
+// came from for the audit log
+func (s *Server) loadArchive(c *gin.Context) (io.ReadCloser, string, error) {
+	if !strings.HasPrefix(c.ContentType(), "application/json") {
+		return c.Request.Body, "upload", nil
+	}
+
+	var req loadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, "", err
+	}
+	if req.Path == "" {
+		return nil, "", errors.New("no path given")
+	}
+	if s.config.ImageLoadDir == "" {
+		return nil, "", errors.New("loading by path requires an image load directory (DOCKWARDEN_IMAGE_LOAD_DIR)")
+	}
+
+	// The root keeps paths, including symlinks, from leaving the directory
+	f, err := os.OpenInRoot(s.config.ImageLoadDir, strings.TrimPrefix(req.Path, "/"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open %s: %w", req.Path, err)
+	}
+	return f, req.Path, nil
+}
+
+// handleImages lists the local images with the containers using them. The
+// list spans every container on the host, so restricted tokens can't use
+// it.
+func (s *Server) handleImages(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+	if token := requestToken(c); token != nil && token.Restricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "listing images requires an unrestricted API token"})
+		return
+	}
+
+	images, err := s.updater.Images(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"images": images,
+		"count":  len(images),
+	})
+}
+
+// handlePruneImages removes the images no container uses, keeping the
+// images retained for rollbacks. Restricted tokens can't prune.
+func (s *Server) handlePruneImages(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+	if token := requestToken(c); token != nil && token.Restricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "removing images requires an unrestricted API token"})
+		return
+	}
+
+	result, err := s.updater.PruneImages(c.Request.Context())
+	s.recordAction(c, "prune_images", "", err)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleUIImages returns HTMX fragment for the local images
+func (s *Server) handleUIImages(c *gin.Context) {
+	if s.updater == nil {
+		c.String(http.StatusOK, `<div class="text-red-500 p-6">Updater not available</div>`)
+		return
+	}
+
+	images, err := s.updater.Images(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, `<div class="text-red-500 p-6">Error listing images: %s</div>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+
+	s.render(c, "images", images)
+}
+
+// handleUIPruneImages removes unused images from the dashboard
+func (s *Server) handleUIPruneImages(c *gin.Context) {
+	if s.updater == nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Updater not available</span>`)
+		return
+	}
+
+	result, err := s.updater.PruneImages(c.Request.Context())
+	s.recordAction(c, "prune_images", "", err)
+	if err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: %s</span>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+	c.String(http.StatusOK, `<span class="text-green-500">✓ %s</span>`,
+		s.tr.T("ui.images_pruned", len(result.Removed), humanBytes(result.Reclaimed)))
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/history"
+	"github.com/gin-gonic/gin"
+)
+
+// imageStatsJSON is an image repository's update statistics as served by
+// /v1/stats/images, durations in seconds
+type imageStatsJSON struct {
+	Repository             string    `json:"repository"`
+	Updates                int       `json:"updates"`
+	Containers             int       `json:"containers"`
+	FirstUpdate            time.Time `json:"first_update"`
+	LastUpdate             time.Time `json:"last_update"`
+	AverageIntervalSeconds int64     `json:"average_interval_seconds,omitempty"`
+	AverageBehindSeconds   int64     `json:"average_behind_seconds,omitempty"`
+}
+
+// imageStatsRow is an image repository in the dashboard chart
+type imageStatsRow struct {
+	history.ImageStats
+	// Percent is the bar width relative to the most updated repository
+	Percent int
+}
+
+// handleImageStats returns the update statistics of each image repository
+// found in the history
+func (s *Server) handleImageStats(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+
+	stats, err := s.imageStats(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	repos := make([]imageStatsJSON, 0, len(stats))
+	for _, st := range stats {
+		repos = append(repos, imageStatsJSON{
+			Repository:             st.Repository,
+			Updates:                st.Updates,
+			Containers:             st.Containers,
+			FirstUpdate:            st.FirstUpdate,
+			LastUpdate:             st.LastUpdate,
+			AverageIntervalSeconds: int64(st.AverageInterval.Seconds()),
+			AverageBehindSeconds:   int64(st.AverageBehind.Seconds()),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"repositories": repos,
+		"count":        len(repos),
+	})
+}
+
+// handleUIImageStats returns HTMX fragment for the updates per image chart
+func (s *Server) handleUIImageStats(c *gin.Context) {
+	var rows []imageStatsRow
+	if s.updater != nil {
+		stats, _ := s.imageStats(c)
+		for _, st := range stats {
+			// Sorted by updates, so the first repository is the largest
+			rows = append(rows, imageStatsRow{
+				ImageStats: st,
+				Percent:    st.Updates * 100 / stats[0].Updates,
+			})
+		}
+	}
+	s.render(c, "imagestats", rows)
+}
+
+// imageStats aggregates the history the request's token may see
+func (s *Server) imageStats(c *gin.Context) ([]history.ImageStats, error) {
+	match, err := s.historyFilter(c)
+	if err != nil {
+		return nil, err
+	}
+	return s.updater.History().ImageStats(match), nil
+}
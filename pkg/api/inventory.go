@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleNetworks lists the networks with the containers attached to them.
+// The list spans every container on the host, so restricted tokens can't
+// use it.
+func (s *Server) handleNetworks(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+	if token := requestToken(c); token != nil && token.Restricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "listing networks requires an unrestricted API token"})
+		return
+	}
+
+	networks, err := s.updater.Networks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"networks": networks,
+		"count":    len(networks),
+	})
+}
+
+// handleVolumes lists the volumes with the containers mounting them and
+// flags orphaned ones. The list spans every container on the host, so
+// restricted tokens can't use it.
+func (s *Server) handleVolumes(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+	if token := requestToken(c); token != nil && token.Restricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "listing volumes requires an unrestricted API token"})
+		return
+	}
+
+	volumes, err := s.updater.Volumes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	orphaned := 0
+	for _, v := range volumes {
+		if v.Orphaned {
+			orphaned++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"volumes":  volumes,
+		"count":    len(volumes),
+		"orphaned": orphaned,
+	})
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/gin-gonic/gin"
+)
+
+// labelsRequest holds label edits: a value sets the label, an empty value
+// removes it
+type labelsRequest struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// handleEditLabels recreates a container with edited dockwarden.* labels.
+// Restricted tokens may only edit their containers, and only so that the
+// container stays theirs. Labels carrying a command, an image or an
+// environment variable need an unrestricted token.
+func (s *Server) handleEditLabels(c *gin.Context) {
+	id := c.Param("id")
+
+	ctr, err := s.client.GetContainer(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "container not found"})
+		return
+	}
+
+	var req labelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	token := requestToken(c)
+	edited, status, err := checkLabelEdits(token, ctr, req.Labels)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+
+	newID, err := s.updater.EditLabels(c.Request.Context(), ctr, req.Labels, privilegedToken(token))
+	s.recordAction(c, "edit_labels", id, err)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "container recreated with edited labels",
+		"id":      id,
+		"new_id":  newID,
+		"labels":  editableLabels(edited.Labels),
+	})
+}
+
+// labelEditor is the data of the dashboard's label editor
+type labelEditor struct {
+	ID     string
+	Name   string
+	Labels string
+}
+
+// handleUILabelEditor returns HTMX fragment with a form editing the
+// dockwarden.* labels of a container, one key=value per line
+func (s *Server) handleUILabelEditor(c *gin.Context) {
+	ctr, err := s.client.GetContainer(context.Background(), c.Param("id"))
+	if err != nil {
+		c.String(http.StatusOK, `<div class="text-red-500 p-6">Failed: container not found</div>`)
+		return
+	}
+
+	labels := editableLabels(ctr.Labels)
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, labels[key])
+	}
+
+	s.render(c, "labels", labelEditor{ID: ctr.ID, Name: ctr.Name, Labels: b.String()})
+}
+
+// handleUIEditLabels applies the label editor: labels missing from the form
+// are removed, changed and new ones set
+func (s *Server) handleUIEditLabels(c *gin.Context) {
+	id := c.Param("id")
+
+	ctr, err := s.client.GetContainer(context.Background(), id)
+	if err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: container not found</span>`)
+		return
+	}
+	if s.updater == nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: updater not available</span>`)
+		return
+	}
+
+	wanted, err := parseLabelLines(c.PostForm("labels"))
+	if err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: %s</span>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+	edits := labelEdits(editableLabels(ctr.Labels), wanted)
+	if len(edits) == 0 {
+		c.String(http.StatusOK, `<span class="text-gray-400">%s</span>`, template.HTMLEscapeString(s.tr.T("ui.labels_unchanged")))
+		return
+	}
+	token := requestToken(c)
+	if _, _, err := checkLabelEdits(token, ctr, edits); err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: %s</span>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+
+	_, err = s.updater.EditLabels(c.Request.Context(), ctr, edits, privilegedToken(token))
+	s.recordAction(c, "edit_labels", id, err)
+	if err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: %s</span>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+	c.String(http.StatusOK, `<span class="text-green-500">✓ %s</span>`, template.HTMLEscapeString(s.tr.T("ui.labels_applied", ctr.Name)))
+}
+
+// checkLabelEdits validates edits of ctr's labels for the request's token
+// and returns the container as it is after the edits, or the status to
+// reject them with
+func checkLabelEdits(token *apiToken, ctr docker.Container, edits map[string]string) (docker.Container, int, error) {
+	if err := docker.ValidateLabelEdits(edits, privilegedToken(token)); err != nil {
+		if errors.Is(err, docker.ErrPrivilegedLabel) {
+			return ctr, http.StatusForbidden, err
+		}
+		return ctr, http.StatusBadRequest, err
+	}
+
+	edited := ctr
+	edited.Labels = docker.EditLabels(ctr.Labels, edits)
+	if token.Restricted() && (!token.Allows(ctr) || !token.Allows(edited)) {
+		return ctr, http.StatusForbidden, errors.New("token is not allowed to manage this container")
+	}
+	return edited, http.StatusOK, nil
+}
+
+// privilegedToken reports whether token may edit labels that carry a
+// command, an image or an environment variable: only an unrestricted token
+// can, so they can't be edited at all while authentication is disabled
+func privilegedToken(token *apiToken) bool {
+	return token != nil && !token.Restricted()
+}
+
+// editableLabels returns the dockwarden.* labels that can be edited
+func editableLabels(labels map[string]string) map[string]string {
+	editable := make(map[string]string)
+	for key, value := range labels {
+		if strings.HasPrefix(key, docker.LabelPrefix) && key != docker.ManagedLabel && key != docker.IdentityLabel {
+			editable[key] = value
+		}
+	}
+	return editable
+}
+
+// parseLabelLines parses one key=value label per line, ignoring blank lines
+// and # comments
+func parseLabelLines(text string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label %q: expected key=value", line)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// labelEdits returns the edits turning the current labels into the wanted
+// ones, with an empty value for labels to remove
+func labelEdits(current, wanted map[string]string) map[string]string {
+	edits := make(map[string]string)
+	for key, value := range wanted {
+		if current[key] != value {
+			edits[key] = value
+		}
+	}
+	for key := range current {
+		if _, ok := wanted[key]; !ok {
+			edits[key] = ""
+		}
+	}
+	return edits
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/health"
+	"github.com/emon5122/dockwarden/internal/updater"
+)
+
+// containerSeries holds the per-container metric values of one container
+type containerSeries struct {
+	name            string
+	image           string
+	updateAvailable bool
+	lastUpdated     int64
+	staleSeconds    float64
+	restartAttempts int
+}
+
+// containerMetrics renders per-container gauges labelled with the container
+// name and image. The number of containers is capped by
+// MetricsMaxContainers to keep the label cardinality bounded; containers
+// beyond the cap are counted in dockwarden_metrics_containers_dropped.
+func (s *Server) containerMetrics() string {
+	limit := s.config.MetricsMaxContainers
+	if limit <= 0 {
+		return ""
+	}
+
+	// Series are keyed by identity, so a container keeps its values across
+	// the recreates of its updates
+	series := make(map[string]*containerSeries)
+	get := func(identity, name, image string) *containerSeries {
+		if identity == "" {
+			identity = name
+		}
+		cs, ok := series[identity]
+		if !ok {
+			cs = &containerSeries{name: name}
+			series[identity] = cs
+		}
+		if image != "" {
+			cs.image = image
+		}
+		return cs
+	}
+
+	now := time.Now()
+	var statuses []updater.ContainerStatus
+	if s.updater != nil {
+		statuses = s.updater.ContainerStatuses()
+	}
+	for _, status := range statuses {
+		cs := get(status.Identity, status.Name, status.Image)
+		cs.updateAvailable = status.UpdateAvailable
+		cs.staleSeconds = status.Staleness(now).Seconds()
+		if !status.LastUpdated.IsZero() {
+			cs.lastUpdated = status.LastUpdated.Unix()
+		}
+	}
+
+	var restarts []health.RestartStatus
+	if s.watcher != nil {
+		restarts = s.watcher.RestartStatuses()
+	}
+	for _, restart := range restarts {
+		get(restart.Identity, restart.Name, restart.Image).restartAttempts = restart.Attempts
+	}
+
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dropped := 0
+	if len(keys) > limit {
+		dropped = len(keys) - limit
+		keys = keys[:limit]
+	}
+
+	var b strings.Builder
+	b.WriteString("\n# HELP dockwarden_container_update_available Whether a newer image is available for the container (1) or not (0)\n")
+	b.WriteString("# TYPE dockwarden_container_update_available gauge\n")
+	for _, key := range keys {
+		cs := series[key]
+		value := 0
+		if cs.updateAvailable {
+			value = 1
+		}
+		fmt.Fprintf(&b, "dockwarden_container_update_available{%s} %d\n", cs.labels(), value)
+	}
+
+	b.WriteString("\n# HELP dockwarden_container_stale_seconds How long a newer image has been available for the container (0 when up to date)\n")
+	b.WriteString("# TYPE dockwarden_container_stale_seconds gauge\n")
+	for _, key := range keys {
+		cs := series[key]
+		fmt.Fprintf(&b, "dockwarden_container_stale_seconds{%s} %.0f\n", cs.labels(), cs.staleSeconds)
+	}
+
+	b.WriteString("\n# HELP dockwarden_container_last_updated_timestamp Unix time of the last successful update of the container\n")
+	b.WriteString("# TYPE dockwarden_container_last_updated_timestamp gauge\n")
+	for _, key := range keys {
+		cs := series[key]
+		if cs.lastUpdated > 0 {
+			fmt.Fprintf(&b, "dockwarden_container_last_updated_timestamp{%s} %d\n", cs.labels(), cs.lastUpdated)
+		}
+	}
+
+	b.WriteString("\n# HELP dockwarden_container_restart_attempts Health-triggered restart attempts of the container\n")
+	b.WriteString("# TYPE dockwarden_container_restart_attempts gauge\n")
+	for _, key := range keys {
+		cs := series[key]
+		fmt.Fprintf(&b, "dockwarden_container_restart_attempts{%s} %d\n", cs.labels(), cs.restartAttempts)
+	}
+
+	b.WriteString("\n# HELP dockwarden_metrics_containers_dropped Containers left out of per-container metrics by the cardinality cap\n")
+	b.WriteString("# TYPE dockwarden_metrics_containers_dropped gauge\n")
+	fmt.Fprintf(&b, "dockwarden_metrics_containers_dropped %d\n", dropped)
+
+	return b.String()
+}
+
+// pullMetrics renders image pull counters and the pull duration histogram
+// per registry
+func (s *Server) pullMetrics() string {
+	if s.updater == nil {
+		return ""
+	}
+	stats := s.updater.PullStats()
+
+	var b strings.Builder
+	b.WriteString("\n# HELP dockwarden_pull_bytes_total Bytes downloaded by image pulls\n")
+	b.WriteString("# TYPE dockwarden_pull_bytes_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(&b, "dockwarden_pull_bytes_total{registry=\"%s\"} %d\n", escapeLabel(st.Registry), st.Bytes)
+	}
+
+	b.WriteString("\n# HELP dockwarden_pull_failures_total Failed image pulls\n")
+	b.WriteString("# TYPE dockwarden_pull_failures_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(&b, "dockwarden_pull_failures_total{registry=\"%s\"} %d\n", escapeLabel(st.Registry), st.Failures)
+	}
+
+	b.WriteString("\n# HELP dockwarden_pull_skipped_total Pulls avoided because the registry still served the local digest\n")
+	b.WriteString("# TYPE dockwarden_pull_skipped_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(&b, "dockwarden_pull_skipped_total{registry=\"%s\"} %d\n", escapeLabel(st.Registry), st.Skipped)
+	}
+
+	b.WriteString("\n# HELP dockwarden_pull_duration_seconds Duration of successful image pulls\n")
+	b.WriteString("# TYPE dockwarden_pull_duration_seconds histogram\n")
+	for _, st := range stats {
+		reg := escapeLabel(st.Registry)
+		var cumulative int64
+		for i, bound := range updater.PullDurationBuckets {
+			cumulative += st.DurationBuckets[i]
+			fmt.Fprintf(&b, "dockwarden_pull_duration_seconds_bucket{registry=\"%s\",le=\"%s\"} %d\n", reg, strconv.FormatFloat(bound, 'f', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "dockwarden_pull_duration_seconds_bucket{registry=\"%s\",le=\"+Inf\"} %d\n", reg, st.Pulls)
+		fmt.Fprintf(&b, "dockwarden_pull_duration_seconds_sum{registry=\"%s\"} %s\n", reg, strconv.FormatFloat(st.DurationSum, 'f', 3, 64))
+		fmt.Fprintf(&b, "dockwarden_pull_duration_seconds_count{registry=\"%s\"} %d\n", reg, st.Pulls)
+	}
+
+	return b.String()
+}
+
+// taskMetrics renders statistics of the updater's worker pools
+func (s *Server) taskMetrics() string {
+	if s.updater == nil {
+		return ""
+	}
+	stats := s.updater.PoolStats()
+
+	return fmt.Sprintf(`
+# HELP dockwarden_tasks_total Container checks and updates run by the worker pool
+# TYPE dockwarden_tasks_total counter
+dockwarden_tasks_total %d
+
+# HELP dockwarden_task_panics_total Tasks that panicked and were recovered
+# TYPE dockwarden_task_panics_total counter
+dockwarden_task_panics_total %d
+
+# HELP dockwarden_task_timeouts_total Tasks that exceeded the per-container timeout
+# TYPE dockwarden_task_timeouts_total counter
+dockwarden_task_timeouts_total %d
+
+# HELP dockwarden_task_queue_wait_seconds_total Time tasks spent waiting for a worker
+# TYPE dockwarden_task_queue_wait_seconds_total counter
+dockwarden_task_queue_wait_seconds_total %s
+
+# HELP dockwarden_task_queue_wait_seconds_max Longest time a task waited for a worker
+# TYPE dockwarden_task_queue_wait_seconds_max gauge
+dockwarden_task_queue_wait_seconds_max %s
+`,
+		stats.Tasks,
+		stats.Panics,
+		stats.Timeouts,
+		strconv.FormatFloat(stats.QueueWait.Seconds(), 'f', 3, 64),
+		strconv.FormatFloat(stats.MaxWait.Seconds(), 'f', 3, 64),
+	)
+}
+
+// storeMetrics reports the size of the update history and the audit log,
+// and how many entries their retention pruned
+func (s *Server) storeMetrics() string {
+	auditEntries, auditPruned := s.audit.Stats()
+	metrics := fmt.Sprintf(`
+# HELP dockwarden_audit_entries API audit log entries held
+# TYPE dockwarden_audit_entries gauge
+dockwarden_audit_entries %d
+
+# HELP dockwarden_audit_pruned_total API audit log entries removed by the retention
+# TYPE dockwarden_audit_pruned_total counter
+dockwarden_audit_pruned_total %d
+`, auditEntries, auditPruned)
+
+	if s.updater == nil {
+		return metrics
+	}
+	stats := s.updater.History().Stats()
+	return metrics + fmt.Sprintf(`
+# HELP dockwarden_history_records Update history records held
+# TYPE dockwarden_history_records gauge
+dockwarden_history_records %d
+
+# HELP dockwarden_history_pruned_total Update history records removed by the retention
+# TYPE dockwarden_history_pruned_total counter
+dockwarden_history_pruned_total %d
+`, stats.Records, stats.Pruned)
+}
+
+// labels renders the Prometheus label set of the series
+func (cs *containerSeries) labels() string {
+	return fmt.Sprintf(`name="%s",image="%s"`, escapeLabel(cs.name), escapeLabel(cs.image))
+}
+
+// labelEscaper escapes label values as required by the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// probeInterval is how often the Docker daemon is pinged in the
+	// background for the health endpoint
+	probeInterval = 5 * time.Second
+
+	// probeStale is how old the last result may get before the daemon is
+	// reported unreachable: a ping that hangs longer than its own timeout
+	// and two intervals means the daemon stopped answering
+	probeStale = 3*probeInterval + 5*time.Second
+)
+
+// dockerProbe pings the Docker daemon in the background and caches the
+// result, so health requests answer immediately instead of waiting on a
+// slow or unresponsive daemon
+type dockerProbe struct {
+	client docker.Client
+
+	once    sync.Once
+	mu      sync.RWMutex
+	err     error
+	checked time.Time
+}
+
+func newDockerProbe(client docker.Client) *dockerProbe {
+	return &dockerProbe{client: client}
+}
+
+// start pings once synchronously, so the first health request has a result,
+// and keeps pinging in the background. Calling it again has no effect.
+func (p *dockerProbe) start() {
+	p.once.Do(func() {
+		p.ping()
+		go func() {
+			ticker := time.NewTicker(probeInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				p.ping()
+			}
+		}()
+	})
+}
+
+func (p *dockerProbe) ping() {
+	err := p.client.Ping()
+
+	p.mu.Lock()
+	changed := (err == nil) != (p.err == nil) || p.checked.IsZero()
+	p.err = err
+	p.checked = time.Now()
+	p.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if err != nil {
+		log.WithError(err).Warn("Docker daemon is not reachable")
+	} else {
+		log.Debug("Docker daemon is reachable")
+	}
+}
+
+// status returns when the daemon was last pinged and the cached result. A
+// result older than probeStale is reported as an error.
+func (p *dockerProbe) status() (time.Time, error) {
+	p.start()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if age := time.Since(p.checked); age > probeStale {
+		return p.checked, fmt.Errorf("docker daemon has not answered a ping for %s", age.Round(time.Second))
+	}
+	return p.checked, p.err
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ValidateTrustedProxies checks that every trusted proxy is an IP address
+// or a CIDR, so a typo doesn't silently fall back to the proxy's address
+func ValidateTrustedProxies(proxies []string) error {
+	for _, proxy := range trimProxies(proxies) {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			return fmt.Errorf("invalid trusted proxy %q, expected an IP address or CIDR", proxy)
+		}
+	}
+	return nil
+}
+
+// trimProxies trims the whitespace around list entries of an environment
+// variable like "10.0.0.0/8, 172.16.0.0/12"
+func trimProxies(proxies []string) []string {
+	trimmed := make([]string, 0, len(proxies))
+	for _, proxy := range proxies {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trimmed = append(trimmed, proxy)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/emon5122/dockwarden/internal/updater"
+	"github.com/gin-gonic/gin"
+)
+
+// handleResetQuarantine resets the update failures of a quarantined
+// container, so the next cycle updates it again
+func (s *Server) handleResetQuarantine(c *gin.Context) {
+	id := c.Param("id")
+
+	ctr, err := s.client.GetContainer(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "container not found"})
+		return
+	}
+	if token := requestToken(c); token.Restricted() && !token.Allows(ctr) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "token is not allowed to manage this container"})
+		return
+	}
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+
+	err = s.updater.ResetQuarantine(ctr.Identity())
+	s.recordAction(c, "quarantine_reset", id, err)
+	if errors.Is(err, updater.ErrNotQuarantined) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "quarantine reset",
+		"id":       id,
+		"identity": ctr.Identity(),
+	})
+}
+
+// handleUIResetQuarantine resets a quarantine from the dashboard
+func (s *Server) handleUIResetQuarantine(c *gin.Context) {
+	id := c.Param("id")
+
+	ctr, err := s.client.GetContainer(context.Background(), id)
+	if err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: container not found</span>`)
+		return
+	}
+	if s.updater == nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: updater not available</span>`)
+		return
+	}
+
+	err = s.updater.ResetQuarantine(ctr.Identity())
+	s.recordAction(c, "quarantine_reset", id, err)
+	if err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: %s</span>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+	c.String(http.StatusOK, `<span class="text-green-500">✓ Quarantine reset</span>`)
+}
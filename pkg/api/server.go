@@ -3,28 +3,43 @@ package api
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
+	"net"
 	"net/http"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/emon5122/dockwarden/internal/audit"
 	"github.com/emon5122/dockwarden/internal/config"
 	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/eol"
 	"github.com/emon5122/dockwarden/internal/health"
+	"github.com/emon5122/dockwarden/internal/i18n"
 	"github.com/emon5122/dockwarden/internal/meta"
+	"github.com/emon5122/dockwarden/internal/notify"
+	"github.com/emon5122/dockwarden/internal/redact"
+	"github.com/emon5122/dockwarden/internal/release"
 	"github.com/emon5122/dockwarden/internal/updater"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
 
-//go:embed templates/dashboard.html
-var dashboardHTML string
+//go:embed openapi.json
+var openAPISpec []byte
 
-//go:embed templates/stats.html
-var statsHTML string
-
-//go:embed templates/containers.html
-var containersHTML string
+// templateFuncs are helpers available to all UI templates
+var templateFuncs = template.FuncMap{
+	"bytes":    humanBytes,
+	"shortID":  shortID,
+	"age":      humanAge,
+	"duration": humanDuration,
+	"ports":    portList,
+}
 
 // Server is the Gin-based web server with HTMX UI
 type Server struct {
@@ -33,6 +48,16 @@ type Server struct {
 	updater *updater.Updater
 	watcher *health.Watcher
 	engine  *gin.Engine
+	tokens  []apiToken
+
+	audit     *audit.Log
+	notifier  *notify.Notifier
+	probe     *dockerProbe
+	simulated simulationCounter
+
+	assets    assetFS
+	templates map[string]*template.Template
+	tr        *i18n.Translator
 }
 
 // NewServer creates a new API server with web UI
@@ -47,11 +72,23 @@ func NewServer(cfg *config.Config, client docker.Client, upd *updater.Updater, w
 	engine := gin.New()
 	engine.Use(gin.Recovery())
 
+	// Without trusted proxies the client IP is the peer address; forwarding
+	// headers could be set by anyone. Validated at startup.
+	if err := engine.SetTrustedProxies(trimProxies(cfg.TrustedProxies)); err != nil {
+		log.WithError(err).Error("Ignoring invalid trusted proxies")
+	}
+
 	// Custom logger that integrates with logrus
 	engine.Use(func(c *gin.Context) {
 		start := time.Now()
 		c.Next()
-		log.Debugf("[GIN] %s %s %d %s", c.Request.Method, c.Request.URL.Path, c.Writer.Status(), time.Since(start))
+		log.WithFields(log.Fields{
+			"method":    c.Request.Method,
+			"path":      c.Request.URL.Path,
+			"status":    c.Writer.Status(),
+			"duration":  time.Since(start).String(),
+			"client_ip": c.ClientIP(),
+		}).Debug("HTTP request")
 	})
 
 	s := &Server{
@@ -60,35 +97,111 @@ func NewServer(cfg *config.Config, client docker.Client, upd *updater.Updater, w
 		updater: upd,
 		watcher: watcher,
 		engine:  engine,
+		audit:   audit.New(cfg.AuditMaxEntries, cfg.AuditRetention),
+		probe:   newDockerProbe(client),
+		assets:  newAssetFS(cfg.UIDir),
+		tr:      i18n.For(cfg.Locale),
 	}
+	s.templates = s.assets.loadTemplates(s.templateFuncs())
+
+	s.notifier = notify.FromConfig(cfg)
+
+	if cfg.APIToken != "" {
+		s.tokens = append(s.tokens, apiToken{Name: "default", Secret: cfg.APIToken})
+	}
+	// Validated at startup; never serve the API without the tokens it was
+	// configured with
+	scoped, err := parseTokens(cfg.APITokens)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid API tokens")
+	}
+	s.tokens = append(s.tokens, scoped...)
 
 	s.setupRoutes()
 	return s
 }
 
-// Start starts the web server
+// Start starts the web server, on the unix socket when one is configured
+// and on the TCP port otherwise
 func (s *Server) Start() error {
+	s.probe.start()
+
+	if s.config.APISocket != "" {
+		return s.startUnix(s.config.APISocket, s.config.APISocketMode)
+	}
+
 	addr := fmt.Sprintf(":%d", s.config.APIPort)
-	log.Infof("Starting web server on http://0.0.0.0%s", addr)
+	log.WithField("addr", "http://0.0.0.0"+addr).Info("Starting web server")
 	return s.engine.Run(addr)
 }
 
+// startUnix serves the API on a unix socket with the given file mode, so
+// local tools can integrate without opening a network port
+func (s *Server) startUnix(path string, mode os.FileMode) error {
+	// A socket left behind by an unclean shutdown would make Listen fail
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("failed to listen on %s: file exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	defer listener.Close()
+
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+
+	log.WithFields(log.Fields{
+		"socket": path,
+		"mode":   fmt.Sprintf("%04o", mode),
+	}).Info("Starting web server")
+	return http.Serve(listener, s.engine)
+}
+
 // setupRoutes configures all routes
 func (s *Server) setupRoutes() {
 	// Health endpoint (no auth)
 	s.engine.GET("/health", s.handleHealth)
 
+	// API description (no auth, so clients and the Swagger UI can discover the API)
+	s.engine.GET("/v1/openapi.json", compress(), s.handleOpenAPI)
+	s.engine.GET("/api-docs", compress(), s.handleSwaggerUI)
+
 	// API v1 routes
 	v1 := s.engine.Group("/v1")
-	if s.config.APIToken != "" {
+	if len(s.tokens) > 0 {
 		v1.Use(s.authMiddleware())
 	}
 	{
 		v1.GET("/health", s.handleHealth)
 		v1.GET("/info", s.handleInfo)
 		v1.GET("/containers", s.handleContainers)
-		v1.POST("/update", s.handleTriggerUpdate)
-		v1.POST("/containers/:id/restart", s.handleRestartContainer)
+		v1.GET("/containers/:id", s.handleContainer)
+		v1.GET("/system", s.handleSystem)
+		v1.GET("/plan", s.handlePlan)
+		v1.POST("/plan/apply", s.leaderOnly(), s.handlePlanApply)
+		v1.GET("/audit", s.handleAudit)
+		v1.GET("/history", s.handleHistory)
+		v1.GET("/history/:id/sbom", s.handleHistorySBOM)
+		v1.GET("/stats/images", s.handleImageStats)
+		v1.POST("/update", s.leaderOnly(), s.handleTriggerUpdate)
+		v1.POST("/containers/:id/restart", s.leaderOnly(), s.handleRestartContainer)
+		v1.POST("/containers/:id/exec", s.leaderOnly(), s.handleExecContainer)
+		v1.POST("/containers/:id/quarantine/reset", s.leaderOnly(), s.handleResetQuarantine)
+		v1.POST("/containers/:id/labels", s.leaderOnly(), s.handleEditLabels)
+		v1.GET("/images", s.handleImages)
+		v1.POST("/images/prune", s.leaderOnly(), s.handlePruneImages)
+		v1.POST("/images/load", s.leaderOnly(), s.handleLoadImages)
+		v1.GET("/networks", s.handleNetworks)
+		v1.GET("/volumes", s.handleVolumes)
+		v1.POST("/simulate", s.handleSimulate)
 	}
 
 	// Metrics endpoint
@@ -97,28 +210,71 @@ func (s *Server) setupRoutes() {
 	}
 
 	// Web UI routes
-	s.engine.GET("/", s.handleDashboard)
-	s.engine.GET("/ui/containers", s.handleUIContainers)
-	s.engine.GET("/ui/stats", s.handleUIStats)
-	s.engine.POST("/ui/update", s.handleUITriggerUpdate)
-	s.engine.POST("/ui/containers/:id/restart", s.handleUIRestartContainer)
+	files := s.assets.static()
+	static := s.engine.Group("/static", compress(), cacheFiles(files))
+	static.StaticFS("/", files)
+
+	ui := s.engine.Group("", compress(), cacheControl(cacheDynamic))
+	ui.GET("/", s.handleDashboard)
+	ui.GET("/ui/containers", s.handleUIContainers)
+	ui.GET("/ui/export/containers", s.handleUIExportContainers)
+	ui.GET("/ui/export/history", s.handleUIExportHistory)
+	ui.GET("/ui/stats", s.handleUIStats)
+	ui.GET("/ui/pending", s.handleUIPending)
+	ui.GET("/ui/system", s.handleUISystem)
+	ui.GET("/ui/plan", s.handleUIPlan)
+	ui.POST("/ui/plan/apply", s.leaderOnly(), s.handleUIPlanApply)
+	ui.GET("/ui/audit", s.handleUIAudit)
+	ui.GET("/ui/images", s.handleUIImages)
+	ui.GET("/ui/stats/images", s.handleUIImageStats)
+	ui.POST("/ui/images/prune", s.uiAuth(), s.leaderOnly(), s.handleUIPruneImages)
+	ui.POST("/ui/update", s.leaderOnly(), s.handleUITriggerUpdate)
+	ui.POST("/ui/containers/:id/restart", s.leaderOnly(), s.handleUIRestartContainer)
+	ui.POST("/ui/containers/:id/quarantine/reset", s.leaderOnly(), s.handleUIResetQuarantine)
+	ui.GET("/ui/containers/:id/labels", s.handleUILabelEditor)
+	ui.POST("/ui/containers/:id/labels", s.uiAuth(), s.leaderOnly(), s.handleUIEditLabels)
+}
+
+// leaderOnly rejects mutations on a standby instance, which serves the API
+// read-only while another instance leads
+func (s *Server) leaderOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.updater != nil && !s.updater.IsLeader() {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": updater.ErrStandby.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// uiAuth requires an API token for dashboard actions that are as powerful as
+// their API counterparts, while tokens are configured. The dashboard asks
+// for the token when such an action is rejected.
+func (s *Server) uiAuth() gin.HandlerFunc {
+	if len(s.tokens) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return s.authMiddleware()
 }
 
-// authMiddleware checks for valid API token
+// authMiddleware checks for valid API token and remembers which one was used
 func (s *Server) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token := c.GetHeader("Authorization")
-		if token != "Bearer "+s.config.APIToken {
+		secret, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		token := lookupToken(s.tokens, secret)
+		if !ok || token == nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
+		c.Set(tokenContextKey, token)
 		c.Next()
 	}
 }
 
-// handleHealth handles health check requests
+// handleHealth reports the cached result of the background Docker ping, so
+// probes never wait on an unresponsive daemon
 func (s *Server) handleHealth(c *gin.Context) {
-	err := s.client.Ping()
+	checked, err := s.probe.status()
 
 	status := "ok"
 	dockerStatus := "connected"
@@ -130,16 +286,24 @@ func (s *Server) handleHealth(c *gin.Context) {
 		httpStatus = http.StatusServiceUnavailable
 	}
 
-	c.JSON(httpStatus, gin.H{
-		"status": status,
-		"docker": dockerStatus,
-		"time":   time.Now().UTC().Format(time.RFC3339),
-	})
+	resp := gin.H{
+		"status":            status,
+		"docker":            dockerStatus,
+		"time":              time.Now().UTC().Format(time.RFC3339),
+		"docker_checked_at": checked.UTC().Format(time.RFC3339),
+	}
+	if s.config.LeaderElection && s.updater != nil {
+		resp["role"] = "standby"
+		if s.updater.IsLeader() {
+			resp["role"] = "leader"
+		}
+	}
+	c.JSON(httpStatus, resp)
 }
 
 // handleInfo handles info requests
 func (s *Server) handleInfo(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	info := gin.H{
 		"name":     "DockWarden",
 		"version":  meta.Version,
 		"commit":   meta.Commit,
@@ -147,11 +311,26 @@ func (s *Server) handleInfo(c *gin.Context) {
 		"mode":     s.config.Mode,
 		"interval": s.config.Interval.String(),
 		"cleanup":  s.config.Cleanup,
-	})
+	}
+	// Webhook URLs carry their token, only the host is shown
+	if s.config.NotificationURL != "" {
+		info["notifications"] = redact.URL(s.config.NotificationURL)
+	}
+	if s.updater != nil {
+		if latest := s.updater.LatestRelease(); latest != nil {
+			info["release"] = latest
+		}
+	}
+	c.JSON(http.StatusOK, info)
 }
 
 // handleContainers returns all managed containers
 func (s *Server) handleContainers(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	ctx := context.Background()
 	containers, err := s.client.ListContainers(ctx, docker.ListOptions{
 		All:           s.config.IncludeStopped,
@@ -162,12 +341,172 @@ func (s *Server) handleContainers(c *gin.Context) {
 		return
 	}
 
+	if token := requestToken(c); token.Restricted() {
+		containers = slices.DeleteFunc(containers, func(ctr docker.Container) bool { return !token.Allows(ctr) })
+	}
+
+	views := s.containerViews(ctx, containers)
+	if format == formatCSV {
+		writeCSV(c, "containers", containerColumns, containerRows(views))
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"containers": containers,
+		"containers": views,
 		"count":      len(containers),
 	})
 }
 
+// containerDetailView is a container view with its inspect details
+type containerDetailView struct {
+	containerView
+	docker.ContainerDetails
+}
+
+// handleContainer returns a single container with its inspect details
+func (s *Server) handleContainer(c *gin.Context) {
+	ctx := context.Background()
+	ctr, details, err := s.client.InspectContainer(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "container not found"})
+		return
+	}
+	if token := requestToken(c); token.Restricted() && !token.Allows(ctr) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "token is not allowed to manage this container"})
+		return
+	}
+
+	c.JSON(http.StatusOK, containerDetailView{
+		containerView:    s.containerViews(ctx, []docker.Container{ctr})[0],
+		ContainerDetails: details,
+	})
+}
+
+// containerView is a container annotated with DockWarden's view of it
+type containerView struct {
+	docker.Container
+	// SkipReason explains why the updater ignores the container (nil if managed)
+	SkipReason *updater.SkipReason
+	// UpdateAvailableSince is when a newer image was first seen upstream
+	// (nil if the container is up to date or hasn't been checked)
+	UpdateAvailableSince *time.Time
+	// EOL is set when the container's tag belongs to an end-of-life release
+	EOL *eol.Status
+	// Owner and Notes say who to contact about the container, from its
+	// dockwarden.owner and dockwarden.notes labels
+	Owner string `json:",omitempty"`
+	Notes string `json:",omitempty"`
+}
+
+// containerViews annotates containers with their skip reasons
+func (s *Server) containerViews(ctx context.Context, containers []docker.Container) []containerView {
+	views := make([]containerView, 0, len(containers))
+	for _, ctr := range containers {
+		view := containerView{
+			Container: ctr,
+			Owner:     ctr.GetLabel(docker.OwnerLabel),
+			Notes:     ctr.GetLabel(docker.NotesLabel),
+		}
+		if s.updater != nil {
+			view.SkipReason = s.updater.SkipReason(ctx, ctr)
+			if status, ok := s.updater.ContainerStatus(ctr.Identity()); ok {
+				if status.UpdateAvailable {
+					since := status.UpdateAvailableSince
+					view.UpdateAvailableSince = &since
+				}
+				if status.EOL != nil && status.EOL.Expired {
+					view.EOL = status.EOL
+				}
+			}
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// handleSystem returns Docker daemon information and disk usage
+func (s *Server) handleSystem(c *gin.Context) {
+	ctx := context.Background()
+	info, err := s.client.Info(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage, err := s.client.DiskUsage(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"info":       info,
+		"disk_usage": usage,
+		"disk_total": usage.Total(),
+	})
+}
+
+// handlePlan returns what the next update cycle would do, without pulling
+func (s *Server) handlePlan(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+
+	plan, err := s.updater.Plan(c.Request.Context(), requestToken(c).Filter())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// planApplyRequest selects planned items to apply, by container name or ID
+type planApplyRequest struct {
+	Containers []string `json:"containers"`
+}
+
+// handlePlanApply immediately applies the selected planned updates
+func (s *Server) handlePlanApply(c *gin.Context) {
+	if s.updater == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "updater not available"})
+		return
+	}
+
+	var req planApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Containers) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no containers selected"})
+		return
+	}
+
+	results, err := s.updater.Apply(c.Request.Context(), req.Containers, requestToken(c).Filter())
+	s.recordAction(c, "plan_apply", strings.Join(req.Containers, ", "), applyError(results, err))
+	if errors.Is(err, updater.ErrCycleLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	applied := 0
+	for _, r := range results {
+		if r.Applied {
+			applied++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"applied": applied,
+	})
+}
+
 // handleTriggerUpdate triggers an update check
 func (s *Server) handleTriggerUpdate(c *gin.Context) {
 	if s.updater == nil {
@@ -175,12 +514,14 @@ func (s *Server) handleTriggerUpdate(c *gin.Context) {
 		return
 	}
 
+	match := requestToken(c).Filter()
 	go func() {
-		if err := s.updater.Run(); err != nil {
-			log.Errorf("Manual update failed: %v", err)
+		if err := s.updater.RunMatching(match); err != nil {
+			log.WithError(err).Error("Manual update failed")
 		}
 	}()
 
+	s.recordAction(c, "update", "", nil)
 	c.JSON(http.StatusAccepted, gin.H{"message": "update triggered"})
 }
 
@@ -189,20 +530,103 @@ func (s *Server) handleRestartContainer(c *gin.Context) {
 	id := c.Param("id")
 	ctx := context.Background()
 
-	if err := s.client.RestartContainer(ctx, id, s.config.StopTimeout); err != nil {
+	ctr, err := s.client.GetContainer(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "container not found"})
+		return
+	}
+	if token := requestToken(c); token.Restricted() && !token.Allows(ctr) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "token is not allowed to manage this container"})
+		return
+	}
+
+	timeout, err := s.restartContainer(ctx, ctr)
+	s.recordAction(c, "restart", id, err)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := gin.H{
+		"message":      "container restarted",
+		"id":           id,
+		"stop_timeout": int(timeout.Seconds()),
+	}
+	if signal := ctr.GetStopSignal(); signal != "" {
+		resp["stop_signal"] = signal
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// restartContainer restarts ctr honouring its dockwarden.stop-timeout and
+// dockwarden.stop-signal labels, like the updater does when stopping it.
+// It returns the stop timeout that was used.
+func (s *Server) restartContainer(ctx context.Context, ctr docker.Container) (time.Duration, error) {
+	timeout := ctr.GetStopTimeout(s.config.StopTimeout)
+	return timeout, s.client.RestartContainer(ctx, ctr.ID, timeout, ctr.GetStopSignal())
+}
+
+// execRequest is the command to run in a container
+type execRequest struct {
+	Command []string `json:"command"`
+	// Timeout is a duration like "30s"; defaults to defaultExecTimeout
+	Timeout string `json:"timeout"`
+}
+
+// defaultExecTimeout bounds commands run through the exec endpoint
+const defaultExecTimeout = 60 * time.Second
+
+// handleExecContainer runs a command in a container and returns its output.
+// It requires an unrestricted token, so it is unavailable without API
+// authentication.
+func (s *Server) handleExecContainer(c *gin.Context) {
+	id := c.Param("id")
+
+	if token := requestToken(c); token == nil || token.Restricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "exec requires an unrestricted API token"})
+		return
+	}
+
+	var req execRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Command) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no command given"})
+		return
+	}
+	timeout := defaultExecTimeout
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timeout"})
+			return
+		}
+		timeout = d
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	result, err := s.client.Exec(ctx, id, req.Command)
+	s.recordAction(c, "exec", id+": "+strings.Join(req.Command, " "), err)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "container restarted", "id": id})
+	c.JSON(http.StatusOK, result)
 }
 
 // handleMetrics returns Prometheus metrics
 func (s *Server) handleMetrics(c *gin.Context) {
 	var updaterStats, watcherStats map[string]interface{}
+	pending := 0
 
 	if s.updater != nil {
 		updaterStats = s.updater.GetStats()
+		pending = s.updater.PendingUpdates()
 	}
 	if s.watcher != nil {
 		watcherStats = s.watcher.GetStats()
@@ -242,27 +666,67 @@ dockwarden_updates_total %d
 # HELP dockwarden_update_failures_total Total number of failed updates
 # TYPE dockwarden_update_failures_total counter
 dockwarden_update_failures_total %d
+
+# HELP dockwarden_updates_pending Containers with an update that was found but not applied
+# TYPE dockwarden_updates_pending gauge
+dockwarden_updates_pending %d
 `,
 		len(containers),
 		running,
 		unhealthy,
 		getInt64(updaterStats, "total_updated"),
 		getInt64(updaterStats, "total_failed"),
+		pending,
 	)
 
 	_ = watcherStats // Available for future metrics
 
+	metrics += s.containerMetrics()
+	metrics += s.pullMetrics()
+	metrics += s.taskMetrics()
+	metrics += s.storeMetrics()
+	metrics += s.simulationMetrics()
+
 	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(metrics))
 }
 
+// handleOpenAPI serves the OpenAPI 3 description of the /v1 API
+func (s *Server) handleOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+}
+
+// handleSwaggerUI serves Swagger UI for the OpenAPI description
+func (s *Server) handleSwaggerUI(c *gin.Context) {
+	page, err := fs.ReadFile(s.assets, "templates/swagger.html")
+	if err != nil {
+		c.String(http.StatusInternalServerError, "failed to load Swagger UI: %v", err)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
 // handleDashboard serves the main web UI dashboard
 func (s *Server) handleDashboard(c *gin.Context) {
-	tmpl := template.Must(template.New("dashboard").Parse(dashboardHTML))
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.Status(http.StatusOK)
-	tmpl.Execute(c.Writer, gin.H{
-		"Version": meta.Version,
-		"TZ":      s.config.TZ,
+	// The page script reloads panels in whole seconds
+	refresh := int(s.config.UIRefresh.Round(time.Second) / time.Second)
+	refreshLabel := s.tr.T("ui.refresh_off")
+	if s.config.UIRefresh > 0 {
+		refresh = max(refresh, 1)
+		refreshLabel = (time.Duration(refresh) * time.Second).String()
+	}
+
+	var latest *release.Status
+	if s.updater != nil {
+		latest = s.updater.LatestRelease()
+	}
+
+	s.render(c, "dashboard", gin.H{
+		"Brand":        s.brand(),
+		"Version":      meta.Version,
+		"Release":      latest,
+		"TZ":           s.config.TZ,
+		"Refresh":      max(refresh, 0),
+		"RefreshLabel": refreshLabel,
 	})
 }
 
@@ -278,10 +742,7 @@ func (s *Server) handleUIContainers(c *gin.Context) {
 		return
 	}
 
-	tmpl := template.Must(template.New("containers").Parse(containersHTML))
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.Status(http.StatusOK)
-	tmpl.Execute(c.Writer, containers)
+	s.render(c, "containers", s.containerViews(ctx, containers))
 }
 
 // handleUIStats returns HTMX fragment for stats
@@ -305,10 +766,7 @@ func (s *Server) handleUIStats(c *gin.Context) {
 		updaterStats = s.updater.GetStats()
 	}
 
-	tmpl := template.Must(template.New("stats").Parse(statsHTML))
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.Status(http.StatusOK)
-	tmpl.Execute(c.Writer, gin.H{
+	s.render(c, "stats", gin.H{
 		"Total":     len(containers),
 		"Running":   running,
 		"Unhealthy": unhealthy,
@@ -316,6 +774,89 @@ func (s *Server) handleUIStats(c *gin.Context) {
 	})
 }
 
+// handleUISystem returns HTMX fragment for the system panel
+func (s *Server) handleUISystem(c *gin.Context) {
+	ctx := context.Background()
+	info, err := s.client.Info(ctx)
+	if err != nil {
+		c.String(http.StatusInternalServerError, `<div class="text-red-500 p-6">Error loading system info: %s</div>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+
+	// Disk usage can be slow on large hosts; show the panel even if it fails
+	usage, usageErr := s.client.DiskUsage(ctx)
+
+	s.render(c, "system", gin.H{
+		"Info":       info,
+		"Usage":      usage,
+		"UsageError": usageErr,
+		"Total":      usage.Total(),
+	})
+}
+
+// handleUIPending returns HTMX fragment for the pending updates badge in
+// the header, empty when there are none
+func (s *Server) handleUIPending(c *gin.Context) {
+	if s.updater == nil {
+		c.String(http.StatusOK, "")
+		return
+	}
+	pending := s.updater.PendingUpdates()
+	if pending == 0 {
+		c.String(http.StatusOK, "")
+		return
+	}
+	label := template.HTMLEscapeString(s.tr.T("ui.pending_updates", pending))
+	c.String(http.StatusOK, `<span class="relative inline-flex" title="%s" role="img" aria-label="%s"><span class="text-xl" aria-hidden="true">🔔</span><span class="absolute -top-1 -right-2 rounded-full bg-yellow-500 px-1.5 text-xs font-bold text-gray-900" aria-hidden="true">%d</span></span>`, label, label, pending)
+}
+
+// handleUIPlan returns HTMX fragment for the update plan
+func (s *Server) handleUIPlan(c *gin.Context) {
+	if s.updater == nil {
+		c.String(http.StatusOK, `<div class="text-red-500 p-6">Updater not available</div>`)
+		return
+	}
+
+	plan, err := s.updater.Plan(c.Request.Context(), nil)
+	if err != nil {
+		c.String(http.StatusInternalServerError, `<div class="text-red-500 p-6">Error generating plan: %s</div>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+
+	s.render(c, "plan", plan)
+}
+
+// handleUIPlanApply applies the planned updates selected in the plan view
+func (s *Server) handleUIPlanApply(c *gin.Context) {
+	if s.updater == nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Updater not available</span>`)
+		return
+	}
+
+	selected := c.PostFormArray("container")
+	if len(selected) == 0 {
+		c.String(http.StatusOK, `<span class="text-yellow-400">Nothing selected</span>`)
+		return
+	}
+
+	results, err := s.updater.Apply(c.Request.Context(), selected, nil)
+	s.recordAction(c, "plan_apply", strings.Join(selected, ", "), applyError(results, err))
+	if err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: %s</span>`, template.HTMLEscapeString(err.Error()))
+		return
+	}
+
+	var b strings.Builder
+	for _, r := range results {
+		if r.Applied {
+			fmt.Fprintf(&b, `<div class="text-green-500">✓ %s updated</div>`, template.HTMLEscapeString(r.Container))
+		} else {
+			fmt.Fprintf(&b, `<div class="text-red-500">✗ %s: %s</div>`, template.HTMLEscapeString(r.Container), template.HTMLEscapeString(r.Error))
+		}
+	}
+	c.String(http.StatusOK, b.String())
+}
+
 // handleUITriggerUpdate triggers update via HTMX
 func (s *Server) handleUITriggerUpdate(c *gin.Context) {
 	if s.updater == nil {
@@ -325,10 +866,11 @@ func (s *Server) handleUITriggerUpdate(c *gin.Context) {
 
 	go func() {
 		if err := s.updater.Run(); err != nil {
-			log.Errorf("Manual update failed: %v", err)
+			log.WithError(err).Error("Manual update failed")
 		}
 	}()
 
+	s.recordAction(c, "update", "", nil)
 	c.String(http.StatusOK, `<span class="text-green-500">✓ Update triggered</span>`)
 }
 
@@ -337,12 +879,72 @@ func (s *Server) handleUIRestartContainer(c *gin.Context) {
 	id := c.Param("id")
 	ctx := context.Background()
 
-	if err := s.client.RestartContainer(ctx, id, s.config.StopTimeout); err != nil {
+	ctr, err := s.client.GetContainer(ctx, id)
+	if err != nil {
+		c.String(http.StatusOK, `<span class="text-red-500">Failed: container not found</span>`)
+		return
+	}
+
+	timeout, err := s.restartContainer(ctx, ctr)
+	s.recordAction(c, "restart", id, err)
+	if err != nil {
 		c.String(http.StatusOK, `<span class="text-red-500">Failed: %s</span>`, err.Error())
 		return
 	}
 
-	c.String(http.StatusOK, `<span class="text-green-500">✓ Restarted</span>`)
+	c.String(http.StatusOK, `<span class="text-green-500">✓ Restarted (stop timeout %s)</span>`, timeout)
+}
+
+// portList renders ports like docker ps, dropping the duplicate entries
+// Docker reports for ports published on both IPv4 and IPv6
+func portList(ports []docker.Port) []string {
+	var list []string
+	for _, p := range ports {
+		if s := p.String(); !slices.Contains(list, s) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// humanBytes formats a byte count using binary units (KiB, MiB, ...)
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// humanAge formats the time elapsed since t, e.g. "3d", "5h" or "12m"
+func humanAge(t time.Time) string {
+	return humanDuration(time.Since(t))
+}
+
+// humanDuration formats a duration in its largest whole unit, e.g. 3d
+func humanDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+// shortID shortens an ID or digest for display
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
 }
 
 func getInt64(m map[string]interface{}, key string) int64 {
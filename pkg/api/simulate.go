@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/emon5122/dockwarden/internal/events"
+	"github.com/emon5122/dockwarden/internal/notify"
+	"github.com/gin-gonic/gin"
+)
+
+// Defaults of simulated events
+const (
+	simulatedContainer = "dockwarden-simulated"
+	simulatedImage     = "dockwarden/simulated:latest"
+)
+
+// simulateRequest selects the event to simulate
+type simulateRequest struct {
+	Event     string `json:"event"`
+	Container string `json:"container"`
+	Image     string `json:"image"`
+}
+
+// simulationCounter counts simulated events by type for the metrics
+type simulationCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (sc *simulationCounter) inc(eventType string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.counts == nil {
+		sc.counts = make(map[string]int64)
+	}
+	sc.counts[eventType]++
+}
+
+// handleSimulate emits a synthetic event through the notifier, the event
+// stream, the metrics and the audit log, so alert routing can be verified
+// without breaking a container. Nothing happens to any container. It
+// requires an unrestricted token since it notifies everyone subscribed.
+func (s *Server) handleSimulate(c *gin.Context) {
+	if token := requestToken(c); token == nil || token.Restricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "simulating events requires an unrestricted API token"})
+		return
+	}
+
+	var req simulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	eventType := notify.EventType(req.Event)
+	if !slices.Contains(notify.SimulatedEvents, eventType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported event %q, use %s", req.Event, simulatedEventList())})
+		return
+	}
+	if req.Container == "" {
+		req.Container = simulatedContainer
+	}
+	if req.Image == "" {
+		req.Image = simulatedImage
+	}
+
+	var err error
+	if s.notifier != nil {
+		err = s.notifier.Simulate(eventType, req.Container, req.Image)
+	}
+	if s.updater != nil {
+		s.updater.Events().Publish(events.Event{
+			Type:      req.Event,
+			Container: req.Container,
+			Image:     req.Image,
+			Message:   notify.SimulatedPrefix + req.Event + " of " + req.Container,
+		})
+	}
+	s.simulated.inc(req.Event)
+	s.recordAction(c, "simulate", req.Event, err)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "simulated " + req.Event,
+		"event":     req.Event,
+		"container": req.Container,
+		"image":     req.Image,
+		"notified":  s.notifier != nil,
+	})
+}
+
+// simulatedEventList names the events that can be simulated
+func simulatedEventList() string {
+	names := make([]string, len(notify.SimulatedEvents))
+	for i, t := range notify.SimulatedEvents {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+// simulationMetrics renders the counter of simulated events
+func (s *Server) simulationMetrics() string {
+	s.simulated.mu.Lock()
+	defer s.simulated.mu.Unlock()
+
+	types := make([]string, 0, len(s.simulated.counts))
+	for t := range s.simulated.counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	var b strings.Builder
+	b.WriteString("\n# HELP dockwarden_simulated_events_total Synthetic events emitted through POST /v1/simulate\n")
+	b.WriteString("# TYPE dockwarden_simulated_events_total counter\n")
+	for _, t := range types {
+		fmt.Fprintf(&b, "dockwarden_simulated_events_total{event=\"%s\"} %d\n", escapeLabel(t), s.simulated.counts[t])
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+// Auto refresh of the dashboard panels, keyboard shortcuts and the API token
+// of guarded actions. The interval defaults to DOCKWARDEN_UI_REFRESH and can
+// be changed per browser; the choice is kept in localStorage.
+(function () {
+    var key = "dockwarden.refresh";
+    var select = document.getElementById("refresh");
+    var timer = null;
+
+    function stored() {
+        try {
+            return localStorage.getItem(key);
+        } catch (e) {
+            return null;
+        }
+    }
+
+    function seconds() {
+        var value = stored();
+        if (value === null) {
+            value = document.body.dataset.refresh;
+        }
+        return parseInt(value, 10) || 0;
+    }
+
+    function refresh() {
+        document.querySelectorAll("[data-refresh-panel]").forEach(function (panel) {
+            htmx.trigger(panel, "refresh");
+        });
+    }
+
+    function schedule() {
+        clearInterval(timer);
+        var interval = seconds();
+        if (interval > 0) {
+            timer = setInterval(refresh, interval * 1000);
+        }
+    }
+
+    if (select) {
+        select.value = stored() === null ? "" : stored();
+        select.addEventListener("change", function () {
+            try {
+                if (select.value === "") {
+                    localStorage.removeItem(key);
+                } else {
+                    localStorage.setItem(key, select.value);
+                }
+            } catch (e) {
+                // Private browsing: the choice lasts until the page is left
+                document.body.dataset.refresh = select.value || document.body.dataset.refresh;
+            }
+            schedule();
+        });
+    }
+    schedule();
+
+    // r refreshes the panels, u checks for updates; keys typed into form
+    // fields and combinations with modifiers are left alone
+    document.addEventListener("keydown", function (e) {
+        if (e.ctrlKey || e.metaKey || e.altKey || e.defaultPrevented) {
+            return;
+        }
+        var target = e.target;
+        if (target.isContentEditable || /^(INPUT|SELECT|TEXTAREA)$/.test(target.tagName)) {
+            return;
+        }
+        if (e.key === "r") {
+            e.preventDefault();
+            refresh();
+        } else if (e.key === "u") {
+            var button = document.getElementById("check-updates");
+            if (button) {
+                e.preventDefault();
+                button.click();
+            }
+        }
+    });
+
+    // Actions guarded like their API counterparts are rejected with 401
+    // while API tokens are configured: the token is asked for, kept for the
+    // browser session and the action sent again
+    var tokenKey = "dockwarden.token";
+
+    function token() {
+        try {
+            return sessionStorage.getItem(tokenKey);
+        } catch (e) {
+            return null;
+        }
+    }
+
+    document.body.addEventListener("htmx:configRequest", function (e) {
+        var value = token();
+        if (value) {
+            e.detail.headers["Authorization"] = "Bearer " + value;
+        }
+    });
+
+    document.body.addEventListener("htmx:responseError", function (e) {
+        if (e.detail.xhr.status !== 401) {
+            return;
+        }
+        var value = window.prompt(document.body.dataset.tokenPrompt || "API token");
+        if (!value) {
+            return;
+        }
+        try {
+            sessionStorage.setItem(tokenKey, value);
+        } catch (err) {
+            return;
+        }
+        var request = e.detail.requestConfig;
+        htmx.ajax(request.verb.toUpperCase(), request.path, {source: e.detail.elt, target: e.detail.target});
+    });
+})();
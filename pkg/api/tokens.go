@@ -0,0 +1,135 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/emon5122/dockwarden/internal/docker"
+	"github.com/emon5122/dockwarden/internal/updater"
+	"github.com/gin-gonic/gin"
+)
+
+// apiToken is a named API token, optionally restricted to the containers of
+// a scope or carrying a label
+type apiToken struct {
+	Name   string
+	Secret string
+
+	// Scope restricts the token to containers with this dockwarden.scope
+	Scope string
+	// LabelKey and LabelValue restrict the token to containers with the label
+	LabelKey   string
+	LabelValue string
+}
+
+// Restricted reports whether the token is limited to some containers
+func (t *apiToken) Restricted() bool {
+	return t != nil && (t.Scope != "" || t.LabelKey != "")
+}
+
+// Allows reports whether the token may act on ctr
+func (t *apiToken) Allows(ctr docker.Container) bool {
+	if t == nil {
+		return true
+	}
+	if t.Scope != "" && ctr.GetScope() != t.Scope {
+		return false
+	}
+	if t.LabelKey != "" && ctr.GetLabel(t.LabelKey) != t.LabelValue {
+		return false
+	}
+	return true
+}
+
+// Filter returns the token's restriction as a container filter, nil when the
+// token is unrestricted
+func (t *apiToken) Filter() updater.Filter {
+	if !t.Restricted() {
+		return nil
+	}
+	return t.Allows
+}
+
+// parseTokens parses token entries of the form name:secret[:selector], where
+// the optional selector is scope=<scope> or label=<key>=<value>. Entries may
+// also be separated by commas or newlines within one value.
+func parseTokens(entries []string) ([]apiToken, error) {
+	var tokens []apiToken
+	index := 0
+	for _, entry := range entries {
+		for _, raw := range strings.FieldsFunc(entry, func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r'
+		}) {
+			raw = strings.TrimSpace(raw)
+			if raw == "" || strings.HasPrefix(raw, "#") {
+				continue
+			}
+			index++
+			token, err := parseToken(raw, index)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+// ValidateTokens checks the API token entries, so a typo stops DockWarden
+// instead of leaving the API without authentication
+func ValidateTokens(entries []string) error {
+	_, err := parseTokens(entries)
+	return err
+}
+
+// parseToken parses the index-th token entry. Errors refer to the entry by
+// its index, since a malformed entry may be nothing but the secret.
+func parseToken(raw string, index int) (apiToken, error) {
+	parts := strings.SplitN(raw, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return apiToken{}, fmt.Errorf("invalid API token entry #%d: expected name:token[:selector]", index)
+	}
+
+	token := apiToken{Name: parts[0], Secret: parts[1]}
+	if len(parts) == 3 {
+		kind, value, _ := strings.Cut(parts[2], "=")
+		switch kind {
+		case "scope":
+			token.Scope = value
+		case "label":
+			token.LabelKey, token.LabelValue, _ = strings.Cut(value, "=")
+		default:
+			return apiToken{}, fmt.Errorf("invalid selector for API token %q: expected scope=<scope> or label=<key>=<value>", token.Name)
+		}
+		if !token.Restricted() {
+			return apiToken{}, fmt.Errorf("empty selector for API token %q", token.Name)
+		}
+	}
+	return token, nil
+}
+
+// tokenContextKey is the gin context key of the authenticated token
+const tokenContextKey = "api_token"
+
+// requestToken returns the token that authenticated the request, or nil when
+// authentication is disabled
+func requestToken(c *gin.Context) *apiToken {
+	if v, ok := c.Get(tokenContextKey); ok {
+		if token, ok := v.(*apiToken); ok {
+			return token
+		}
+	}
+	return nil
+}
+
+// lookupToken returns the token matching secret, comparing in constant time
+func lookupToken(tokens []apiToken, secret string) *apiToken {
+	var found *apiToken
+	for i := range tokens {
+		if subtle.ConstantTimeCompare([]byte(tokens[i].Secret), []byte(secret)) == 1 {
+			found = &tokens[i]
+		}
+	}
+	return found
+}